@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/minaevmike/godis/client"
+)
+
+// runBackup implements backup subcommand: godis backup -addr host:port (-out file | -path name)
+func runBackup(args []string) int {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:4321", "address of server")
+	out := flags.String("out", "", "local file where backup is streamed")
+	path := flags.String("path", "", "file name in backup directory of server where server writes backup")
+	flags.Parse(args)
+
+	if (*out == "") == (*path == "") {
+		fmt.Fprintln(os.Stderr, "exactly one of -out and -path must be set")
+		return 2
+	}
+	c, err := client.Dial(*addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "can't connect: %v\n", err)
+		return 1
+	}
+	defer c.Close()
+
+	var info client.BackupInfo
+	if *out != "" {
+		info, err = c.BackupToFile(*out)
+		info.Path = *out
+	} else {
+		info, err = c.BackupOnServer(*path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backup failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("backup %s: %d keys, %d bytes, wal position %d, sha256 %s\n",
+		info.Path, info.Keys, info.Size, info.WALPosition, info.SHA256)
+	return 0
+}
@@ -1,6 +1,7 @@
 package bench
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -178,7 +179,7 @@ func TestMain(m *testing.M) {
 
 	code := m.Run()
 
-	s.Shutdown()
+	s.Shutdown(context.Background())
 	cl.Close()
 
 	os.Exit(code)
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/snapshot"
+)
+
+type BackupInfo struct {
+	// WALPosition - sequence number of the first wal segment which isn't included into backup
+	WALPosition uint64
+	Keys        int64
+	// Size of backup in bytes
+	Size   int64
+	SHA256 string
+	// Path - file written by server, empty for streamed backup
+	Path string
+	// LSN of the last wal record included into backup
+	LSN uint64
+}
+
+// Backup streams consistent backup of all databases from server to w and checks its checksum.
+// Backup is restored with server.Restore
+func (c *Client) Backup(w io.Writer) (BackupInfo, error) {
+	conn, err := c.connectionPool.Get()
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	defer conn.Close()
+
+	if err := c.wireProtocol.Write(conn, &godis_proto.Request{Operation: godis_proto.Operation_Backup}); err != nil {
+		markUnusable(conn)
+		return BackupInfo{}, err
+	}
+	h := sha256.New()
+	out := io.MultiWriter(w, h)
+	for {
+		resp := &godis_proto.Response{}
+		if err := c.wireProtocol.Read(conn, resp); err != nil {
+			markUnusable(conn)
+			return BackupInfo{}, err
+		}
+		if resp.GetError() != nil {
+			return BackupInfo{}, newError(resp.GetError())
+		}
+		if chunk, ok := resp.GetResponseValue().(*godis_proto.Response_Chunk); ok {
+			if _, err := out.Write(chunk.Chunk); err != nil {
+				// rest of backup isn't read, so connection can't be reused
+				markUnusable(conn)
+				return BackupInfo{}, err
+			}
+			continue
+		}
+		info := newBackupInfo(resp.GetBackup())
+		if sum := hex.EncodeToString(h.Sum(nil)); sum != info.SHA256 {
+			return info, fmt.Errorf("backup sha256 %s doesn't match %s", sum, info.SHA256)
+		}
+		return info, nil
+	}
+}
+
+// BackupToFile streams backup to file and writes its checksum to file.sha256
+func (c *Client) BackupToFile(file string) (BackupInfo, error) {
+	tmp := file + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	info, err := c.Backup(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, file)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return info, err
+	}
+	return info, snapshot.WriteChecksumFile(file, info.SHA256)
+}
+
+// BackupOnServer makes server write backup to file with given name in its backup directory
+func (c *Client) BackupOnServer(name string) (BackupInfo, error) {
+	resp, err := c.do(&godis_proto.Request{Operation: godis_proto.Operation_Backup, Path: name})
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	return newBackupInfo(resp.GetBackup()), nil
+}
+
+func newBackupInfo(info *godis_proto.BackupInfo) BackupInfo {
+	return BackupInfo{
+		WALPosition: info.GetWalPosition(),
+		Keys:        info.GetKeys(),
+		Size:        info.GetSize(),
+		SHA256:      info.GetSha256(),
+		Path:        info.GetPath(),
+		LSN:         info.GetLsn(),
+	}
+}
@@ -3,8 +3,8 @@ package client
 import (
 	"net"
 
-	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/minaevmike/godis/codec"
@@ -22,18 +22,55 @@ func Dial(addr string) (*Client, error) {
 		return nil, err
 	}
 
-	return &Client{connectionPool: p, wireProtocol: wire.NewSimpleWireProtocol(codec.NewProtoCodec())}, nil
+	return &Client{connectionPool: p, wireProtocol: wire.NewSimpleWireProtocol(codec.NewProtoCodec()), dial: factory}, nil
 }
 
 type Client struct {
 	connectionPool pool.Pool
 	wireProtocol   wire.Protocol
+	// dial creates connections which are not returned to pool, e.g. for subscriptions
+	dial func() (net.Conn, error)
+	// db is set for requests which don't select database themselves, nil means database 0
+	db *godis_proto.Database
+	// durable is set for all requests of client
+	durable bool
 }
 
 func (c *Client) Close() {
 	c.connectionPool.Close()
 }
 
+// DB returns client which sends requests to database with given index, it shares connections with c,
+// so only one of them should be closed
+func (c *Client) DB(index int) *Client {
+	db := *c
+	db.db = &godis_proto.Database{Index: uint32(index)}
+	return &db
+}
+
+// Durable returns client which waits until its writes are synced to disk before request returns,
+// it shares connections with c, so only one of them should be closed
+func (c *Client) Durable() *Client {
+	d := *c
+	d.durable = true
+	return &d
+}
+
+// FlushDB deletes all keys of client database
+func (c *Client) FlushDB() error {
+	_, err := c.do(&godis_proto.Request{Operation: godis_proto.Operation_FlushDB})
+	return err
+}
+
+// DBSize returns amount of keys in client database
+func (c *Client) DBSize() (int, error) {
+	resp, err := c.do(&godis_proto.Request{Operation: godis_proto.Operation_DBSize})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
 func (c *Client) get(key string) (*godis_proto.Response, error) {
 	conn, err := c.connectionPool.Get()
 	if err != nil {
@@ -112,7 +149,24 @@ func (c *Client) Remove(key string) error {
 	return nil
 }
 
+// do makes request using connection from pool
+func (c *Client) do(req *godis_proto.Request) (*godis_proto.Response, error) {
+	conn, err := c.connectionPool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return c.writeRequestReadResponse(conn, req)
+}
+
 func (c *Client) writeRequestReadResponse(conn net.Conn, req *godis_proto.Request) (*godis_proto.Response, error) {
+	if req.Database == nil {
+		req.Database = c.db
+	}
+	if c.durable {
+		req.Durable = true
+	}
 	err := c.wireProtocol.Write(conn, req)
 	if err != nil {
 		return nil, err
@@ -124,7 +178,7 @@ func (c *Client) writeRequestReadResponse(conn net.Conn, req *godis_proto.Reques
 	}
 
 	if resp.GetError() != nil {
-		return nil, errors.New(resp.GetError().GetMessage())
+		return nil, newError(resp.GetError())
 	}
 
 	return resp, nil
@@ -245,3 +299,110 @@ func (c *Client) GetByMapKey(key string, mapKey string) (string, error) {
 		return "", fmt.Errorf("key has another type %T", t)
 	}
 }
+
+// Expire sets ttl of existing key, false is returned if key doesn't exist
+func (c *Client) Expire(key string, ttl time.Duration) (bool, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_Expire,
+		Value:     &godis_proto.Value{Ttl: ttl.Nanoseconds() + time.Now().UnixNano()},
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.GetCount() > 0, nil
+}
+
+// HSet sets fields of map and returns amount of added fields. If key doesn't exist it's created with given ttl
+func (c *Client) HSet(key string, ttl time.Duration, fields map[string]string) (int, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_HSet,
+		Value: &godis_proto.Value{
+			Value: &godis_proto.Value_StringMap{StringMap: &godis_proto.MapString{StringMap: fields}},
+			Ttl:   ttl.Nanoseconds() + time.Now().UnixNano(),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
+// HDel deletes fields of map and returns amount of deleted fields, map without fields is deleted
+func (c *Client) HDel(key string, fields ...string) (int, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_HDel,
+		Value: &godis_proto.Value{
+			Value: &godis_proto.Value_StringSlice{StringSlice: &godis_proto.RepeatedString{StringArrayVal: fields}},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
+// Incr adds n to integer stored as string and returns result. If key doesn't exist it's created
+// with value n and given ttl
+func (c *Client) Incr(key string, n int64, ttl time.Duration) (int64, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_Incr,
+		Value: &godis_proto.Value{
+			Value: &godis_proto.Value_StringVal{StringVal: strconv.FormatInt(n, 10)},
+			Ttl:   ttl.Nanoseconds() + time.Now().UnixNano(),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.GetCount(), nil
+}
+
+// RangeOptions describes range of keys, start is inclusive, end is exclusive.
+// Empty start or end means that range is unbounded from this side
+type RangeOptions struct {
+	Start     string
+	End       string
+	Limit     int
+	Reverse   bool
+	PageToken string
+}
+
+type KeyValue struct {
+	Key   string
+	Value *godis_proto.Value
+}
+
+// Range returns keys with values from given range in lexicographical order and token of the next page,
+// token is empty when there are no more keys in range. Server must be started with ordered storage
+func (c *Client) Range(opts RangeOptions) ([]KeyValue, string, error) {
+	conn, err := c.connectionPool.Get()
+	if err != nil {
+		return nil, "", err
+	}
+	defer conn.Close()
+
+	req := &godis_proto.Request{
+		Key:       opts.Start,
+		Operation: godis_proto.Operation_Range,
+		EndKey:    opts.End,
+		Limit:     uint32(opts.Limit),
+		Reverse:   opts.Reverse,
+		PageToken: opts.PageToken,
+	}
+
+	resp, err := c.writeRequestReadResponse(conn, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := resp.GetKeyValues().GetItems()
+	result := make([]KeyValue, 0, len(items))
+	for _, item := range items {
+		result = append(result, KeyValue{Key: item.GetKey(), Value: item.GetValue()})
+	}
+	return result, resp.GetKeyValues().GetNextPageToken(), nil
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"errors"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+// Error is returned when server rejects request
+type Error struct {
+	Code    godis_proto.ErrorCode
+	Message string
+}
+
+func newError(e *godis_proto.Error) error {
+	return &Error{Code: e.GetCode(), Message: e.GetMessage()}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// IsQuotaExceeded returns true if request was rejected because quota of database or client is exceeded
+func IsQuotaExceeded(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == godis_proto.ErrorCode_QuotaExceeded
+}
+
+// IsLimitExceeded returns true if request was rejected because it exceeds server limits, e.g. key is too long
+func IsLimitExceeded(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == godis_proto.ErrorCode_LimitExceeded
+}
+
+// IsReadOnly returns true if write request was sent to replica
+func IsReadOnly(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == godis_proto.ErrorCode_ReadOnly
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"gopkg.in/fatih/pool.v2"
+)
+
+var ErrTimeout = errors.New("timeout")
+
+// LPush inserts elements to the head of slice and returns its new length.
+// Elements are inserted one after another, so the last element would be the first in slice.
+// If key doesn't exist it's created with given ttl
+func (c *Client) LPush(key string, ttl time.Duration, elements ...string) (int, error) {
+	return c.push(godis_proto.Operation_LPush, key, ttl, elements)
+}
+
+// RPush appends elements to the tail of slice and returns its new length.
+// If key doesn't exist it's created with given ttl
+func (c *Client) RPush(key string, ttl time.Duration, elements ...string) (int, error) {
+	return c.push(godis_proto.Operation_RPush, key, ttl, elements)
+}
+
+func (c *Client) push(op godis_proto.Operation, key string, ttl time.Duration, elements []string) (int, error) {
+	conn, err := c.connectionPool.Get()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	req := &godis_proto.Request{
+		Key:       key,
+		Operation: op,
+		Value: &godis_proto.Value{
+			Value: &godis_proto.Value_StringSlice{StringSlice: &godis_proto.RepeatedString{StringArrayVal: elements}},
+			Ttl:   ttl.Nanoseconds() + time.Now().UnixNano(),
+		},
+	}
+
+	resp, err := c.writeRequestReadResponse(conn, req)
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
+// LPop removes and returns the first element of slice
+func (c *Client) LPop(key string) (string, error) {
+	return c.pop(godis_proto.Operation_LPop, key)
+}
+
+// RPop removes and returns the last element of slice
+func (c *Client) RPop(key string) (string, error) {
+	return c.pop(godis_proto.Operation_RPop, key)
+}
+
+func (c *Client) pop(op godis_proto.Operation, key string) (string, error) {
+	conn, err := c.connectionPool.Get()
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	req := &godis_proto.Request{
+		Key:       key,
+		Operation: op,
+	}
+
+	resp, err := c.writeRequestReadResponse(conn, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.GetValue().GetStringVal(), nil
+}
+
+// BLPop removes and returns the first element of the first non empty slice of keys.
+// If all slices are empty it waits until element is pushed, timeout is reached or ctx is done.
+// Zero timeout means wait until ctx is done. ErrTimeout is returned on timeout
+func (c *Client) BLPop(ctx context.Context, timeout time.Duration, keys ...string) (key string, element string, err error) {
+	return c.blockingPop(ctx, godis_proto.Operation_BLPop, timeout, keys)
+}
+
+// BRPop is same as BLPop but it removes the last element of slice
+func (c *Client) BRPop(ctx context.Context, timeout time.Duration, keys ...string) (key string, element string, err error) {
+	return c.blockingPop(ctx, godis_proto.Operation_BRPop, timeout, keys)
+}
+
+func (c *Client) blockingPop(ctx context.Context, op godis_proto.Operation, timeout time.Duration, keys []string) (string, string, error) {
+	req := &godis_proto.Request{
+		Operation: op,
+		Keys:      keys,
+		Timeout:   timeout.Nanoseconds(),
+	}
+
+	resp, err := c.writeRequestReadResponseContext(ctx, req)
+	if err != nil {
+		return "", "", err
+	}
+
+	items := resp.GetKeyValues().GetItems()
+	if len(items) == 0 {
+		return "", "", ErrTimeout
+	}
+	return items[0].GetKey(), items[0].GetValue().GetStringVal(), nil
+}
+
+// writeRequestReadResponseContext makes request which can be cancelled by ctx,
+// connection of cancelled request is closed, so server stops processing it
+func (c *Client) writeRequestReadResponseContext(ctx context.Context, req *godis_proto.Request) (*godis_proto.Response, error) {
+	conn, err := c.connectionPool.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	cancelled := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			// unblock read
+			conn.SetDeadline(time.Now())
+			close(cancelled)
+		case <-done:
+		}
+	}()
+
+	resp, err := c.writeRequestReadResponse(conn, req)
+	close(done)
+	select {
+	case <-cancelled:
+		markUnusable(conn)
+		return nil, ctx.Err()
+	default:
+	}
+	if err != nil {
+		if _, ok := err.(net.Error); ok {
+			markUnusable(conn)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+func markUnusable(conn net.Conn) {
+	if pc, ok := conn.(*pool.PoolConn); ok {
+		pc.MarkUnusable()
+	}
+}
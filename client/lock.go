@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+var (
+	ErrLockHeld    = errors.New("lock is held by another owner")
+	ErrLockNotHeld = errors.New("lock isn't held by owner")
+	ErrLeaseTTL    = errors.New("lease ttl is too short to be renewed")
+)
+
+type Lock struct {
+	Key   string
+	Owner string
+	// Token is increased on every acquire of lock, pass it to protected resources to reject stale owners
+	Token     uint64
+	ExpiresAt time.Time
+}
+
+// AcquireLock takes lock for owner for ttl. If lock is held by another owner ErrLockHeld is returned
+// with current state of lock. Acquire of lock held by the same owner extends lease and keeps token
+func (c *Client) AcquireLock(key, owner string, ttl time.Duration) (Lock, error) {
+	return c.lock(godis_proto.Operation_LockAcquire, key, owner, ttl, ErrLockHeld)
+}
+
+// RenewLock extends lease of lock held by owner, ErrLockNotHeld is returned if owner lost the lock
+func (c *Client) RenewLock(key, owner string, ttl time.Duration) (Lock, error) {
+	return c.lock(godis_proto.Operation_LockRenew, key, owner, ttl, ErrLockNotHeld)
+}
+
+func (c *Client) lock(op godis_proto.Operation, key, owner string, ttl time.Duration, errNotOwner error) (Lock, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: op,
+		Owner:     owner,
+		Lease:     ttl.Nanoseconds(),
+	})
+	if err != nil {
+		return Lock{}, err
+	}
+	l := resp.GetLock()
+	lock := Lock{Key: key, Owner: l.GetOwner(), Token: l.GetToken()}
+	if l.GetOwner() != "" {
+		lock.ExpiresAt = time.Unix(0, l.GetExpiresAt())
+	}
+	if l.GetOwner() != owner {
+		return lock, errNotOwner
+	}
+	return lock, nil
+}
+
+// ReleaseLock frees lock held by owner, ErrLockNotHeld is returned if owner doesn't hold the lock
+func (c *Client) ReleaseLock(key, owner string) error {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_LockRelease,
+		Owner:     owner,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.GetCount() == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Lease is lock which is renewed in background until it's released
+type Lease struct {
+	Lock
+
+	client *Client
+	ttl    time.Duration
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// AcquireLease takes lock like AcquireLock and keeps it alive by renewing it every ttl/3.
+// Context of lease is done when lock is lost (renew was rejected or wasn't possible until lease expired),
+// lease is released or ctx is done. Lock is released when ctx is done. ErrLeaseTTL is returned if ttl/3 isn't positive
+func (c *Client) AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (*Lease, error) {
+	if ttl/3 <= 0 {
+		return nil, ErrLeaseTTL
+	}
+	deadline := time.Now().Add(ttl)
+	lock, err := c.AcquireLock(key, owner, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Lease{
+		Lock:   lock,
+		client: c,
+		ttl:    ttl,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	l.ctx, l.cancel = context.WithCancel(ctx)
+	go l.keepAlive(deadline)
+	return l, nil
+}
+
+// Context returns context which is done when lock is lost or released
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+func (l *Lease) keepAlive(deadline time.Time) {
+	defer close(l.done)
+	defer l.cancel()
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.ctx.Done():
+			// parent context is done
+			l.client.ReleaseLock(l.Key, l.Owner)
+			return
+		case <-ticker.C:
+		}
+
+		start := time.Now()
+		_, err := l.client.RenewLock(l.Key, l.Owner, l.ttl)
+		switch {
+		case err == nil:
+			deadline = start.Add(l.ttl)
+		case err == ErrLockNotHeld:
+			return
+		case time.Now().After(deadline):
+			// lease expired while server wasn't reachable
+			return
+		}
+	}
+}
+
+// Release stops renewal and frees the lock, ErrLockNotHeld is returned if lock was already lost
+func (l *Lease) Release() error {
+	l.once.Do(func() {
+		close(l.stop)
+	})
+	<-l.done
+	return l.client.ReleaseLock(l.Key, l.Owner)
+}
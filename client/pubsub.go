@@ -0,0 +1,370 @@
+package client
+
+import (
+	"errors"
+	"net"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+const (
+	subscriptionBufferSize = 1024
+	reconnectMinBackoff    = 10 * time.Millisecond
+	reconnectMaxBackoff    = 5 * time.Second
+)
+
+var ErrSubscriptionClosed = errors.New("subscription closed")
+
+type Message struct {
+	Channel string
+	// Pattern is set when message is received by pattern subscription
+	Pattern string
+	Payload []byte
+	// Key, Event and Database are set for keyspace notifications
+	Key      string
+	Event    godis_proto.EventType
+	Database int
+}
+
+// Publish sends payload to all subscribers of channel and returns amount of subscribers received it
+func (c *Client) Publish(channel string, payload []byte) (int, error) {
+	conn, err := c.connectionPool.Get()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	req := &godis_proto.Request{
+		Key:       channel,
+		Operation: godis_proto.Operation_Publish,
+		Payload:   payload,
+	}
+
+	resp, err := c.writeRequestReadResponse(conn, req)
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
+// Subscribe creates subscription to given channels. Subscription uses its own connection
+// and restores all subscriptions after reconnect
+func (c *Client) Subscribe(channels ...string) (*Subscription, error) {
+	return c.newSubscription(channels, nil)
+}
+
+// PSubscribe creates subscription to channels matching given patterns, patterns use go regexp syntax
+func (c *Client) PSubscribe(patterns ...string) (*Subscription, error) {
+	return c.newSubscription(nil, patterns)
+}
+
+// SubscribeKeyspace creates subscription to notifications about changes of keys matching given regexp,
+// empty events means all event types
+func (c *Client) SubscribeKeyspace(pattern string, events ...godis_proto.EventType) (*Subscription, error) {
+	err := validatePatterns([]string{pattern})
+	if err != nil {
+		return nil, err
+	}
+	s := c.initSubscription()
+	s.keyspace[pattern] = events
+	return s, s.start()
+}
+
+func (c *Client) newSubscription(channels, patterns []string) (*Subscription, error) {
+	err := validatePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	s := c.initSubscription()
+	for _, channel := range channels {
+		s.channels[channel] = struct{}{}
+	}
+	for _, pattern := range patterns {
+		s.patterns[pattern] = struct{}{}
+	}
+	return s, s.start()
+}
+
+func (c *Client) initSubscription() *Subscription {
+	return &Subscription{
+		client:   c,
+		channels: make(map[string]struct{}),
+		patterns: make(map[string]struct{}),
+		keyspace: make(map[string][]godis_proto.EventType),
+		messages: make(chan *Message, subscriptionBufferSize),
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *Subscription) start() error {
+	conn, err := s.connect()
+	if err != nil {
+		return err
+	}
+
+	go s.run(conn)
+	return nil
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		_, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscription delivers messages published to subscribed channels
+type Subscription struct {
+	client *Client
+
+	mu       sync.Mutex
+	conn     net.Conn
+	channels map[string]struct{}
+	patterns map[string]struct{}
+	keyspace map[string][]godis_proto.EventType
+
+	messages  chan *Message
+	closed    chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Channel returns channel with received messages, it's closed after subscription is closed
+func (s *Subscription) Channel() <-chan *Message {
+	return s.messages
+}
+
+// Subscribe adds channels to subscription
+func (s *Subscription) Subscribe(channels ...string) error {
+	return s.change(godis_proto.Operation_Subscribe, s.channels, channels, true)
+}
+
+// PSubscribe adds patterns to subscription
+func (s *Subscription) PSubscribe(patterns ...string) error {
+	err := validatePatterns(patterns)
+	if err != nil {
+		return err
+	}
+	return s.change(godis_proto.Operation_PSubscribe, s.patterns, patterns, true)
+}
+
+// Unsubscribe removes channels from subscription
+func (s *Subscription) Unsubscribe(channels ...string) error {
+	return s.change(godis_proto.Operation_Unsubscribe, s.channels, channels, false)
+}
+
+// PUnsubscribe removes patterns from subscription
+func (s *Subscription) PUnsubscribe(patterns ...string) error {
+	return s.change(godis_proto.Operation_PUnsubscribe, s.patterns, patterns, false)
+}
+
+// SubscribeKeyspace adds subscription to notifications about keys matching to pattern,
+// events of existing subscription to the same pattern are replaced
+func (s *Subscription) SubscribeKeyspace(pattern string, events ...godis_proto.EventType) error {
+	err := validatePatterns([]string{pattern})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.closed:
+		return ErrSubscriptionClosed
+	default:
+	}
+
+	s.keyspace[pattern] = events
+	if s.conn == nil {
+		return nil
+	}
+	return s.client.wireProtocol.Write(s.conn, s.client.keyspaceRequest(pattern, events))
+}
+
+// UnsubscribeKeyspace removes key patterns from subscription
+func (s *Subscription) UnsubscribeKeyspace(patterns ...string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.closed:
+		return ErrSubscriptionClosed
+	default:
+	}
+
+	for _, pattern := range patterns {
+		delete(s.keyspace, pattern)
+	}
+	if s.conn == nil {
+		return nil
+	}
+	return s.client.wireProtocol.Write(s.conn, &godis_proto.Request{
+		Operation: godis_proto.Operation_UnsubscribeKeyspace,
+		Channels:  patterns,
+		Database:  s.client.db,
+	})
+}
+
+// keyspaceRequest subscribes to keys of client database
+func (c *Client) keyspaceRequest(pattern string, events []godis_proto.EventType) *godis_proto.Request {
+	return &godis_proto.Request{Operation: godis_proto.Operation_SubscribeKeyspace, Key: pattern, Events: events, Database: c.db}
+}
+
+func (s *Subscription) change(op godis_proto.Operation, set map[string]struct{}, items []string, add bool) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.closed:
+		return ErrSubscriptionClosed
+	default:
+	}
+
+	for _, item := range items {
+		if add {
+			set[item] = struct{}{}
+		} else {
+			delete(set, item)
+		}
+	}
+	if s.conn == nil {
+		// subscription is reconnecting, all subscriptions would be restored after reconnect
+		return nil
+	}
+	// ack is read by run loop
+	return s.client.wireProtocol.Write(s.conn, &godis_proto.Request{Operation: op, Channels: items})
+}
+
+// Close closes subscription connection and stops reconnects
+func (s *Subscription) Close() error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		close(s.closed)
+		if s.conn != nil {
+			s.conn.Close()
+		}
+		s.mu.Unlock()
+	})
+	<-s.done
+	return nil
+}
+
+// connect dials new connection and restores subscriptions on it
+func (s *Subscription) connect() (net.Conn, error) {
+	conn, err := s.client.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.closed:
+		conn.Close()
+		return nil, ErrSubscriptionClosed
+	default:
+	}
+
+	requests := []*godis_proto.Request{
+		{Operation: godis_proto.Operation_Subscribe, Channels: keys(s.channels)},
+		{Operation: godis_proto.Operation_PSubscribe, Channels: keys(s.patterns)},
+	}
+	for pattern, events := range s.keyspace {
+		requests = append(requests, s.client.keyspaceRequest(pattern, events))
+	}
+	for _, req := range requests {
+		if len(req.Channels) == 0 && req.Operation != godis_proto.Operation_SubscribeKeyspace {
+			continue
+		}
+		err = s.client.wireProtocol.Write(conn, req)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	s.conn = conn
+	return conn, nil
+}
+
+func (s *Subscription) run(conn net.Conn) {
+	defer close(s.done)
+	defer close(s.messages)
+
+	backoff := reconnectMinBackoff
+	for {
+		if conn != nil {
+			backoff = reconnectMinBackoff
+			s.read(conn)
+			s.mu.Lock()
+			s.conn = nil
+			s.mu.Unlock()
+			conn.Close()
+		}
+
+		select {
+		case <-s.closed:
+			return
+		case <-time.After(backoff):
+		}
+
+		var err error
+		conn, err = s.connect()
+		if err != nil {
+			conn = nil
+			backoff *= 2
+			if backoff > reconnectMaxBackoff {
+				backoff = reconnectMaxBackoff
+			}
+		}
+	}
+}
+
+// read delivers messages from connection until it's broken or subscription is closed
+func (s *Subscription) read(conn net.Conn) {
+	for {
+		resp := &godis_proto.Response{}
+		err := s.client.wireProtocol.Read(conn, resp)
+		if err != nil {
+			return
+		}
+		msg := resp.GetMessage()
+		if msg == nil {
+			// (un)subscribe ack
+			continue
+		}
+		select {
+		case s.messages <- &Message{
+			Channel:  msg.GetChannel(),
+			Pattern:  msg.GetPattern(),
+			Payload:  msg.GetPayload(),
+			Key:      msg.GetKey(),
+			Event:    msg.GetEvent(),
+			Database: int(msg.GetDatabase()),
+		}:
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+func keys(set map[string]struct{}) []string {
+	out := make([]string, 0, len(set))
+	for key := range set {
+		out = append(out, key)
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+type Job struct {
+	ID      string
+	Payload []byte
+	// Attempts - amount of deliveries including current one
+	Attempts   int
+	EnqueuedAt time.Time
+}
+
+type QueueStats struct {
+	// Ready jobs can be dequeued right now
+	Ready int
+	// Delayed jobs are waiting for their delay
+	Delayed  int
+	InFlight int
+	Dead     int
+}
+
+// QEnqueue adds job to queue and returns its id, job becomes visible to workers after delay.
+// Job is moved to dead letters after maxAttempts failed deliveries, zero maxAttempts means unlimited
+func (c *Client) QEnqueue(queue string, payload []byte, delay time.Duration, maxAttempts int) (string, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:         queue,
+		Operation:   godis_proto.Operation_QEnqueue,
+		Payload:     payload,
+		Delay:       delay.Nanoseconds(),
+		MaxAttempts: uint32(maxAttempts),
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetValue().GetStringVal(), nil
+}
+
+// QDequeue returns up to count ready jobs, they are hidden from other workers for visibilityTimeout.
+// Job which isn't acknowledged with QAck during this time is delivered again.
+// Zero visibilityTimeout means server default (30 seconds)
+func (c *Client) QDequeue(queue string, count int, visibilityTimeout time.Duration) ([]Job, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:               queue,
+		Operation:         godis_proto.Operation_QDequeue,
+		Limit:             uint32(count),
+		VisibilityTimeout: visibilityTimeout.Nanoseconds(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs(resp.GetJobs().GetJobs()), nil
+}
+
+// QAck removes processed jobs from queue and returns amount of acknowledged jobs
+func (c *Client) QAck(queue string, ids ...string) (int, error) {
+	return c.queueFinish(godis_proto.Operation_QAck, queue, 0, ids)
+}
+
+// QNack returns failed jobs back to queue, they are delivered again after delay.
+// Jobs without attempts left are moved to dead letters. Dead jobs are requeued with reset attempts
+func (c *Client) QNack(queue string, delay time.Duration, ids ...string) (int, error) {
+	return c.queueFinish(godis_proto.Operation_QNack, queue, delay, ids)
+}
+
+func (c *Client) queueFinish(op godis_proto.Operation, queue string, delay time.Duration, ids []string) (int, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       queue,
+		Operation: op,
+		Ids:       ids,
+		Delay:     delay.Nanoseconds(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
+func (c *Client) QStats(queue string) (QueueStats, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       queue,
+		Operation: godis_proto.Operation_QStats,
+	})
+	if err != nil {
+		return QueueStats{}, err
+	}
+	stats := resp.GetQueueStats()
+	return QueueStats{
+		Ready:    int(stats.GetReady()),
+		Delayed:  int(stats.GetDelayed()),
+		InFlight: int(stats.GetInFlight()),
+		Dead:     int(stats.GetDead()),
+	}, nil
+}
+
+// QDead returns up to limit dead jobs, zero limit means all of them
+func (c *Client) QDead(queue string, limit int) ([]Job, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       queue,
+		Operation: godis_proto.Operation_QDead,
+		Limit:     uint32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs(resp.GetJobs().GetJobs()), nil
+}
+
+type WorkerOptions struct {
+	// VisibilityTimeout - time job is hidden from other workers for, it should be greater than processing time
+	VisibilityTimeout time.Duration
+	// Batch - amount of jobs dequeued at once, default is 1
+	Batch int
+	// PollInterval - delay between dequeue requests when queue is empty or request failed, default is 1 second
+	PollInterval time.Duration
+	// RetryDelay - delay of failed job delivery
+	RetryDelay time.Duration
+	// OnError is called on failed requests to server, worker continues after PollInterval
+	OnError func(err error)
+}
+
+// RunWorker processes jobs of queue until ctx is done and returns ctx error.
+// Job is acknowledged if handler returns nil, otherwise it's returned to queue with RetryDelay
+func (c *Client) RunWorker(ctx context.Context, queue string, opts WorkerOptions, handler func(ctx context.Context, job Job) error) error {
+	if opts.Batch <= 0 {
+		opts.Batch = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	onError := func(err error) {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		jobs, err := c.QDequeue(queue, opts.Batch, opts.VisibilityTimeout)
+		if err != nil {
+			onError(err)
+		}
+		for _, job := range jobs {
+			if ctx.Err() != nil {
+				// job would be delivered again after visibility timeout
+				return ctx.Err()
+			}
+			if handler(ctx, job) == nil {
+				_, err = c.QAck(queue, job.ID)
+			} else {
+				_, err = c.QNack(queue, opts.RetryDelay, job.ID)
+			}
+			if err != nil {
+				onError(err)
+			}
+		}
+		if len(jobs) > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+func jobs(list []*godis_proto.Job) []Job {
+	result := make([]Job, 0, len(list))
+	for _, job := range list {
+		result = append(result, Job{
+			ID:         job.GetId(),
+			Payload:    job.GetPayload(),
+			Attempts:   int(job.GetAttempts()),
+			EnqueuedAt: time.Unix(0, job.GetEnqueuedAt()),
+		})
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+type RateLimitResult struct {
+	Allowed bool
+	// Remaining - cost which can be spent right now
+	Remaining int
+	// RetryAfter is set for denied request, request with same cost would be allowed after it
+	RetryAfter time.Duration
+}
+
+// TokenBucket checks request with given cost against token bucket stored by key:
+// bucket holds up to limit tokens which are refilled evenly during window
+func (c *Client) TokenBucket(key string, limit int, window time.Duration, cost int) (RateLimitResult, error) {
+	return c.rateLimit(godis_proto.RateLimitAlgorithm_TokenBucket, key, limit, window, cost)
+}
+
+// SlidingWindowLog checks request with given cost against log of requests stored by key:
+// sum of costs of requests allowed during the last window can't exceed limit
+func (c *Client) SlidingWindowLog(key string, limit int, window time.Duration, cost int) (RateLimitResult, error) {
+	return c.rateLimit(godis_proto.RateLimitAlgorithm_SlidingWindowLog, key, limit, window, cost)
+}
+
+func (c *Client) rateLimit(algorithm godis_proto.RateLimitAlgorithm, key string, limit int, window time.Duration, cost int) (RateLimitResult, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_RateLimit,
+		Algorithm: algorithm,
+		Limit:     uint32(limit),
+		Window:    window.Nanoseconds(),
+		Cost:      uint32(cost),
+	})
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	result := resp.GetRateLimit()
+	return RateLimitResult{
+		Allowed:    result.GetAllowed(),
+		Remaining:  int(result.GetRemaining()),
+		RetryAfter: time.Duration(result.GetRetryAfter()),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+// Eval runs lua script atomically on server and returns its result: nil, string, []string or map[string]string.
+// keys and args are available in script as KEYS and ARGV tables. Zero timeout means server default execution time limit.
+// Script can use godis.get(key), godis.set(key, value [, ttl_ms]), godis.del(key) and godis.exists(key),
+// its writes are applied only if it succeeds
+func (c *Client) Eval(script string, timeout time.Duration, keys []string, args ...string) (interface{}, error) {
+	return c.eval(&godis_proto.Request{
+		Operation: godis_proto.Operation_Eval,
+		Script:    script,
+		Timeout:   timeout.Nanoseconds(),
+		Keys:      keys,
+		Args:      args,
+	})
+}
+
+// EvalSHA runs script cached on server by ScriptLoad or Eval, see Eval
+func (c *Client) EvalSHA(sha string, timeout time.Duration, keys []string, args ...string) (interface{}, error) {
+	return c.eval(&godis_proto.Request{
+		Operation: godis_proto.Operation_EvalSHA,
+		Sha:       sha,
+		Timeout:   timeout.Nanoseconds(),
+		Keys:      keys,
+		Args:      args,
+	})
+}
+
+func (c *Client) eval(req *godis_proto.Request) (interface{}, error) {
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch v := resp.GetValue().GetValue().(type) {
+	case *godis_proto.Value_StringVal:
+		return v.StringVal, nil
+	case *godis_proto.Value_StringSlice:
+		return v.StringSlice.GetStringArrayVal(), nil
+	case *godis_proto.Value_StringMap:
+		return v.StringMap.GetStringMap(), nil
+	}
+	return nil, nil
+}
+
+// ScriptLoad compiles and caches script on server, it returns sha1 of script to use in EvalSHA
+func (c *Client) ScriptLoad(script string) (string, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Operation: godis_proto.Operation_ScriptLoad,
+		Script:    script,
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetValue().GetStringVal(), nil
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+type DatabaseStats struct {
+	Index int
+	Keys  int64
+	// Bytes - total size of keys and encoded values
+	Bytes int64
+	// MaxKeys and MaxBytes are quota limits, zero means no limit
+	MaxKeys  int64
+	MaxBytes int64
+	// Rejected - amount of requests rejected by database quota
+	Rejected int64
+}
+
+type ClientStats struct {
+	Address     string
+	Connections int64
+	// Rejected - amount of requests rejected by client quota
+	Rejected int64
+}
+
+type ConnectionStats struct {
+	Active   int64
+	Accepted int64
+	// Rejected and RejectedPerHost - connections rejected because of max connections limits
+	Rejected        int64
+	RejectedPerHost int64
+	// IdleClosed - connections closed by idle timeout
+	IdleClosed    int64
+	ReadTimeouts  int64
+	WriteTimeouts int64
+}
+
+type SnapshotInfo struct {
+	// WALPosition - sequence number of the first wal segment which isn't included into snapshot
+	WALPosition uint64
+	Keys        int64
+	// Size of snapshot file in bytes
+	Size    int64
+	Created time.Time
+	// LSN of the last wal record included into snapshot
+	LSN uint64
+}
+
+type ReplicationStats struct {
+	// Primary - address of primary, empty if server isn't replica
+	Primary string
+	// Connected is set while replica receives wal stream from primary
+	Connected bool
+	// LSN of the last record applied by replica or written by primary
+	LSN uint64
+	// PrimaryLSN - LSN of the last record written by primary known to replica
+	PrimaryLSN uint64
+	// Lag - time between write of the last applied record and its apply, zero if replica has all records
+	Lag       time.Duration
+	FullSyncs int64
+	// Replicas - amount of replicas connected to primary
+	Replicas int64
+}
+
+type Stats struct {
+	Databases []DatabaseStats
+	// Clients contains only clients limited by quota
+	Clients     []ClientStats
+	Connections ConnectionStats
+	// LastSnapshot is nil if snapshot wasn't written or loaded since server start
+	LastSnapshot *SnapshotInfo
+	Replication  ReplicationStats
+}
+
+// Stats returns usage of server databases and clients
+func (c *Client) Stats() (Stats, error) {
+	resp, err := c.do(&godis_proto.Request{Operation: godis_proto.Operation_Stats})
+	if err != nil {
+		return Stats{}, err
+	}
+	var stats Stats
+	for _, db := range resp.GetStats().GetDatabases() {
+		stats.Databases = append(stats.Databases, DatabaseStats{
+			Index:    int(db.GetIndex()),
+			Keys:     db.GetKeys(),
+			Bytes:    db.GetBytes(),
+			MaxKeys:  db.GetMaxKeys(),
+			MaxBytes: db.GetMaxBytes(),
+			Rejected: db.GetRejected(),
+		})
+	}
+	for _, cl := range resp.GetStats().GetClients() {
+		stats.Clients = append(stats.Clients, ClientStats{
+			Address:     cl.GetAddress(),
+			Connections: cl.GetConnections(),
+			Rejected:    cl.GetRejected(),
+		})
+	}
+	conns := resp.GetStats().GetConnections()
+	stats.Connections = ConnectionStats{
+		Active:          conns.GetActive(),
+		Accepted:        conns.GetAccepted(),
+		Rejected:        conns.GetRejected(),
+		RejectedPerHost: conns.GetRejectedPerHost(),
+		IdleClosed:      conns.GetIdleClosed(),
+		ReadTimeouts:    conns.GetReadTimeouts(),
+		WriteTimeouts:   conns.GetWriteTimeouts(),
+	}
+	repl := resp.GetStats().GetReplication()
+	stats.Replication = ReplicationStats{
+		Primary:    repl.GetPrimary(),
+		Connected:  repl.GetConnected(),
+		LSN:        repl.GetLsn(),
+		PrimaryLSN: repl.GetPrimaryLsn(),
+		Lag:        time.Duration(repl.GetLag()),
+		FullSyncs:  repl.GetFullSyncs(),
+		Replicas:   repl.GetReplicas(),
+	}
+	if info := resp.GetStats().GetLastSnapshot(); info != nil {
+		snapshot := newSnapshotInfo(info)
+		stats.LastSnapshot = &snapshot
+	}
+	return stats, nil
+}
+
+// Snapshot makes server write snapshot of all databases
+func (c *Client) Snapshot() (SnapshotInfo, error) {
+	resp, err := c.do(&godis_proto.Request{Operation: godis_proto.Operation_Snapshot})
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	return newSnapshotInfo(resp.GetSnapshot()), nil
+}
+
+func newSnapshotInfo(info *godis_proto.SnapshotInfo) SnapshotInfo {
+	return SnapshotInfo{
+		WALPosition: info.GetWalPosition(),
+		Keys:        info.GetKeys(),
+		Size:        info.GetSize(),
+		Created:     time.Unix(0, info.GetCreated()),
+		LSN:         info.GetLsn(),
+	}
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+const (
+	// StreamIDAuto lets server generate id of added entry
+	StreamIDAuto = "*"
+	// StreamIDLast means the last entry in stream at the moment of request,
+	// e.g. XRead with it returns only entries added after request
+	StreamIDLast = "$"
+	// StreamIDNew means entries which were never delivered to consumer group
+	StreamIDNew = ">"
+	// StreamIDMin and StreamIDMax are the smallest and the greatest possible ids
+	StreamIDMin = "-"
+	StreamIDMax = "+"
+)
+
+type StreamEntry struct {
+	ID     string
+	Fields map[string]string
+}
+
+type PendingEntry struct {
+	ID            string
+	Consumer      string
+	DeliveryTime  time.Time
+	DeliveryCount int
+}
+
+// XReadArgs - arguments of XRead and XReadGroup.
+// Streams maps stream key to id after which entries are read
+type XReadArgs struct {
+	Streams map[string]string
+	// Limit - max number of entries returned per stream, zero means no limit
+	Limit int
+	// Block - wait until entries are added if there are no entries at the moment
+	Block bool
+	// Timeout - max time to wait, zero means wait until ctx is done
+	Timeout time.Duration
+}
+
+// XAdd appends entry to stream and returns its id. Id must be greater than the last id in stream
+// or StreamIDAuto. If maxLen is positive the oldest entries are trimmed so stream contains at most maxLen entries.
+// If stream doesn't exist it's created with given ttl
+func (c *Client) XAdd(key, id string, fields map[string]string, maxLen int, ttl time.Duration) (string, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_XAdd,
+		Ids:       []string{id},
+		MaxLen:    uint32(maxLen),
+		Value: &godis_proto.Value{
+			Value: &godis_proto.Value_StringMap{StringMap: &godis_proto.MapString{StringMap: fields}},
+			Ttl:   ttl.Nanoseconds() + time.Now().UnixNano(),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetValue().GetStringVal(), nil
+}
+
+// XRange returns entries with ids from start to end inclusive, zero limit means no limit
+func (c *Client) XRange(key, start, end string, limit int) ([]StreamEntry, error) {
+	return c.streamRange(key, start, end, limit, false)
+}
+
+// XRevRange is same as XRange but entries are returned in reverse order
+func (c *Client) XRevRange(key, start, end string, limit int) ([]StreamEntry, error) {
+	return c.streamRange(key, start, end, limit, true)
+}
+
+func (c *Client) streamRange(key, start, end string, limit int, reverse bool) ([]StreamEntry, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_XRange,
+		Ids:       []string{start, end},
+		Limit:     uint32(limit),
+		Reverse:   reverse,
+	})
+	if err != nil {
+		return nil, err
+	}
+	streams := resp.GetStreams().GetStreams()
+	if len(streams) == 0 {
+		return nil, nil
+	}
+	return streamEntries(streams[0].GetEntries()), nil
+}
+
+// XLen returns number of entries in stream
+func (c *Client) XLen(key string) (int, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_XLen,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
+// XTrim removes the oldest entries, so stream contains at most maxLen entries, returns number of removed entries
+func (c *Client) XTrim(key string, maxLen int) (int, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_XTrim,
+		MaxLen:    uint32(maxLen),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
+// XRead returns entries added after given ids grouped by stream key. If args.Block is set and there are no
+// such entries it waits until entry is added, timeout is reached or ctx is done. ErrTimeout is returned on timeout
+func (c *Client) XRead(ctx context.Context, args XReadArgs) (map[string][]StreamEntry, error) {
+	return c.streamRead(ctx, &godis_proto.Request{Operation: godis_proto.Operation_XRead}, args)
+}
+
+// XGroupCreate creates consumer group which receives entries after given id, usually StreamIDLast or "0"
+func (c *Client) XGroupCreate(key, group, id string) error {
+	_, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_XGroupCreate,
+		Group:     group,
+		Ids:       []string{id},
+	})
+	return err
+}
+
+// XReadGroup reads entries as consumer of group. With StreamIDNew id entries which were never delivered
+// to group are returned and added to consumer pending list until they are acknowledged with XAck.
+// With any other id pending entries of consumer after it are returned
+func (c *Client) XReadGroup(ctx context.Context, group, consumer string, args XReadArgs) (map[string][]StreamEntry, error) {
+	return c.streamRead(ctx, &godis_proto.Request{
+		Operation: godis_proto.Operation_XReadGroup,
+		Group:     group,
+		Consumer:  consumer,
+	}, args)
+}
+
+func (c *Client) streamRead(ctx context.Context, req *godis_proto.Request, args XReadArgs) (map[string][]StreamEntry, error) {
+	for key, id := range args.Streams {
+		req.Keys = append(req.Keys, key)
+		req.Ids = append(req.Ids, id)
+	}
+	req.Limit = uint32(args.Limit)
+	req.Block = args.Block
+	req.Timeout = args.Timeout.Nanoseconds()
+
+	resp, err := c.writeRequestReadResponseContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := resp.GetStreams().GetStreams()
+	if len(streams) == 0 && args.Block {
+		return nil, ErrTimeout
+	}
+	result := make(map[string][]StreamEntry, len(streams))
+	for _, s := range streams {
+		result[s.GetKey()] = streamEntries(s.GetEntries())
+	}
+	return result, nil
+}
+
+// XAck removes entries from group pending list, returns number of acknowledged entries
+func (c *Client) XAck(key, group string, ids ...string) (int, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_XAck,
+		Group:     group,
+		Ids:       ids,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.GetCount()), nil
+}
+
+// XPending returns entries delivered to group but not acknowledged yet,
+// if consumer is not empty only its entries are returned
+func (c *Client) XPending(key, group, consumer string) ([]PendingEntry, error) {
+	resp, err := c.do(&godis_proto.Request{
+		Key:       key,
+		Operation: godis_proto.Operation_XPending,
+		Group:     group,
+		Consumer:  consumer,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result []PendingEntry
+	for _, p := range resp.GetPending().GetEntries() {
+		result = append(result, PendingEntry{
+			ID:            p.GetId(),
+			Consumer:      p.GetConsumer(),
+			DeliveryTime:  time.Unix(0, p.GetDeliveryTime()),
+			DeliveryCount: int(p.GetDeliveryCount()),
+		})
+	}
+	return result, nil
+}
+
+func streamEntries(entries []*godis_proto.StreamEntry) []StreamEntry {
+	result := make([]StreamEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, StreamEntry{ID: e.GetId(), Fields: e.GetFields()})
+	}
+	return result
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os"
+
+	"github.com/minaevmike/godis/encryption"
+)
+
+// encryptionKeysEnv - environment variable with encryption keys, it's used when key file isn't set
+const encryptionKeysEnv = "GODIS_ENCRYPTION_KEYS"
+
+// loadKeys loads encryption keys from file or environment, nil is returned if encryption isn't configured
+func loadKeys(file string) (*encryption.Keyring, error) {
+	if file != "" {
+		return encryption.LoadFile(file)
+	}
+	if _, ok := os.LookupEnv(encryptionKeysEnv); ok {
+		return encryption.FromEnv(encryptionKeysEnv)
+	}
+	return nil, nil
+}
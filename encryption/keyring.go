@@ -0,0 +1,150 @@
+// Package encryption encrypts data at rest with AES-GCM.
+//
+// Keys are identified by numeric IDs, every sealed message starts with 4 byte ID of its key and 12 byte nonce.
+// Data is encrypted with the current key and decrypted with the key which ID is stored in message,
+// so keys are rotated by adding new key and keeping old ones until data sealed with them is rewritten
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	idSize    = 4
+	nonceSize = 12
+)
+
+var (
+	// ErrUnknownKey is returned when data is sealed with key which isn't in keyring
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrDecrypt is returned when data can't be authenticated: key is wrong or data is damaged
+	ErrDecrypt = errors.New("can't decrypt data, encryption key is wrong or data is corrupted")
+)
+
+// Keyring keeps AES keys by their IDs
+type Keyring struct {
+	current uint32
+	keys    map[uint32]cipher.AEAD
+}
+
+// NewKeyring creates keyring which encrypts with key current, keys must be 16, 24 or 32 bytes long
+func NewKeyring(keys map[uint32][]byte, current uint32) (*Keyring, error) {
+	k := &Keyring{current: current, keys: make(map[uint32]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %v", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %v", id, err)
+		}
+		k.keys[id] = aead
+	}
+	if _, ok := k.keys[current]; !ok {
+		return nil, fmt.Errorf("%v: current key %d", ErrUnknownKey, current)
+	}
+	return k, nil
+}
+
+// Parse reads keys in form <id>:<hex key> separated by new lines or commas, lines starting with # are skipped.
+// The last key is current
+func Parse(data string) (*Keyring, error) {
+	keys := map[uint32][]byte{}
+	var current uint32
+	entries := strings.FieldsFunc(data, func(r rune) bool { return r == '\n' || r == ',' })
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("key must be in form <id>:<hex key>")
+		}
+		id, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("bad key id %q", parts[0])
+		}
+		key, err := hex.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("key %d isn't hex encoded", id)
+		}
+		if _, ok := keys[uint32(id)]; ok {
+			return nil, fmt.Errorf("key %d is duplicated", id)
+		}
+		keys[uint32(id)] = key
+		current = uint32(id)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no encryption keys")
+	}
+	return NewKeyring(keys, current)
+}
+
+// LoadFile reads keys from file, see Parse
+func LoadFile(file string) (*Keyring, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(string(data))
+}
+
+// FromEnv reads keys from environment variable, see Parse
+func FromEnv(name string) (*Keyring, error) {
+	data, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s isn't set", name)
+	}
+	return Parse(data)
+}
+
+// KeyID returns ID of key used for encryption
+func (k *Keyring) KeyID() uint32 {
+	return k.current
+}
+
+// Seal encrypts plaintext with current key, additional data isn't encrypted, but it must be the same on Open
+func (k *Keyring) Seal(plaintext, additional []byte) ([]byte, error) {
+	aead := k.keys[k.current]
+	sealed := make([]byte, idSize+nonceSize, idSize+nonceSize+len(plaintext)+aead.Overhead())
+	binary.BigEndian.PutUint32(sealed, k.current)
+	if _, err := io.ReadFull(rand.Reader, sealed[idSize:]); err != nil {
+		return nil, err
+	}
+	return aead.Seal(sealed, sealed[idSize:], plaintext, withID(sealed[:idSize], additional)), nil
+}
+
+// Open decrypts data sealed with any key of keyring
+func (k *Keyring) Open(sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < idSize+nonceSize {
+		return nil, ErrDecrypt
+	}
+	id := binary.BigEndian.Uint32(sealed)
+	aead, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%v: data is encrypted with key %d", ErrUnknownKey, id)
+	}
+	plaintext, err := aead.Open(nil, sealed[idSize:idSize+nonceSize], sealed[idSize+nonceSize:], withID(sealed[:idSize], additional))
+	if err != nil {
+		return nil, fmt.Errorf("%v (key %d)", ErrDecrypt, id)
+	}
+	return plaintext, nil
+}
+
+// withID returns additional data authenticated with key ID
+func withID(id, additional []byte) []byte {
+	return append(append([]byte(nil), id...), additional...)
+}
@@ -0,0 +1,86 @@
+package encryption
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const (
+	key1 = "000102030405060708090a0b0c0d0e0f"
+	key2 = "101112131415161718191a1b1c1d1e1f101112131415161718191a1b1c1d1e1f"
+)
+
+func TestKeyring_SealOpen(t *testing.T) {
+	k, err := Parse("# old key\n1:" + key1 + "\n2:" + key2 + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.KeyID() != 2 {
+		t.Fatalf("expected current key 2, got %d", k.KeyID())
+	}
+	sealed, err := k.Seal([]byte("secret"), []byte("ad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Fatal("plaintext is visible in sealed data")
+	}
+	plaintext, err := k.Open(sealed, []byte("ad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "secret" {
+		t.Fatalf("unexpected plaintext %q", plaintext)
+	}
+	if _, err := k.Open(sealed, []byte("other")); err == nil {
+		t.Fatal("data is opened with different additional data")
+	}
+
+	// data sealed with old key is opened after rotation
+	old, err := Parse("1:" + key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err = old.Seal([]byte("old"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := k.Open(sealed, nil); err != nil || string(plaintext) != "old" {
+		t.Fatalf("can't open data sealed with old key: %v", err)
+	}
+}
+
+func TestKeyring_WrongKey(t *testing.T) {
+	k, err := Parse("1:" + key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := k.Seal([]byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrong, err := Parse("1:" + strings.Repeat("ff", 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrong.Open(sealed, nil); err == nil || !strings.Contains(err.Error(), ErrDecrypt.Error()) {
+		t.Fatalf("expected decrypt error, got %v", err)
+	}
+	other, err := Parse("2:" + key2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Open(sealed, nil); err == nil || !strings.Contains(err.Error(), ErrUnknownKey.Error()) {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, data := range []string{"", "1", "x:" + key1, "1:zz", "1:0102", "1:" + key1 + ",1:" + key1} {
+		if _, err := Parse(data); err == nil {
+			t.Fatalf("keys %q are parsed", data)
+		}
+	}
+}
@@ -14,6 +14,34 @@ It has these top-level messages:
 	Value
 	RepeatedString
 	MapString
+	KeyValue
+	KeyValueList
+	Message
+	Database
+	StreamEntry
+	StreamPendingEntry
+	StreamConsumerGroup
+	Stream
+	StreamRead
+	StreamReadList
+	StreamPendingList
+	Job
+	Queue
+	JobList
+	QueueStats
+	Lock
+	RateLimitResult
+	RateLimitLogEntry
+	RateLimiter
+	DatabaseStats
+	ClientStats
+	ServerStats
+	ReplicationEvent
+	WalRecord
+	ReplicationStats
+	BackupInfo
+	SnapshotInfo
+	ConnectionStats
 */
 package godis_proto
 
@@ -32,41 +60,282 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type ErrorCode int32
+
+const (
+	// error without special code
+	ErrorCode_UnknownError ErrorCode = 0
+	// request was rejected because quota of database or client is exceeded
+	ErrorCode_QuotaExceeded ErrorCode = 1
+	// request exceeds server limits, e.g. key or value is too large
+	ErrorCode_LimitExceeded ErrorCode = 2
+	// request frame is too large or can't be decoded, connection is closed after this error
+	ErrorCode_BadRequest ErrorCode = 3
+	// connection was rejected because server or client host has too many connections
+	ErrorCode_TooManyConnections ErrorCode = 4
+	// write request was sent to replica, replicas serve only reads
+	ErrorCode_ReadOnly ErrorCode = 5
+)
+
+var ErrorCode_name = map[int32]string{
+	0: "UnknownError",
+	1: "QuotaExceeded",
+	2: "LimitExceeded",
+	3: "BadRequest",
+	4: "TooManyConnections",
+	5: "ReadOnly",
+}
+var ErrorCode_value = map[string]int32{
+	"UnknownError":       0,
+	"QuotaExceeded":      1,
+	"LimitExceeded":      2,
+	"BadRequest":         3,
+	"TooManyConnections": 4,
+	"ReadOnly":           5,
+}
+
+func (x ErrorCode) String() string {
+	return proto.EnumName(ErrorCode_name, int32(x))
+}
+func (ErrorCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
 type Operation int32
 
 const (
-	Operation_Remove     Operation = 0
-	Operation_Get        Operation = 1
-	Operation_Set        Operation = 2
-	Operation_Keys       Operation = 3
-	Operation_GetByIndex Operation = 4
-	Operation_GetByKey   Operation = 5
+	Operation_Remove              Operation = 0
+	Operation_Get                 Operation = 1
+	Operation_Set                 Operation = 2
+	Operation_Keys                Operation = 3
+	Operation_GetByIndex          Operation = 4
+	Operation_GetByKey            Operation = 5
+	Operation_Range               Operation = 6
+	Operation_Publish             Operation = 7
+	Operation_Subscribe           Operation = 8
+	Operation_PSubscribe          Operation = 9
+	Operation_Unsubscribe         Operation = 10
+	Operation_PUnsubscribe        Operation = 11
+	Operation_SubscribeKeyspace   Operation = 12
+	Operation_UnsubscribeKeyspace Operation = 13
+	Operation_LPush               Operation = 14
+	Operation_RPush               Operation = 15
+	Operation_LPop                Operation = 16
+	Operation_RPop                Operation = 17
+	Operation_BLPop               Operation = 18
+	Operation_BRPop               Operation = 19
+	Operation_XAdd                Operation = 20
+	Operation_XRange              Operation = 21
+	Operation_XLen                Operation = 22
+	Operation_XTrim               Operation = 23
+	Operation_XRead               Operation = 24
+	Operation_XGroupCreate        Operation = 25
+	Operation_XReadGroup          Operation = 26
+	Operation_XAck                Operation = 27
+	Operation_XPending            Operation = 28
+	Operation_QEnqueue            Operation = 29
+	Operation_QDequeue            Operation = 30
+	Operation_QAck                Operation = 31
+	Operation_QNack               Operation = 32
+	Operation_QStats              Operation = 33
+	Operation_QDead               Operation = 34
+	Operation_LockAcquire         Operation = 35
+	Operation_LockRenew           Operation = 36
+	Operation_LockRelease         Operation = 37
+	Operation_RateLimit           Operation = 38
+	Operation_Eval                Operation = 39
+	Operation_EvalSHA             Operation = 40
+	Operation_ScriptLoad          Operation = 41
+	Operation_Select              Operation = 42
+	Operation_FlushDB             Operation = 43
+	Operation_DBSize              Operation = 44
+	Operation_Stats               Operation = 45
+	// Snapshot writes snapshot of all databases and truncates wal records included into it
+	Operation_Snapshot Operation = 46
+	// Backup streams consistent backup to client as chunks followed by backup info,
+	// or writes it to file in server backup directory if path is set
+	Operation_Backup Operation = 47
+	// Expire sets ttl of existing key to value ttl
+	Operation_Expire Operation = 48
+	// HSet sets fields of map from value string_map, HDel deletes fields listed in value string_slice
+	Operation_HSet Operation = 49
+	Operation_HDel Operation = 50
+	// Incr adds number from value string_val to integer stored as string and returns result as count
+	Operation_Incr Operation = 51
+	// Replicate is sent by replica, primary streams its data and then new wal records, see ReplicationEvent
+	Operation_Replicate Operation = 52
 )
 
 var Operation_name = map[int32]string{
-	0: "Remove",
-	1: "get",
-	2: "set",
-	3: "Keys",
-	4: "GetByIndex",
-	5: "GetByKey",
+	0:  "Remove",
+	1:  "Get",
+	2:  "Set",
+	3:  "Keys",
+	4:  "GetByIndex",
+	5:  "GetByKey",
+	6:  "Range",
+	7:  "Publish",
+	8:  "Subscribe",
+	9:  "PSubscribe",
+	10: "Unsubscribe",
+	11: "PUnsubscribe",
+	12: "SubscribeKeyspace",
+	13: "UnsubscribeKeyspace",
+	14: "LPush",
+	15: "RPush",
+	16: "LPop",
+	17: "RPop",
+	18: "BLPop",
+	19: "BRPop",
+	20: "XAdd",
+	21: "XRange",
+	22: "XLen",
+	23: "XTrim",
+	24: "XRead",
+	25: "XGroupCreate",
+	26: "XReadGroup",
+	27: "XAck",
+	28: "XPending",
+	29: "QEnqueue",
+	30: "QDequeue",
+	31: "QAck",
+	32: "QNack",
+	33: "QStats",
+	34: "QDead",
+	35: "LockAcquire",
+	36: "LockRenew",
+	37: "LockRelease",
+	38: "RateLimit",
+	39: "Eval",
+	40: "EvalSHA",
+	41: "ScriptLoad",
+	42: "Select",
+	43: "FlushDB",
+	44: "DBSize",
+	45: "Stats",
+	46: "Snapshot",
+	47: "Backup",
+	48: "Expire",
+	49: "HSet",
+	50: "HDel",
+	51: "Incr",
+	52: "Replicate",
 }
 var Operation_value = map[string]int32{
-	"Remove":     0,
-	"get":        1,
-	"set":        2,
-	"Keys":       3,
-	"GetByIndex": 4,
-	"GetByKey":   5,
+	"Remove":              0,
+	"Get":                 1,
+	"Set":                 2,
+	"Keys":                3,
+	"GetByIndex":          4,
+	"GetByKey":            5,
+	"Range":               6,
+	"Publish":             7,
+	"Subscribe":           8,
+	"PSubscribe":          9,
+	"Unsubscribe":         10,
+	"PUnsubscribe":        11,
+	"SubscribeKeyspace":   12,
+	"UnsubscribeKeyspace": 13,
+	"LPush":               14,
+	"RPush":               15,
+	"LPop":                16,
+	"RPop":                17,
+	"BLPop":               18,
+	"BRPop":               19,
+	"XAdd":                20,
+	"XRange":              21,
+	"XLen":                22,
+	"XTrim":               23,
+	"XRead":               24,
+	"XGroupCreate":        25,
+	"XReadGroup":          26,
+	"XAck":                27,
+	"XPending":            28,
+	"QEnqueue":            29,
+	"QDequeue":            30,
+	"QAck":                31,
+	"QNack":               32,
+	"QStats":              33,
+	"QDead":               34,
+	"LockAcquire":         35,
+	"LockRenew":           36,
+	"LockRelease":         37,
+	"RateLimit":           38,
+	"Eval":                39,
+	"EvalSHA":             40,
+	"ScriptLoad":          41,
+	"Select":              42,
+	"FlushDB":             43,
+	"DBSize":              44,
+	"Stats":               45,
+	"Snapshot":            46,
+	"Backup":              47,
+	"Expire":              48,
+	"HSet":                49,
+	"HDel":                50,
+	"Incr":                51,
+	"Replicate":           52,
 }
 
 func (x Operation) String() string {
 	return proto.EnumName(Operation_name, int32(x))
 }
-func (Operation) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+func (Operation) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type RateLimitAlgorithm int32
+
+const (
+	// limit tokens are refilled evenly during window, cost is taken from bucket
+	RateLimitAlgorithm_TokenBucket RateLimitAlgorithm = 0
+	// sum of costs of requests allowed during the last window can't exceed limit
+	RateLimitAlgorithm_SlidingWindowLog RateLimitAlgorithm = 1
+)
+
+var RateLimitAlgorithm_name = map[int32]string{
+	0: "TokenBucket",
+	1: "SlidingWindowLog",
+}
+var RateLimitAlgorithm_value = map[string]int32{
+	"TokenBucket":      0,
+	"SlidingWindowLog": 1,
+}
+
+func (x RateLimitAlgorithm) String() string {
+	return proto.EnumName(RateLimitAlgorithm_name, int32(x))
+}
+func (RateLimitAlgorithm) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type EventType int32
+
+const (
+	EventType_NoEvent EventType = 0
+	// key was set
+	EventType_Written EventType = 1
+	// key was removed by remove request
+	EventType_Removed EventType = 2
+	// key was removed because its ttl expired
+	EventType_Expired EventType = 3
+)
+
+var EventType_name = map[int32]string{
+	0: "NoEvent",
+	1: "Written",
+	2: "Removed",
+	3: "Expired",
+}
+var EventType_value = map[string]int32{
+	"NoEvent": 0,
+	"Written": 1,
+	"Removed": 2,
+	"Expired": 3,
+}
+
+func (x EventType) String() string {
+	return proto.EnumName(EventType_name, int32(x))
+}
+func (EventType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 type Error struct {
-	Message string `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
+	Message string    `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
+	Code    ErrorCode `protobuf:"varint,2,opt,name=code,enum=godis_proto.ErrorCode" json:"code,omitempty"`
 }
 
 func (m *Error) Reset()                    { *m = Error{} }
@@ -81,6 +350,13 @@ func (m *Error) GetMessage() string {
 	return ""
 }
 
+func (m *Error) GetCode() ErrorCode {
+	if m != nil {
+		return m.Code
+	}
+	return ErrorCode_UnknownError
+}
+
 type Response struct {
 	// keys would be returned in `Keys` request otherwise value will be in result
 	//
@@ -88,6 +364,20 @@ type Response struct {
 	//	*Response_Error
 	//	*Response_Value
 	//	*Response_Keys
+	//	*Response_KeyValues
+	//	*Response_Message
+	//	*Response_Count
+	//	*Response_Streams
+	//	*Response_Pending
+	//	*Response_Jobs
+	//	*Response_QueueStats
+	//	*Response_Lock
+	//	*Response_RateLimit
+	//	*Response_Stats
+	//	*Response_Snapshot
+	//	*Response_Chunk
+	//	*Response_Backup
+	//	*Response_Replication
 	ResponseValue isResponse_ResponseValue `protobuf_oneof:"response_value"`
 }
 
@@ -109,10 +399,66 @@ type Response_Value struct {
 type Response_Keys struct {
 	Keys *RepeatedString `protobuf:"bytes,3,opt,name=keys,oneof"`
 }
+type Response_KeyValues struct {
+	KeyValues *KeyValueList `protobuf:"bytes,4,opt,name=key_values,json=keyValues,oneof"`
+}
+type Response_Message struct {
+	Message *Message `protobuf:"bytes,5,opt,name=message,oneof"`
+}
+type Response_Count struct {
+	Count int64 `protobuf:"varint,6,opt,name=count,oneof"`
+}
+type Response_Streams struct {
+	Streams *StreamReadList `protobuf:"bytes,7,opt,name=streams,oneof"`
+}
+type Response_Pending struct {
+	Pending *StreamPendingList `protobuf:"bytes,8,opt,name=pending,oneof"`
+}
+type Response_Jobs struct {
+	Jobs *JobList `protobuf:"bytes,9,opt,name=jobs,oneof"`
+}
+type Response_QueueStats struct {
+	QueueStats *QueueStats `protobuf:"bytes,10,opt,name=queue_stats,json=queueStats,oneof"`
+}
+type Response_Lock struct {
+	Lock *Lock `protobuf:"bytes,11,opt,name=lock,oneof"`
+}
+type Response_RateLimit struct {
+	RateLimit *RateLimitResult `protobuf:"bytes,12,opt,name=rate_limit,json=rateLimit,oneof"`
+}
+type Response_Stats struct {
+	Stats *ServerStats `protobuf:"bytes,13,opt,name=stats,oneof"`
+}
+type Response_Snapshot struct {
+	Snapshot *SnapshotInfo `protobuf:"bytes,14,opt,name=snapshot,oneof"`
+}
+type Response_Chunk struct {
+	Chunk []byte `protobuf:"bytes,15,opt,name=chunk,oneof"`
+}
+type Response_Backup struct {
+	Backup *BackupInfo `protobuf:"bytes,16,opt,name=backup,oneof"`
+}
+type Response_Replication struct {
+	Replication *ReplicationEvent `protobuf:"bytes,17,opt,name=replication,oneof"`
+}
 
-func (*Response_Error) isResponse_ResponseValue() {}
-func (*Response_Value) isResponse_ResponseValue() {}
-func (*Response_Keys) isResponse_ResponseValue()  {}
+func (*Response_Error) isResponse_ResponseValue()       {}
+func (*Response_Value) isResponse_ResponseValue()       {}
+func (*Response_Keys) isResponse_ResponseValue()        {}
+func (*Response_KeyValues) isResponse_ResponseValue()   {}
+func (*Response_Message) isResponse_ResponseValue()     {}
+func (*Response_Count) isResponse_ResponseValue()       {}
+func (*Response_Streams) isResponse_ResponseValue()     {}
+func (*Response_Pending) isResponse_ResponseValue()     {}
+func (*Response_Jobs) isResponse_ResponseValue()        {}
+func (*Response_QueueStats) isResponse_ResponseValue()  {}
+func (*Response_Lock) isResponse_ResponseValue()        {}
+func (*Response_RateLimit) isResponse_ResponseValue()   {}
+func (*Response_Stats) isResponse_ResponseValue()       {}
+func (*Response_Snapshot) isResponse_ResponseValue()    {}
+func (*Response_Chunk) isResponse_ResponseValue()       {}
+func (*Response_Backup) isResponse_ResponseValue()      {}
+func (*Response_Replication) isResponse_ResponseValue() {}
 
 func (m *Response) GetResponseValue() isResponse_ResponseValue {
 	if m != nil {
@@ -142,12 +488,124 @@ func (m *Response) GetKeys() *RepeatedString {
 	return nil
 }
 
+func (m *Response) GetKeyValues() *KeyValueList {
+	if x, ok := m.GetResponseValue().(*Response_KeyValues); ok {
+		return x.KeyValues
+	}
+	return nil
+}
+
+func (m *Response) GetMessage() *Message {
+	if x, ok := m.GetResponseValue().(*Response_Message); ok {
+		return x.Message
+	}
+	return nil
+}
+
+func (m *Response) GetCount() int64 {
+	if x, ok := m.GetResponseValue().(*Response_Count); ok {
+		return x.Count
+	}
+	return 0
+}
+
+func (m *Response) GetStreams() *StreamReadList {
+	if x, ok := m.GetResponseValue().(*Response_Streams); ok {
+		return x.Streams
+	}
+	return nil
+}
+
+func (m *Response) GetPending() *StreamPendingList {
+	if x, ok := m.GetResponseValue().(*Response_Pending); ok {
+		return x.Pending
+	}
+	return nil
+}
+
+func (m *Response) GetJobs() *JobList {
+	if x, ok := m.GetResponseValue().(*Response_Jobs); ok {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (m *Response) GetQueueStats() *QueueStats {
+	if x, ok := m.GetResponseValue().(*Response_QueueStats); ok {
+		return x.QueueStats
+	}
+	return nil
+}
+
+func (m *Response) GetLock() *Lock {
+	if x, ok := m.GetResponseValue().(*Response_Lock); ok {
+		return x.Lock
+	}
+	return nil
+}
+
+func (m *Response) GetRateLimit() *RateLimitResult {
+	if x, ok := m.GetResponseValue().(*Response_RateLimit); ok {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (m *Response) GetStats() *ServerStats {
+	if x, ok := m.GetResponseValue().(*Response_Stats); ok {
+		return x.Stats
+	}
+	return nil
+}
+
+func (m *Response) GetSnapshot() *SnapshotInfo {
+	if x, ok := m.GetResponseValue().(*Response_Snapshot); ok {
+		return x.Snapshot
+	}
+	return nil
+}
+
+func (m *Response) GetChunk() []byte {
+	if x, ok := m.GetResponseValue().(*Response_Chunk); ok {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (m *Response) GetBackup() *BackupInfo {
+	if x, ok := m.GetResponseValue().(*Response_Backup); ok {
+		return x.Backup
+	}
+	return nil
+}
+
+func (m *Response) GetReplication() *ReplicationEvent {
+	if x, ok := m.GetResponseValue().(*Response_Replication); ok {
+		return x.Replication
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Response) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Response_OneofMarshaler, _Response_OneofUnmarshaler, _Response_OneofSizer, []interface{}{
 		(*Response_Error)(nil),
 		(*Response_Value)(nil),
 		(*Response_Keys)(nil),
+		(*Response_KeyValues)(nil),
+		(*Response_Message)(nil),
+		(*Response_Count)(nil),
+		(*Response_Streams)(nil),
+		(*Response_Pending)(nil),
+		(*Response_Jobs)(nil),
+		(*Response_QueueStats)(nil),
+		(*Response_Lock)(nil),
+		(*Response_RateLimit)(nil),
+		(*Response_Stats)(nil),
+		(*Response_Snapshot)(nil),
+		(*Response_Chunk)(nil),
+		(*Response_Backup)(nil),
+		(*Response_Replication)(nil),
 	}
 }
 
@@ -170,6 +628,72 @@ func _Response_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Keys); err != nil {
 			return err
 		}
+	case *Response_KeyValues:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.KeyValues); err != nil {
+			return err
+		}
+	case *Response_Message:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Message); err != nil {
+			return err
+		}
+	case *Response_Count:
+		b.EncodeVarint(6<<3 | proto.WireVarint)
+		b.EncodeVarint(uint64(x.Count))
+	case *Response_Streams:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Streams); err != nil {
+			return err
+		}
+	case *Response_Pending:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Pending); err != nil {
+			return err
+		}
+	case *Response_Jobs:
+		b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Jobs); err != nil {
+			return err
+		}
+	case *Response_QueueStats:
+		b.EncodeVarint(10<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.QueueStats); err != nil {
+			return err
+		}
+	case *Response_Lock:
+		b.EncodeVarint(11<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Lock); err != nil {
+			return err
+		}
+	case *Response_RateLimit:
+		b.EncodeVarint(12<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.RateLimit); err != nil {
+			return err
+		}
+	case *Response_Stats:
+		b.EncodeVarint(13<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Stats); err != nil {
+			return err
+		}
+	case *Response_Snapshot:
+		b.EncodeVarint(14<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Snapshot); err != nil {
+			return err
+		}
+	case *Response_Chunk:
+		b.EncodeVarint(15<<3 | proto.WireBytes)
+		b.EncodeRawBytes(x.Chunk)
+	case *Response_Backup:
+		b.EncodeVarint(16<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Backup); err != nil {
+			return err
+		}
+	case *Response_Replication:
+		b.EncodeVarint(17<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Replication); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Response.ResponseValue has unexpected type %T", x)
@@ -204,6 +728,116 @@ func _Response_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffe
 		err := b.DecodeMessage(msg)
 		m.ResponseValue = &Response_Keys{msg}
 		return true, err
+	case 4: // response_value.key_values
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(KeyValueList)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_KeyValues{msg}
+		return true, err
+	case 5: // response_value.message
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Message)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Message{msg}
+		return true, err
+	case 6: // response_value.count
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.ResponseValue = &Response_Count{int64(x)}
+		return true, err
+	case 7: // response_value.streams
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(StreamReadList)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Streams{msg}
+		return true, err
+	case 8: // response_value.pending
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(StreamPendingList)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Pending{msg}
+		return true, err
+	case 9: // response_value.jobs
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(JobList)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Jobs{msg}
+		return true, err
+	case 10: // response_value.queue_stats
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(QueueStats)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_QueueStats{msg}
+		return true, err
+	case 11: // response_value.lock
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Lock)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Lock{msg}
+		return true, err
+	case 12: // response_value.rate_limit
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RateLimitResult)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_RateLimit{msg}
+		return true, err
+	case 13: // response_value.stats
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ServerStats)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Stats{msg}
+		return true, err
+	case 14: // response_value.snapshot
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SnapshotInfo)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Snapshot{msg}
+		return true, err
+	case 15: // response_value.chunk
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeRawBytes(true)
+		m.ResponseValue = &Response_Chunk{x}
+		return true, err
+	case 16: // response_value.backup
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BackupInfo)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Backup{msg}
+		return true, err
+	case 17: // response_value.replication
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ReplicationEvent)
+		err := b.DecodeMessage(msg)
+		m.ResponseValue = &Response_Replication{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -228,6 +862,73 @@ func _Response_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(3<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Response_KeyValues:
+		s := proto.Size(x.KeyValues)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Message:
+		s := proto.Size(x.Message)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Count:
+		n += proto.SizeVarint(6<<3 | proto.WireVarint)
+		n += proto.SizeVarint(uint64(x.Count))
+	case *Response_Streams:
+		s := proto.Size(x.Streams)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Pending:
+		s := proto.Size(x.Pending)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Jobs:
+		s := proto.Size(x.Jobs)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_QueueStats:
+		s := proto.Size(x.QueueStats)
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Lock:
+		s := proto.Size(x.Lock)
+		n += proto.SizeVarint(11<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_RateLimit:
+		s := proto.Size(x.RateLimit)
+		n += proto.SizeVarint(12<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Stats:
+		s := proto.Size(x.Stats)
+		n += proto.SizeVarint(13<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Snapshot:
+		s := proto.Size(x.Snapshot)
+		n += proto.SizeVarint(14<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Chunk:
+		n += proto.SizeVarint(15<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Chunk)))
+		n += len(x.Chunk)
+	case *Response_Backup:
+		s := proto.Size(x.Backup)
+		n += proto.SizeVarint(16<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Response_Replication:
+		s := proto.Size(x.Replication)
+		n += proto.SizeVarint(17<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -238,12 +939,75 @@ func _Response_OneofSizer(msg proto.Message) (n int) {
 type Request struct {
 	Key       string    `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
 	Operation Operation `protobuf:"varint,2,opt,name=operation,enum=godis_proto.Operation" json:"operation,omitempty"`
-	// value usefull only on set
+	// value usefull only on set, push, XAdd, Expire and map and counter updates. On push string_slice contains pushed elements,
+	// on XAdd string_map contains entry fields, ttl is used if key doesn't exist
 	Value *Value `protobuf:"bytes,3,opt,name=value" json:"value,omitempty"`
 	// index usefull only on get by index
 	Index uint32 `protobuf:"varint,4,opt,name=index" json:"index,omitempty"`
 	// map_key usefull only on get by key
 	MapKey string `protobuf:"bytes,5,opt,name=map_key,json=mapKey" json:"map_key,omitempty"`
+	// end_key usefull only on range, key is used as start of the range
+	EndKey string `protobuf:"bytes,6,opt,name=end_key,json=endKey" json:"end_key,omitempty"`
+	// limit usefull only on range, stream reads, QDequeue and QDead, on RateLimit it's max cost allowed per window
+	Limit uint32 `protobuf:"varint,7,opt,name=limit" json:"limit,omitempty"`
+	// reverse usefull only on range
+	Reverse bool `protobuf:"varint,8,opt,name=reverse" json:"reverse,omitempty"`
+	// page_token usefull only on range
+	PageToken string `protobuf:"bytes,9,opt,name=page_token,json=pageToken" json:"page_token,omitempty"`
+	// channels usefull only on (un)subscribe and contains channels or patterns,
+	// empty list on unsubscribe means all channels or patterns
+	Channels []string `protobuf:"bytes,10,rep,name=channels" json:"channels,omitempty"`
+	// payload usefull only on publish and QEnqueue, key is used as channel on publish
+	Payload []byte `protobuf:"bytes,11,opt,name=payload" json:"payload,omitempty"`
+	// events usefull only on subscribe keyspace, key is used as regexp of keys, empty list means all events
+	Events []EventType `protobuf:"varint,12,rep,packed,name=events,enum=godis_proto.EventType" json:"events,omitempty"`
+	// keys usefull only on blocking pops, stream reads and scripts, keys are checked in given order
+	Keys []string `protobuf:"bytes,13,rep,name=keys" json:"keys,omitempty"`
+	// timeout usefull only on blocking operations, in nanoseconds, zero means wait forever.
+	// On Eval and EvalSHA it's max execution time of script, zero means server default
+	Timeout int64 `protobuf:"varint,14,opt,name=timeout" json:"timeout,omitempty"`
+	// ids usefull only on stream operations: entry id on XAdd, start and end of range on XRange,
+	// last read ids for every key on XRead and XReadGroup, start id on XGroupCreate and acknowledged ids on XAck.
+	// On QAck and QNack ids contains job ids
+	Ids []string `protobuf:"bytes,15,rep,name=ids" json:"ids,omitempty"`
+	// max_len usefull only on XAdd and XTrim
+	MaxLen uint32 `protobuf:"varint,16,opt,name=max_len,json=maxLen" json:"max_len,omitempty"`
+	// block usefull only on XRead and XReadGroup, when set request waits for new entries until timeout
+	Block bool `protobuf:"varint,17,opt,name=block" json:"block,omitempty"`
+	// group and consumer usefull only on stream consumer group operations
+	Group    string `protobuf:"bytes,18,opt,name=group" json:"group,omitempty"`
+	Consumer string `protobuf:"bytes,19,opt,name=consumer" json:"consumer,omitempty"`
+	// delay usefull only on QEnqueue and QNack, in nanoseconds job becomes visible to QDequeue after
+	Delay int64 `protobuf:"varint,20,opt,name=delay" json:"delay,omitempty"`
+	// visibility_timeout usefull only on QDequeue, in nanoseconds job is hidden from other workers for,
+	// job which isn't acknowledged during this time is delivered again
+	VisibilityTimeout int64 `protobuf:"varint,21,opt,name=visibility_timeout,json=visibilityTimeout" json:"visibility_timeout,omitempty"`
+	// max_attempts usefull only on QEnqueue, job is moved to dead letters after this amount of failed deliveries,
+	// zero means unlimited
+	MaxAttempts uint32 `protobuf:"varint,22,opt,name=max_attempts,json=maxAttempts" json:"max_attempts,omitempty"`
+	// owner usefull only on lock operations
+	Owner string `protobuf:"bytes,23,opt,name=owner" json:"owner,omitempty"`
+	// lease usefull only on LockAcquire and LockRenew, in nanoseconds lock is held for
+	Lease int64 `protobuf:"varint,24,opt,name=lease" json:"lease,omitempty"`
+	// window, cost and algorithm usefull only on RateLimit, window is in nanoseconds, zero cost means 1
+	Window    int64              `protobuf:"varint,25,opt,name=window" json:"window,omitempty"`
+	Cost      uint32             `protobuf:"varint,26,opt,name=cost" json:"cost,omitempty"`
+	Algorithm RateLimitAlgorithm `protobuf:"varint,27,opt,name=algorithm,enum=godis_proto.RateLimitAlgorithm" json:"algorithm,omitempty"`
+	// script usefull only on Eval and ScriptLoad, it contains lua source
+	Script string `protobuf:"bytes,28,opt,name=script" json:"script,omitempty"`
+	// sha usefull only on EvalSHA, it contains sha1 of script returned by ScriptLoad
+	Sha string `protobuf:"bytes,29,opt,name=sha" json:"sha,omitempty"`
+	// args usefull only on Eval and EvalSHA, they are available in script as ARGV
+	Args []string `protobuf:"bytes,30,rep,name=args" json:"args,omitempty"`
+	// database selects database for this request only, if it isn't set database selected
+	// for connection is used (0 by default). On Select it's database selected for connection
+	Database *Database `protobuf:"bytes,31,opt,name=database" json:"database,omitempty"`
+	// durable makes server send response only after writes made by request are synced to disk
+	Durable bool `protobuf:"varint,32,opt,name=durable" json:"durable,omitempty"`
+	// path usefull only on Backup, it's name of file in server backup directory
+	Path string `protobuf:"bytes,33,opt,name=path" json:"path,omitempty"`
+	// lsn usefull only on Replicate, it's LSN of the last record applied by replica, zero requests full resync
+	Lsn uint64 `protobuf:"varint,34,opt,name=lsn" json:"lsn,omitempty"`
 }
 
 func (m *Request) Reset()                    { *m = Request{} }
@@ -286,42 +1050,265 @@ func (m *Request) GetMapKey() string {
 	return ""
 }
 
-type Value struct {
-	// Types that are valid to be assigned to Value:
-	//	*Value_StringVal
-	//	*Value_StringSlice
-	//	*Value_StringMap
-	Value isValue_Value `protobuf_oneof:"value"`
-	// unix nanoseconds until this value is valid
-	Ttl int64 `protobuf:"varint,4,opt,name=ttl" json:"ttl,omitempty"`
+func (m *Request) GetEndKey() string {
+	if m != nil {
+		return m.EndKey
+	}
+	return ""
 }
 
-func (m *Value) Reset()                    { *m = Value{} }
-func (m *Value) String() string            { return proto.CompactTextString(m) }
-func (*Value) ProtoMessage()               {}
-func (*Value) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
-
-type isValue_Value interface {
-	isValue_Value()
+func (m *Request) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
 }
 
-type Value_StringVal struct {
-	StringVal string `protobuf:"bytes,1,opt,name=string_val,json=stringVal,oneof"`
-}
-type Value_StringSlice struct {
-	StringSlice *RepeatedString `protobuf:"bytes,2,opt,name=string_slice,json=stringSlice,oneof"`
-}
-type Value_StringMap struct {
-	StringMap *MapString `protobuf:"bytes,3,opt,name=string_map,json=stringMap,oneof"`
+func (m *Request) GetReverse() bool {
+	if m != nil {
+		return m.Reverse
+	}
+	return false
 }
 
-func (*Value_StringVal) isValue_Value()   {}
-func (*Value_StringSlice) isValue_Value() {}
-func (*Value_StringMap) isValue_Value()   {}
+func (m *Request) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
 
-func (m *Value) GetValue() isValue_Value {
+func (m *Request) GetChannels() []string {
 	if m != nil {
-		return m.Value
+		return m.Channels
+	}
+	return nil
+}
+
+func (m *Request) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Request) GetEvents() []EventType {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *Request) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *Request) GetTimeout() int64 {
+	if m != nil {
+		return m.Timeout
+	}
+	return 0
+}
+
+func (m *Request) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+func (m *Request) GetMaxLen() uint32 {
+	if m != nil {
+		return m.MaxLen
+	}
+	return 0
+}
+
+func (m *Request) GetBlock() bool {
+	if m != nil {
+		return m.Block
+	}
+	return false
+}
+
+func (m *Request) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *Request) GetConsumer() string {
+	if m != nil {
+		return m.Consumer
+	}
+	return ""
+}
+
+func (m *Request) GetDelay() int64 {
+	if m != nil {
+		return m.Delay
+	}
+	return 0
+}
+
+func (m *Request) GetVisibilityTimeout() int64 {
+	if m != nil {
+		return m.VisibilityTimeout
+	}
+	return 0
+}
+
+func (m *Request) GetMaxAttempts() uint32 {
+	if m != nil {
+		return m.MaxAttempts
+	}
+	return 0
+}
+
+func (m *Request) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Request) GetLease() int64 {
+	if m != nil {
+		return m.Lease
+	}
+	return 0
+}
+
+func (m *Request) GetWindow() int64 {
+	if m != nil {
+		return m.Window
+	}
+	return 0
+}
+
+func (m *Request) GetCost() uint32 {
+	if m != nil {
+		return m.Cost
+	}
+	return 0
+}
+
+func (m *Request) GetAlgorithm() RateLimitAlgorithm {
+	if m != nil {
+		return m.Algorithm
+	}
+	return RateLimitAlgorithm_TokenBucket
+}
+
+func (m *Request) GetScript() string {
+	if m != nil {
+		return m.Script
+	}
+	return ""
+}
+
+func (m *Request) GetSha() string {
+	if m != nil {
+		return m.Sha
+	}
+	return ""
+}
+
+func (m *Request) GetArgs() []string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+func (m *Request) GetDatabase() *Database {
+	if m != nil {
+		return m.Database
+	}
+	return nil
+}
+
+func (m *Request) GetDurable() bool {
+	if m != nil {
+		return m.Durable
+	}
+	return false
+}
+
+func (m *Request) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Request) GetLsn() uint64 {
+	if m != nil {
+		return m.Lsn
+	}
+	return 0
+}
+
+type Value struct {
+	// Types that are valid to be assigned to Value:
+	//	*Value_StringVal
+	//	*Value_StringSlice
+	//	*Value_StringMap
+	//	*Value_Stream
+	//	*Value_Queue
+	//	*Value_Lock
+	//	*Value_RateLimiter
+	Value isValue_Value `protobuf_oneof:"value"`
+	// unix nanoseconds until this value is valid
+	Ttl int64 `protobuf:"varint,4,opt,name=ttl" json:"ttl,omitempty"`
+}
+
+func (m *Value) Reset()                    { *m = Value{} }
+func (m *Value) String() string            { return proto.CompactTextString(m) }
+func (*Value) ProtoMessage()               {}
+func (*Value) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type isValue_Value interface {
+	isValue_Value()
+}
+
+type Value_StringVal struct {
+	StringVal string `protobuf:"bytes,1,opt,name=string_val,json=stringVal,oneof"`
+}
+type Value_StringSlice struct {
+	StringSlice *RepeatedString `protobuf:"bytes,2,opt,name=string_slice,json=stringSlice,oneof"`
+}
+type Value_StringMap struct {
+	StringMap *MapString `protobuf:"bytes,3,opt,name=string_map,json=stringMap,oneof"`
+}
+type Value_Stream struct {
+	Stream *Stream `protobuf:"bytes,5,opt,name=stream,oneof"`
+}
+type Value_Queue struct {
+	Queue *Queue `protobuf:"bytes,6,opt,name=queue,oneof"`
+}
+type Value_Lock struct {
+	Lock *Lock `protobuf:"bytes,7,opt,name=lock,oneof"`
+}
+type Value_RateLimiter struct {
+	RateLimiter *RateLimiter `protobuf:"bytes,8,opt,name=rate_limiter,json=rateLimiter,oneof"`
+}
+
+func (*Value_StringVal) isValue_Value()   {}
+func (*Value_StringSlice) isValue_Value() {}
+func (*Value_StringMap) isValue_Value()   {}
+func (*Value_Stream) isValue_Value()      {}
+func (*Value_Queue) isValue_Value()       {}
+func (*Value_Lock) isValue_Value()        {}
+func (*Value_RateLimiter) isValue_Value() {}
+
+func (m *Value) GetValue() isValue_Value {
+	if m != nil {
+		return m.Value
 	}
 	return nil
 }
@@ -347,6 +1334,34 @@ func (m *Value) GetStringMap() *MapString {
 	return nil
 }
 
+func (m *Value) GetStream() *Stream {
+	if x, ok := m.GetValue().(*Value_Stream); ok {
+		return x.Stream
+	}
+	return nil
+}
+
+func (m *Value) GetQueue() *Queue {
+	if x, ok := m.GetValue().(*Value_Queue); ok {
+		return x.Queue
+	}
+	return nil
+}
+
+func (m *Value) GetLock() *Lock {
+	if x, ok := m.GetValue().(*Value_Lock); ok {
+		return x.Lock
+	}
+	return nil
+}
+
+func (m *Value) GetRateLimiter() *RateLimiter {
+	if x, ok := m.GetValue().(*Value_RateLimiter); ok {
+		return x.RateLimiter
+	}
+	return nil
+}
+
 func (m *Value) GetTtl() int64 {
 	if m != nil {
 		return m.Ttl
@@ -360,6 +1375,10 @@ func (*Value) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error,
 		(*Value_StringVal)(nil),
 		(*Value_StringSlice)(nil),
 		(*Value_StringMap)(nil),
+		(*Value_Stream)(nil),
+		(*Value_Queue)(nil),
+		(*Value_Lock)(nil),
+		(*Value_RateLimiter)(nil),
 	}
 }
 
@@ -380,6 +1399,26 @@ func _Value_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.StringMap); err != nil {
 			return err
 		}
+	case *Value_Stream:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Stream); err != nil {
+			return err
+		}
+	case *Value_Queue:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Queue); err != nil {
+			return err
+		}
+	case *Value_Lock:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Lock); err != nil {
+			return err
+		}
+	case *Value_RateLimiter:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.RateLimiter); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Value.Value has unexpected type %T", x)
@@ -413,6 +1452,38 @@ func _Value_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer)
 		err := b.DecodeMessage(msg)
 		m.Value = &Value_StringMap{msg}
 		return true, err
+	case 5: // value.stream
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Stream)
+		err := b.DecodeMessage(msg)
+		m.Value = &Value_Stream{msg}
+		return true, err
+	case 6: // value.queue
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Queue)
+		err := b.DecodeMessage(msg)
+		m.Value = &Value_Queue{msg}
+		return true, err
+	case 7: // value.lock
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Lock)
+		err := b.DecodeMessage(msg)
+		m.Value = &Value_Lock{msg}
+		return true, err
+	case 8: // value.rate_limiter
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RateLimiter)
+		err := b.DecodeMessage(msg)
+		m.Value = &Value_RateLimiter{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -436,6 +1507,26 @@ func _Value_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(3<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Value_Stream:
+		s := proto.Size(x.Stream)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Value_Queue:
+		s := proto.Size(x.Queue)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Value_Lock:
+		s := proto.Size(x.Lock)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Value_RateLimiter:
+		s := proto.Size(x.RateLimiter)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -475,6 +1566,1119 @@ func (m *MapString) GetStringMap() map[string]string {
 	return nil
 }
 
+type KeyValue struct {
+	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Value *Value `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *KeyValue) Reset()                    { *m = KeyValue{} }
+func (m *KeyValue) String() string            { return proto.CompactTextString(m) }
+func (*KeyValue) ProtoMessage()               {}
+func (*KeyValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *KeyValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *KeyValue) GetValue() *Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type KeyValueList struct {
+	Items []*KeyValue `protobuf:"bytes,1,rep,name=items" json:"items,omitempty"`
+	// next_page_token is empty when there are no more items in the range
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken" json:"next_page_token,omitempty"`
+}
+
+func (m *KeyValueList) Reset()                    { *m = KeyValueList{} }
+func (m *KeyValueList) String() string            { return proto.CompactTextString(m) }
+func (*KeyValueList) ProtoMessage()               {}
+func (*KeyValueList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *KeyValueList) GetItems() []*KeyValue {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+func (m *KeyValueList) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+type Message struct {
+	Channel string `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+	// pattern is set when message is received by pattern subscription
+	Pattern string `protobuf:"bytes,2,opt,name=pattern" json:"pattern,omitempty"`
+	Payload []byte `protobuf:"bytes,3,opt,name=payload" json:"payload,omitempty"`
+	// key and event are set for keyspace notifications
+	Key   string    `protobuf:"bytes,4,opt,name=key" json:"key,omitempty"`
+	Event EventType `protobuf:"varint,5,opt,name=event,enum=godis_proto.EventType" json:"event,omitempty"`
+	// database of key for keyspace notifications
+	Database uint32 `protobuf:"varint,6,opt,name=database" json:"database,omitempty"`
+}
+
+func (m *Message) Reset()                    { *m = Message{} }
+func (m *Message) String() string            { return proto.CompactTextString(m) }
+func (*Message) ProtoMessage()               {}
+func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *Message) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *Message) GetPattern() string {
+	if m != nil {
+		return m.Pattern
+	}
+	return ""
+}
+
+func (m *Message) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Message) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Message) GetEvent() EventType {
+	if m != nil {
+		return m.Event
+	}
+	return EventType_NoEvent
+}
+
+func (m *Message) GetDatabase() uint32 {
+	if m != nil {
+		return m.Database
+	}
+	return 0
+}
+
+type Database struct {
+	Index uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+}
+
+func (m *Database) Reset()                    { *m = Database{} }
+func (m *Database) String() string            { return proto.CompactTextString(m) }
+func (*Database) ProtoMessage()               {}
+func (*Database) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *Database) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+type StreamEntry struct {
+	Id     string            `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Fields map[string]string `protobuf:"bytes,2,rep,name=fields" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *StreamEntry) Reset()                    { *m = StreamEntry{} }
+func (m *StreamEntry) String() string            { return proto.CompactTextString(m) }
+func (*StreamEntry) ProtoMessage()               {}
+func (*StreamEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *StreamEntry) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *StreamEntry) GetFields() map[string]string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+type StreamPendingEntry struct {
+	Id       string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Consumer string `protobuf:"bytes,2,opt,name=consumer" json:"consumer,omitempty"`
+	// unix nanoseconds of the last delivery
+	DeliveryTime  int64 `protobuf:"varint,3,opt,name=delivery_time,json=deliveryTime" json:"delivery_time,omitempty"`
+	DeliveryCount int64 `protobuf:"varint,4,opt,name=delivery_count,json=deliveryCount" json:"delivery_count,omitempty"`
+}
+
+func (m *StreamPendingEntry) Reset()                    { *m = StreamPendingEntry{} }
+func (m *StreamPendingEntry) String() string            { return proto.CompactTextString(m) }
+func (*StreamPendingEntry) ProtoMessage()               {}
+func (*StreamPendingEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *StreamPendingEntry) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *StreamPendingEntry) GetConsumer() string {
+	if m != nil {
+		return m.Consumer
+	}
+	return ""
+}
+
+func (m *StreamPendingEntry) GetDeliveryTime() int64 {
+	if m != nil {
+		return m.DeliveryTime
+	}
+	return 0
+}
+
+func (m *StreamPendingEntry) GetDeliveryCount() int64 {
+	if m != nil {
+		return m.DeliveryCount
+	}
+	return 0
+}
+
+type StreamConsumerGroup struct {
+	Name            string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	LastDeliveredId string `protobuf:"bytes,2,opt,name=last_delivered_id,json=lastDeliveredId" json:"last_delivered_id,omitempty"`
+	// pending entries were delivered to consumers but weren't acknowledged yet, ordered by id
+	Pending []*StreamPendingEntry `protobuf:"bytes,3,rep,name=pending" json:"pending,omitempty"`
+}
+
+func (m *StreamConsumerGroup) Reset()                    { *m = StreamConsumerGroup{} }
+func (m *StreamConsumerGroup) String() string            { return proto.CompactTextString(m) }
+func (*StreamConsumerGroup) ProtoMessage()               {}
+func (*StreamConsumerGroup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *StreamConsumerGroup) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *StreamConsumerGroup) GetLastDeliveredId() string {
+	if m != nil {
+		return m.LastDeliveredId
+	}
+	return ""
+}
+
+func (m *StreamConsumerGroup) GetPending() []*StreamPendingEntry {
+	if m != nil {
+		return m.Pending
+	}
+	return nil
+}
+
+type Stream struct {
+	// entries ordered by id
+	Entries []*StreamEntry         `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+	LastId  string                 `protobuf:"bytes,2,opt,name=last_id,json=lastId" json:"last_id,omitempty"`
+	Groups  []*StreamConsumerGroup `protobuf:"bytes,3,rep,name=groups" json:"groups,omitempty"`
+}
+
+func (m *Stream) Reset()                    { *m = Stream{} }
+func (m *Stream) String() string            { return proto.CompactTextString(m) }
+func (*Stream) ProtoMessage()               {}
+func (*Stream) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *Stream) GetEntries() []*StreamEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *Stream) GetLastId() string {
+	if m != nil {
+		return m.LastId
+	}
+	return ""
+}
+
+func (m *Stream) GetGroups() []*StreamConsumerGroup {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+type StreamRead struct {
+	Key     string         `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Entries []*StreamEntry `protobuf:"bytes,2,rep,name=entries" json:"entries,omitempty"`
+}
+
+func (m *StreamRead) Reset()                    { *m = StreamRead{} }
+func (m *StreamRead) String() string            { return proto.CompactTextString(m) }
+func (*StreamRead) ProtoMessage()               {}
+func (*StreamRead) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *StreamRead) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *StreamRead) GetEntries() []*StreamEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type StreamReadList struct {
+	Streams []*StreamRead `protobuf:"bytes,1,rep,name=streams" json:"streams,omitempty"`
+}
+
+func (m *StreamReadList) Reset()                    { *m = StreamReadList{} }
+func (m *StreamReadList) String() string            { return proto.CompactTextString(m) }
+func (*StreamReadList) ProtoMessage()               {}
+func (*StreamReadList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *StreamReadList) GetStreams() []*StreamRead {
+	if m != nil {
+		return m.Streams
+	}
+	return nil
+}
+
+type StreamPendingList struct {
+	Entries []*StreamPendingEntry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+}
+
+func (m *StreamPendingList) Reset()                    { *m = StreamPendingList{} }
+func (m *StreamPendingList) String() string            { return proto.CompactTextString(m) }
+func (*StreamPendingList) ProtoMessage()               {}
+func (*StreamPendingList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *StreamPendingList) GetEntries() []*StreamPendingEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type Job struct {
+	Id      string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Payload []byte `protobuf:"bytes,2,opt,name=payload" json:"payload,omitempty"`
+	// unix nanoseconds when job becomes visible to QDequeue, for delivered job it's visibility deadline
+	VisibleAt int64 `protobuf:"varint,3,opt,name=visible_at,json=visibleAt" json:"visible_at,omitempty"`
+	// attempts - amount of deliveries
+	Attempts    uint32 `protobuf:"varint,4,opt,name=attempts" json:"attempts,omitempty"`
+	MaxAttempts uint32 `protobuf:"varint,5,opt,name=max_attempts,json=maxAttempts" json:"max_attempts,omitempty"`
+	// unix nanoseconds
+	EnqueuedAt int64 `protobuf:"varint,6,opt,name=enqueued_at,json=enqueuedAt" json:"enqueued_at,omitempty"`
+}
+
+func (m *Job) Reset()                    { *m = Job{} }
+func (m *Job) String() string            { return proto.CompactTextString(m) }
+func (*Job) ProtoMessage()               {}
+func (*Job) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *Job) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Job) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Job) GetVisibleAt() int64 {
+	if m != nil {
+		return m.VisibleAt
+	}
+	return 0
+}
+
+func (m *Job) GetAttempts() uint32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *Job) GetMaxAttempts() uint32 {
+	if m != nil {
+		return m.MaxAttempts
+	}
+	return 0
+}
+
+func (m *Job) GetEnqueuedAt() int64 {
+	if m != nil {
+		return m.EnqueuedAt
+	}
+	return 0
+}
+
+type Queue struct {
+	// waiting jobs ordered by visible_at
+	Waiting []*Job `protobuf:"bytes,1,rep,name=waiting" json:"waiting,omitempty"`
+	// in_flight jobs were delivered but weren't acknowledged yet
+	InFlight []*Job `protobuf:"bytes,2,rep,name=in_flight,json=inFlight" json:"in_flight,omitempty"`
+	Dead     []*Job `protobuf:"bytes,3,rep,name=dead" json:"dead,omitempty"`
+	LastId   uint64 `protobuf:"varint,4,opt,name=last_id,json=lastId" json:"last_id,omitempty"`
+}
+
+func (m *Queue) Reset()                    { *m = Queue{} }
+func (m *Queue) String() string            { return proto.CompactTextString(m) }
+func (*Queue) ProtoMessage()               {}
+func (*Queue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *Queue) GetWaiting() []*Job {
+	if m != nil {
+		return m.Waiting
+	}
+	return nil
+}
+
+func (m *Queue) GetInFlight() []*Job {
+	if m != nil {
+		return m.InFlight
+	}
+	return nil
+}
+
+func (m *Queue) GetDead() []*Job {
+	if m != nil {
+		return m.Dead
+	}
+	return nil
+}
+
+func (m *Queue) GetLastId() uint64 {
+	if m != nil {
+		return m.LastId
+	}
+	return 0
+}
+
+type JobList struct {
+	Jobs []*Job `protobuf:"bytes,1,rep,name=jobs" json:"jobs,omitempty"`
+}
+
+func (m *JobList) Reset()                    { *m = JobList{} }
+func (m *JobList) String() string            { return proto.CompactTextString(m) }
+func (*JobList) ProtoMessage()               {}
+func (*JobList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *JobList) GetJobs() []*Job {
+	if m != nil {
+		return m.Jobs
+	}
+	return nil
+}
+
+type QueueStats struct {
+	// ready jobs can be dequeued right now
+	Ready int64 `protobuf:"varint,1,opt,name=ready" json:"ready,omitempty"`
+	// delayed jobs are waiting for their delay
+	Delayed  int64 `protobuf:"varint,2,opt,name=delayed" json:"delayed,omitempty"`
+	InFlight int64 `protobuf:"varint,3,opt,name=in_flight,json=inFlight" json:"in_flight,omitempty"`
+	Dead     int64 `protobuf:"varint,4,opt,name=dead" json:"dead,omitempty"`
+}
+
+func (m *QueueStats) Reset()                    { *m = QueueStats{} }
+func (m *QueueStats) String() string            { return proto.CompactTextString(m) }
+func (*QueueStats) ProtoMessage()               {}
+func (*QueueStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *QueueStats) GetReady() int64 {
+	if m != nil {
+		return m.Ready
+	}
+	return 0
+}
+
+func (m *QueueStats) GetDelayed() int64 {
+	if m != nil {
+		return m.Delayed
+	}
+	return 0
+}
+
+func (m *QueueStats) GetInFlight() int64 {
+	if m != nil {
+		return m.InFlight
+	}
+	return 0
+}
+
+func (m *QueueStats) GetDead() int64 {
+	if m != nil {
+		return m.Dead
+	}
+	return 0
+}
+
+type Lock struct {
+	// owner is empty if lock is free
+	Owner string `protobuf:"bytes,1,opt,name=owner" json:"owner,omitempty"`
+	// token is increased on every acquire, it's used as fencing token
+	Token uint64 `protobuf:"varint,2,opt,name=token" json:"token,omitempty"`
+	// unix nanoseconds when lease expires
+	ExpiresAt int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+}
+
+func (m *Lock) Reset()                    { *m = Lock{} }
+func (m *Lock) String() string            { return proto.CompactTextString(m) }
+func (*Lock) ProtoMessage()               {}
+func (*Lock) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *Lock) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Lock) GetToken() uint64 {
+	if m != nil {
+		return m.Token
+	}
+	return 0
+}
+
+func (m *Lock) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+type RateLimitResult struct {
+	Allowed   bool  `protobuf:"varint,1,opt,name=allowed" json:"allowed,omitempty"`
+	Remaining int64 `protobuf:"varint,2,opt,name=remaining" json:"remaining,omitempty"`
+	// retry_after is set for denied requests, in nanoseconds request with same cost would be allowed after
+	RetryAfter int64 `protobuf:"varint,3,opt,name=retry_after,json=retryAfter" json:"retry_after,omitempty"`
+}
+
+func (m *RateLimitResult) Reset()                    { *m = RateLimitResult{} }
+func (m *RateLimitResult) String() string            { return proto.CompactTextString(m) }
+func (*RateLimitResult) ProtoMessage()               {}
+func (*RateLimitResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *RateLimitResult) GetAllowed() bool {
+	if m != nil {
+		return m.Allowed
+	}
+	return false
+}
+
+func (m *RateLimitResult) GetRemaining() int64 {
+	if m != nil {
+		return m.Remaining
+	}
+	return 0
+}
+
+func (m *RateLimitResult) GetRetryAfter() int64 {
+	if m != nil {
+		return m.RetryAfter
+	}
+	return 0
+}
+
+type RateLimitLogEntry struct {
+	// unix nanoseconds
+	Time int64  `protobuf:"varint,1,opt,name=time" json:"time,omitempty"`
+	Cost uint32 `protobuf:"varint,2,opt,name=cost" json:"cost,omitempty"`
+}
+
+func (m *RateLimitLogEntry) Reset()                    { *m = RateLimitLogEntry{} }
+func (m *RateLimitLogEntry) String() string            { return proto.CompactTextString(m) }
+func (*RateLimitLogEntry) ProtoMessage()               {}
+func (*RateLimitLogEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *RateLimitLogEntry) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *RateLimitLogEntry) GetCost() uint32 {
+	if m != nil {
+		return m.Cost
+	}
+	return 0
+}
+
+type RateLimiter struct {
+	Algorithm RateLimitAlgorithm `protobuf:"varint,1,opt,name=algorithm,enum=godis_proto.RateLimitAlgorithm" json:"algorithm,omitempty"`
+	// tokens and updated_at (unix nanoseconds) are used by token bucket
+	Tokens    float64 `protobuf:"fixed64,2,opt,name=tokens" json:"tokens,omitempty"`
+	UpdatedAt int64   `protobuf:"varint,3,opt,name=updated_at,json=updatedAt" json:"updated_at,omitempty"`
+	// log is used by sliding window log, ordered by time
+	Log []*RateLimitLogEntry `protobuf:"bytes,4,rep,name=log" json:"log,omitempty"`
+}
+
+func (m *RateLimiter) Reset()                    { *m = RateLimiter{} }
+func (m *RateLimiter) String() string            { return proto.CompactTextString(m) }
+func (*RateLimiter) ProtoMessage()               {}
+func (*RateLimiter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *RateLimiter) GetAlgorithm() RateLimitAlgorithm {
+	if m != nil {
+		return m.Algorithm
+	}
+	return RateLimitAlgorithm_TokenBucket
+}
+
+func (m *RateLimiter) GetTokens() float64 {
+	if m != nil {
+		return m.Tokens
+	}
+	return 0
+}
+
+func (m *RateLimiter) GetUpdatedAt() int64 {
+	if m != nil {
+		return m.UpdatedAt
+	}
+	return 0
+}
+
+func (m *RateLimiter) GetLog() []*RateLimitLogEntry {
+	if m != nil {
+		return m.Log
+	}
+	return nil
+}
+
+type DatabaseStats struct {
+	Index uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	Keys  int64  `protobuf:"varint,2,opt,name=keys" json:"keys,omitempty"`
+	// bytes - total size of keys and encoded values
+	Bytes int64 `protobuf:"varint,3,opt,name=bytes" json:"bytes,omitempty"`
+	// max_keys and max_bytes are quota limits, zero means no limit
+	MaxKeys  int64 `protobuf:"varint,4,opt,name=max_keys,json=maxKeys" json:"max_keys,omitempty"`
+	MaxBytes int64 `protobuf:"varint,5,opt,name=max_bytes,json=maxBytes" json:"max_bytes,omitempty"`
+	// rejected - amount of requests rejected by database quota
+	Rejected int64 `protobuf:"varint,6,opt,name=rejected" json:"rejected,omitempty"`
+}
+
+func (m *DatabaseStats) Reset()                    { *m = DatabaseStats{} }
+func (m *DatabaseStats) String() string            { return proto.CompactTextString(m) }
+func (*DatabaseStats) ProtoMessage()               {}
+func (*DatabaseStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *DatabaseStats) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *DatabaseStats) GetKeys() int64 {
+	if m != nil {
+		return m.Keys
+	}
+	return 0
+}
+
+func (m *DatabaseStats) GetBytes() int64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
+func (m *DatabaseStats) GetMaxKeys() int64 {
+	if m != nil {
+		return m.MaxKeys
+	}
+	return 0
+}
+
+func (m *DatabaseStats) GetMaxBytes() int64 {
+	if m != nil {
+		return m.MaxBytes
+	}
+	return 0
+}
+
+func (m *DatabaseStats) GetRejected() int64 {
+	if m != nil {
+		return m.Rejected
+	}
+	return 0
+}
+
+type ClientStats struct {
+	// address - remote host of client connections
+	Address     string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Connections int64  `protobuf:"varint,2,opt,name=connections" json:"connections,omitempty"`
+	// rejected - amount of requests rejected by client quota
+	Rejected int64 `protobuf:"varint,3,opt,name=rejected" json:"rejected,omitempty"`
+}
+
+func (m *ClientStats) Reset()                    { *m = ClientStats{} }
+func (m *ClientStats) String() string            { return proto.CompactTextString(m) }
+func (*ClientStats) ProtoMessage()               {}
+func (*ClientStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *ClientStats) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *ClientStats) GetConnections() int64 {
+	if m != nil {
+		return m.Connections
+	}
+	return 0
+}
+
+func (m *ClientStats) GetRejected() int64 {
+	if m != nil {
+		return m.Rejected
+	}
+	return 0
+}
+
+type ServerStats struct {
+	Databases []*DatabaseStats `protobuf:"bytes,1,rep,name=databases" json:"databases,omitempty"`
+	// clients contains only clients limited by quota
+	Clients     []*ClientStats   `protobuf:"bytes,2,rep,name=clients" json:"clients,omitempty"`
+	Connections *ConnectionStats `protobuf:"bytes,3,opt,name=connections" json:"connections,omitempty"`
+	// last_snapshot is set if snapshot was written or loaded since start
+	LastSnapshot *SnapshotInfo     `protobuf:"bytes,4,opt,name=last_snapshot,json=lastSnapshot" json:"last_snapshot,omitempty"`
+	Replication  *ReplicationStats `protobuf:"bytes,5,opt,name=replication" json:"replication,omitempty"`
+}
+
+func (m *ServerStats) Reset()                    { *m = ServerStats{} }
+func (m *ServerStats) String() string            { return proto.CompactTextString(m) }
+func (*ServerStats) ProtoMessage()               {}
+func (*ServerStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *ServerStats) GetDatabases() []*DatabaseStats {
+	if m != nil {
+		return m.Databases
+	}
+	return nil
+}
+
+func (m *ServerStats) GetClients() []*ClientStats {
+	if m != nil {
+		return m.Clients
+	}
+	return nil
+}
+
+func (m *ServerStats) GetConnections() *ConnectionStats {
+	if m != nil {
+		return m.Connections
+	}
+	return nil
+}
+
+func (m *ServerStats) GetLastSnapshot() *SnapshotInfo {
+	if m != nil {
+		return m.LastSnapshot
+	}
+	return nil
+}
+
+func (m *ServerStats) GetReplication() *ReplicationStats {
+	if m != nil {
+		return m.Replication
+	}
+	return nil
+}
+
+// ReplicationEvent is record or state of primary streamed to replica
+type ReplicationEvent struct {
+	// record is set for wal record, records of full resync have zero lsn
+	Record *WalRecord `protobuf:"bytes,1,opt,name=record" json:"record,omitempty"`
+	// full_sync is set before full resync, replica deletes its data and applies records of dump which follow
+	FullSync bool `protobuf:"varint,2,opt,name=full_sync,json=fullSync" json:"full_sync,omitempty"`
+	// synced is set after records of full resync dump, replica has data of primary up to lsn then
+	Synced bool `protobuf:"varint,3,opt,name=synced" json:"synced,omitempty"`
+	// lsn - LSN of the last record written by primary
+	Lsn uint64 `protobuf:"varint,4,opt,name=lsn" json:"lsn,omitempty"`
+	// time - unix nanoseconds on primary when event was sent
+	Time int64 `protobuf:"varint,5,opt,name=time" json:"time,omitempty"`
+}
+
+func (m *ReplicationEvent) Reset()                    { *m = ReplicationEvent{} }
+func (m *ReplicationEvent) String() string            { return proto.CompactTextString(m) }
+func (*ReplicationEvent) ProtoMessage()               {}
+func (*ReplicationEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *ReplicationEvent) GetRecord() *WalRecord {
+	if m != nil {
+		return m.Record
+	}
+	return nil
+}
+
+func (m *ReplicationEvent) GetFullSync() bool {
+	if m != nil {
+		return m.FullSync
+	}
+	return false
+}
+
+func (m *ReplicationEvent) GetSynced() bool {
+	if m != nil {
+		return m.Synced
+	}
+	return false
+}
+
+func (m *ReplicationEvent) GetLsn() uint64 {
+	if m != nil {
+		return m.Lsn
+	}
+	return 0
+}
+
+func (m *ReplicationEvent) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+type WalRecord struct {
+	Command  uint32 `protobuf:"varint,1,opt,name=command" json:"command,omitempty"`
+	Database uint32 `protobuf:"varint,2,opt,name=database" json:"database,omitempty"`
+	Key      []byte `protobuf:"bytes,3,opt,name=key" json:"key,omitempty"`
+	Value    []byte `protobuf:"bytes,4,opt,name=value" json:"value,omitempty"`
+	Lsn      uint64 `protobuf:"varint,5,opt,name=lsn" json:"lsn,omitempty"`
+	// time - unix nanoseconds when record was written
+	Time int64 `protobuf:"varint,6,opt,name=time" json:"time,omitempty"`
+}
+
+func (m *WalRecord) Reset()                    { *m = WalRecord{} }
+func (m *WalRecord) String() string            { return proto.CompactTextString(m) }
+func (*WalRecord) ProtoMessage()               {}
+func (*WalRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *WalRecord) GetCommand() uint32 {
+	if m != nil {
+		return m.Command
+	}
+	return 0
+}
+
+func (m *WalRecord) GetDatabase() uint32 {
+	if m != nil {
+		return m.Database
+	}
+	return 0
+}
+
+func (m *WalRecord) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *WalRecord) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *WalRecord) GetLsn() uint64 {
+	if m != nil {
+		return m.Lsn
+	}
+	return 0
+}
+
+func (m *WalRecord) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+type ReplicationStats struct {
+	// primary - address of primary, empty if server isn't replica
+	Primary string `protobuf:"bytes,1,opt,name=primary" json:"primary,omitempty"`
+	// connected is set while replica receives wal stream from primary
+	Connected bool `protobuf:"varint,2,opt,name=connected" json:"connected,omitempty"`
+	// lsn - LSN of the last record applied by replica or written by primary
+	Lsn uint64 `protobuf:"varint,3,opt,name=lsn" json:"lsn,omitempty"`
+	// primary_lsn - LSN of the last record written by primary known to replica
+	PrimaryLsn uint64 `protobuf:"varint,4,opt,name=primary_lsn,json=primaryLsn" json:"primary_lsn,omitempty"`
+	// lag - nanoseconds between write of the last applied record and its apply, zero if replica has all records
+	Lag int64 `protobuf:"varint,5,opt,name=lag" json:"lag,omitempty"`
+	// full_syncs - amount of full resyncs made by replica
+	FullSyncs int64 `protobuf:"varint,6,opt,name=full_syncs,json=fullSyncs" json:"full_syncs,omitempty"`
+	// replicas - amount of replicas connected to primary
+	Replicas int64 `protobuf:"varint,7,opt,name=replicas" json:"replicas,omitempty"`
+}
+
+func (m *ReplicationStats) Reset()                    { *m = ReplicationStats{} }
+func (m *ReplicationStats) String() string            { return proto.CompactTextString(m) }
+func (*ReplicationStats) ProtoMessage()               {}
+func (*ReplicationStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *ReplicationStats) GetPrimary() string {
+	if m != nil {
+		return m.Primary
+	}
+	return ""
+}
+
+func (m *ReplicationStats) GetConnected() bool {
+	if m != nil {
+		return m.Connected
+	}
+	return false
+}
+
+func (m *ReplicationStats) GetLsn() uint64 {
+	if m != nil {
+		return m.Lsn
+	}
+	return 0
+}
+
+func (m *ReplicationStats) GetPrimaryLsn() uint64 {
+	if m != nil {
+		return m.PrimaryLsn
+	}
+	return 0
+}
+
+func (m *ReplicationStats) GetLag() int64 {
+	if m != nil {
+		return m.Lag
+	}
+	return 0
+}
+
+func (m *ReplicationStats) GetFullSyncs() int64 {
+	if m != nil {
+		return m.FullSyncs
+	}
+	return 0
+}
+
+func (m *ReplicationStats) GetReplicas() int64 {
+	if m != nil {
+		return m.Replicas
+	}
+	return 0
+}
+
+type BackupInfo struct {
+	// wal_position - sequence number of the first wal segment which isn't included into backup
+	WalPosition uint64 `protobuf:"varint,1,opt,name=wal_position,json=walPosition" json:"wal_position,omitempty"`
+	Keys        int64  `protobuf:"varint,2,opt,name=keys" json:"keys,omitempty"`
+	// size of backup in bytes
+	Size int64 `protobuf:"varint,3,opt,name=size" json:"size,omitempty"`
+	// sha256 - hex encoded checksum of backup
+	Sha256 string `protobuf:"bytes,4,opt,name=sha256" json:"sha256,omitempty"`
+	// path - path of backup file on server if it's written to file
+	Path string `protobuf:"bytes,5,opt,name=path" json:"path,omitempty"`
+	// lsn - LSN of the last wal record included into backup
+	Lsn uint64 `protobuf:"varint,6,opt,name=lsn" json:"lsn,omitempty"`
+}
+
+func (m *BackupInfo) Reset()                    { *m = BackupInfo{} }
+func (m *BackupInfo) String() string            { return proto.CompactTextString(m) }
+func (*BackupInfo) ProtoMessage()               {}
+func (*BackupInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *BackupInfo) GetWalPosition() uint64 {
+	if m != nil {
+		return m.WalPosition
+	}
+	return 0
+}
+
+func (m *BackupInfo) GetKeys() int64 {
+	if m != nil {
+		return m.Keys
+	}
+	return 0
+}
+
+func (m *BackupInfo) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *BackupInfo) GetSha256() string {
+	if m != nil {
+		return m.Sha256
+	}
+	return ""
+}
+
+func (m *BackupInfo) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *BackupInfo) GetLsn() uint64 {
+	if m != nil {
+		return m.Lsn
+	}
+	return 0
+}
+
+type SnapshotInfo struct {
+	// wal_position - sequence number of the first wal segment which isn't included into snapshot
+	WalPosition uint64 `protobuf:"varint,1,opt,name=wal_position,json=walPosition" json:"wal_position,omitempty"`
+	// keys - amount of keys in snapshot
+	Keys int64 `protobuf:"varint,2,opt,name=keys" json:"keys,omitempty"`
+	// size of snapshot file in bytes
+	Size int64 `protobuf:"varint,3,opt,name=size" json:"size,omitempty"`
+	// created - unix nanoseconds when snapshot was started
+	Created int64 `protobuf:"varint,4,opt,name=created" json:"created,omitempty"`
+	// lsn - LSN of the last wal record included into snapshot
+	Lsn uint64 `protobuf:"varint,5,opt,name=lsn" json:"lsn,omitempty"`
+}
+
+func (m *SnapshotInfo) Reset()                    { *m = SnapshotInfo{} }
+func (m *SnapshotInfo) String() string            { return proto.CompactTextString(m) }
+func (*SnapshotInfo) ProtoMessage()               {}
+func (*SnapshotInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *SnapshotInfo) GetWalPosition() uint64 {
+	if m != nil {
+		return m.WalPosition
+	}
+	return 0
+}
+
+func (m *SnapshotInfo) GetKeys() int64 {
+	if m != nil {
+		return m.Keys
+	}
+	return 0
+}
+
+func (m *SnapshotInfo) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *SnapshotInfo) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *SnapshotInfo) GetLsn() uint64 {
+	if m != nil {
+		return m.Lsn
+	}
+	return 0
+}
+
+type ConnectionStats struct {
+	// active - amount of open connections
+	Active   int64 `protobuf:"varint,1,opt,name=active" json:"active,omitempty"`
+	Accepted int64 `protobuf:"varint,2,opt,name=accepted" json:"accepted,omitempty"`
+	// rejected - connections rejected because of max connections limit
+	Rejected int64 `protobuf:"varint,3,opt,name=rejected" json:"rejected,omitempty"`
+	// rejected_per_host - connections rejected because of max connections per host limit
+	RejectedPerHost int64 `protobuf:"varint,4,opt,name=rejected_per_host,json=rejectedPerHost" json:"rejected_per_host,omitempty"`
+	// idle_closed - connections closed by idle timeout
+	IdleClosed int64 `protobuf:"varint,5,opt,name=idle_closed,json=idleClosed" json:"idle_closed,omitempty"`
+	// read_timeouts and write_timeouts - connections closed because request or response wasn't transferred in time
+	ReadTimeouts  int64 `protobuf:"varint,6,opt,name=read_timeouts,json=readTimeouts" json:"read_timeouts,omitempty"`
+	WriteTimeouts int64 `protobuf:"varint,7,opt,name=write_timeouts,json=writeTimeouts" json:"write_timeouts,omitempty"`
+}
+
+func (m *ConnectionStats) Reset()                    { *m = ConnectionStats{} }
+func (m *ConnectionStats) String() string            { return proto.CompactTextString(m) }
+func (*ConnectionStats) ProtoMessage()               {}
+func (*ConnectionStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+func (m *ConnectionStats) GetActive() int64 {
+	if m != nil {
+		return m.Active
+	}
+	return 0
+}
+
+func (m *ConnectionStats) GetAccepted() int64 {
+	if m != nil {
+		return m.Accepted
+	}
+	return 0
+}
+
+func (m *ConnectionStats) GetRejected() int64 {
+	if m != nil {
+		return m.Rejected
+	}
+	return 0
+}
+
+func (m *ConnectionStats) GetRejectedPerHost() int64 {
+	if m != nil {
+		return m.RejectedPerHost
+	}
+	return 0
+}
+
+func (m *ConnectionStats) GetIdleClosed() int64 {
+	if m != nil {
+		return m.IdleClosed
+	}
+	return 0
+}
+
+func (m *ConnectionStats) GetReadTimeouts() int64 {
+	if m != nil {
+		return m.ReadTimeouts
+	}
+	return 0
+}
+
+func (m *ConnectionStats) GetWriteTimeouts() int64 {
+	if m != nil {
+		return m.WriteTimeouts
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Error)(nil), "godis_proto.Error")
 	proto.RegisterType((*Response)(nil), "godis_proto.Response")
@@ -482,41 +2686,226 @@ func init() {
 	proto.RegisterType((*Value)(nil), "godis_proto.Value")
 	proto.RegisterType((*RepeatedString)(nil), "godis_proto.RepeatedString")
 	proto.RegisterType((*MapString)(nil), "godis_proto.MapString")
+	proto.RegisterType((*KeyValue)(nil), "godis_proto.KeyValue")
+	proto.RegisterType((*KeyValueList)(nil), "godis_proto.KeyValueList")
+	proto.RegisterType((*Message)(nil), "godis_proto.Message")
+	proto.RegisterType((*Database)(nil), "godis_proto.Database")
+	proto.RegisterType((*StreamEntry)(nil), "godis_proto.StreamEntry")
+	proto.RegisterType((*StreamPendingEntry)(nil), "godis_proto.StreamPendingEntry")
+	proto.RegisterType((*StreamConsumerGroup)(nil), "godis_proto.StreamConsumerGroup")
+	proto.RegisterType((*Stream)(nil), "godis_proto.Stream")
+	proto.RegisterType((*StreamRead)(nil), "godis_proto.StreamRead")
+	proto.RegisterType((*StreamReadList)(nil), "godis_proto.StreamReadList")
+	proto.RegisterType((*StreamPendingList)(nil), "godis_proto.StreamPendingList")
+	proto.RegisterType((*Job)(nil), "godis_proto.Job")
+	proto.RegisterType((*Queue)(nil), "godis_proto.Queue")
+	proto.RegisterType((*JobList)(nil), "godis_proto.JobList")
+	proto.RegisterType((*QueueStats)(nil), "godis_proto.QueueStats")
+	proto.RegisterType((*Lock)(nil), "godis_proto.Lock")
+	proto.RegisterType((*RateLimitResult)(nil), "godis_proto.RateLimitResult")
+	proto.RegisterType((*RateLimitLogEntry)(nil), "godis_proto.RateLimitLogEntry")
+	proto.RegisterType((*RateLimiter)(nil), "godis_proto.RateLimiter")
+	proto.RegisterType((*DatabaseStats)(nil), "godis_proto.DatabaseStats")
+	proto.RegisterType((*ClientStats)(nil), "godis_proto.ClientStats")
+	proto.RegisterType((*ServerStats)(nil), "godis_proto.ServerStats")
+	proto.RegisterType((*ReplicationEvent)(nil), "godis_proto.ReplicationEvent")
+	proto.RegisterType((*WalRecord)(nil), "godis_proto.WalRecord")
+	proto.RegisterType((*ReplicationStats)(nil), "godis_proto.ReplicationStats")
+	proto.RegisterType((*BackupInfo)(nil), "godis_proto.BackupInfo")
+	proto.RegisterType((*SnapshotInfo)(nil), "godis_proto.SnapshotInfo")
+	proto.RegisterType((*ConnectionStats)(nil), "godis_proto.ConnectionStats")
+	proto.RegisterEnum("godis_proto.ErrorCode", ErrorCode_name, ErrorCode_value)
 	proto.RegisterEnum("godis_proto.Operation", Operation_name, Operation_value)
+	proto.RegisterEnum("godis_proto.RateLimitAlgorithm", RateLimitAlgorithm_name, RateLimitAlgorithm_value)
+	proto.RegisterEnum("godis_proto.EventType", EventType_name, EventType_value)
 }
 
 func init() { proto.RegisterFile("godis.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 473 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0xdf, 0x8a, 0xd3, 0x40,
-	0x14, 0xc6, 0x3b, 0x4d, 0xd3, 0x36, 0xa7, 0x6b, 0x08, 0x87, 0x45, 0x83, 0x5e, 0x58, 0x03, 0x42,
-	0xd8, 0x8b, 0x82, 0x55, 0x50, 0x16, 0x2f, 0xb4, 0xb8, 0x6c, 0xa5, 0x2c, 0xe2, 0x14, 0x7a, 0x5b,
-	0x46, 0x7b, 0x28, 0x61, 0xf3, 0xcf, 0x99, 0xd9, 0xc5, 0x3c, 0x85, 0x8f, 0x21, 0xf8, 0x10, 0x3e,
-	0x9b, 0xcc, 0x24, 0xa9, 0x0d, 0x54, 0xbc, 0x9b, 0x33, 0xf3, 0x3b, 0xf3, 0x7d, 0xdf, 0x99, 0x81,
-	0xc9, 0xbe, 0xd8, 0x25, 0x6a, 0x56, 0xca, 0x42, 0x17, 0x58, 0x17, 0x5b, 0x5b, 0x44, 0xcf, 0xc0,
-	0xbd, 0x92, 0xb2, 0x90, 0x18, 0xc2, 0x28, 0x23, 0xa5, 0xc4, 0x9e, 0x42, 0x36, 0x65, 0xb1, 0xc7,
-	0xdb, 0x32, 0xfa, 0xc9, 0x60, 0xcc, 0x49, 0x95, 0x45, 0xae, 0x08, 0x2f, 0xc0, 0x25, 0xc3, 0x5b,
-	0x68, 0x32, 0xc7, 0xd9, 0xd1, 0x65, 0x33, 0x7b, 0xd3, 0xb2, 0xc7, 0x6b, 0xc4, 0xb0, 0xf7, 0x22,
-	0xbd, 0xa3, 0xb0, 0x7f, 0x82, 0xdd, 0x98, 0x13, 0xc3, 0x5a, 0x04, 0x5f, 0xc0, 0xe0, 0x96, 0x2a,
-	0x15, 0x3a, 0x16, 0x7d, 0xd2, 0x41, 0x39, 0x95, 0x24, 0x34, 0xed, 0xd6, 0x5a, 0x26, 0xf9, 0x7e,
-	0xd9, 0xe3, 0x16, 0x5d, 0x04, 0xe0, 0xcb, 0xc6, 0xd6, 0xd6, 0x5e, 0x12, 0xfd, 0x62, 0x30, 0xe2,
-	0xf4, 0xed, 0x8e, 0x94, 0xc6, 0x00, 0x9c, 0x5b, 0xaa, 0x9a, 0x2c, 0x66, 0x89, 0xaf, 0xc0, 0x2b,
-	0x4a, 0x92, 0x42, 0x27, 0x45, 0x6e, 0x2d, 0xf9, 0xf3, 0x87, 0x1d, 0x9d, 0x4f, 0xed, 0x29, 0xff,
-	0x0b, 0x62, 0xdc, 0x86, 0x70, 0xfe, 0x15, 0xa2, 0x8d, 0x70, 0x0e, 0x6e, 0x92, 0xef, 0xe8, 0x7b,
-	0x38, 0x98, 0xb2, 0xf8, 0x01, 0xaf, 0x0b, 0x7c, 0x04, 0xa3, 0x4c, 0x94, 0x5b, 0xe3, 0xc5, 0xb5,
-	0x5e, 0x86, 0x99, 0x28, 0x57, 0x54, 0x45, 0xbf, 0x19, 0xb8, 0xb6, 0x1f, 0x9f, 0x02, 0x28, 0x1b,
-	0xcd, 0xc4, 0xa8, 0x1d, 0x2f, 0x7b, 0xdc, 0xab, 0xf7, 0x36, 0x22, 0xc5, 0x77, 0x70, 0xd6, 0x00,
-	0x2a, 0x4d, 0xbe, 0xb6, 0xf3, 0xfc, 0xcf, 0x90, 0x26, 0x75, 0xcb, 0xda, 0x74, 0xe0, 0xeb, 0x83,
-	0x44, 0x26, 0xca, 0x26, 0x4a, 0x37, 0xfc, 0x8d, 0x28, 0x0f, 0xad, 0x8d, 0xf4, 0x8d, 0x28, 0xcd,
-	0x18, 0xb5, 0x4e, 0x6d, 0x24, 0x87, 0x9b, 0xe5, 0x62, 0xd4, 0x0c, 0x24, 0xba, 0x04, 0xbf, 0x2b,
-	0x8a, 0x31, 0x04, 0x8d, 0x8a, 0x90, 0x52, 0x54, 0x36, 0x4e, 0x7f, 0xea, 0xc4, 0x1e, 0xf7, 0xeb,
-	0xfd, 0xf7, 0x66, 0x7b, 0x23, 0xd2, 0xe8, 0x07, 0x03, 0xef, 0xa0, 0x88, 0x1f, 0x3a, 0xee, 0xd8,
-	0xd4, 0x89, 0x27, 0xf3, 0xe7, 0xa7, 0xdd, 0xcd, 0xd6, 0xad, 0xb5, 0xab, 0x5c, 0xcb, 0xea, 0xc8,
-	0xea, 0xe3, 0xb7, 0xe0, 0x77, 0x0f, 0x4f, 0xfc, 0x81, 0xf3, 0xe3, 0x2f, 0xe9, 0x35, 0x2f, 0x77,
-	0xd9, 0x7f, 0xc3, 0x2e, 0x3e, 0x83, 0x77, 0x78, 0x7f, 0x04, 0x18, 0x72, 0xca, 0x8a, 0x7b, 0x0a,
-	0x7a, 0x38, 0x02, 0xe7, 0x9a, 0x74, 0xc0, 0xcc, 0x62, 0x4d, 0x3a, 0xe8, 0xe3, 0x18, 0x06, 0x2b,
-	0xaa, 0x54, 0xe0, 0xa0, 0x0f, 0x70, 0x4d, 0x7a, 0x51, 0x7d, 0x34, 0x4f, 0x1d, 0x0c, 0xf0, 0x0c,
-	0xc6, 0xb6, 0x5e, 0x51, 0x15, 0xb8, 0x5f, 0x86, 0xd6, 0xfb, 0xcb, 0x3f, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x31, 0x96, 0x2b, 0x92, 0x7e, 0x03, 0x00, 0x00,
+	// 2931 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x39, 0x49, 0x77, 0xdc, 0xc6,
+	0xd1, 0xc4, 0xec, 0x53, 0x33, 0x24, 0x9b, 0xad, 0x0d, 0xda, 0x4c, 0x1a, 0xde, 0xf8, 0xd1, 0x16,
+	0x6d, 0xd1, 0xfe, 0x3e, 0xdb, 0xf2, 0xf6, 0x71, 0x93, 0x29, 0x8b, 0xb2, 0x49, 0x8c, 0x6c, 0xeb,
+	0x36, 0xaf, 0x09, 0xb4, 0x48, 0x98, 0x18, 0x00, 0x02, 0x30, 0x24, 0xc7, 0xb7, 0x1c, 0x72, 0xcb,
+	0x7b, 0xc9, 0x25, 0xb9, 0xe5, 0x90, 0x97, 0x7b, 0x2e, 0x79, 0xb9, 0x25, 0x3f, 0x20, 0xf9, 0x2b,
+	0xf9, 0x07, 0x39, 0xe5, 0x55, 0x75, 0x37, 0x30, 0x43, 0x0e, 0xad, 0x24, 0x2f, 0xb7, 0xae, 0x0d,
+	0x5d, 0x5d, 0x5d, 0x6b, 0x03, 0x3a, 0x87, 0xb1, 0x1f, 0x64, 0xab, 0x49, 0x1a, 0xe7, 0x31, 0x57,
+	0x40, 0x9f, 0x00, 0xe7, 0x09, 0xd4, 0xb7, 0xd3, 0x34, 0x4e, 0xb9, 0x0d, 0xcd, 0x81, 0xcc, 0x32,
+	0x71, 0x28, 0x6d, 0x6b, 0xc9, 0x5a, 0x6e, 0xbb, 0x06, 0xe4, 0x2b, 0x50, 0xf3, 0x62, 0x5f, 0xda,
+	0x95, 0x25, 0x6b, 0x79, 0x6e, 0xed, 0xfa, 0xea, 0x98, 0xf8, 0x2a, 0xc9, 0x6e, 0xc6, 0xbe, 0x74,
+	0x89, 0xc7, 0xf9, 0x7b, 0x03, 0x5a, 0xae, 0xcc, 0x92, 0x38, 0xca, 0x50, 0xb0, 0x2e, 0x91, 0x4e,
+	0x1f, 0xec, 0xac, 0xf1, 0x8b, 0x92, 0x3b, 0x33, 0xae, 0x62, 0x41, 0xde, 0x13, 0x11, 0x0e, 0xd5,
+	0x2e, 0xe7, 0x79, 0xbf, 0x43, 0x0a, 0xf2, 0x12, 0x0b, 0xbf, 0x0f, 0xb5, 0x63, 0x39, 0xca, 0xec,
+	0x2a, 0xb1, 0xde, 0x9e, 0x60, 0x75, 0x65, 0x22, 0x45, 0x2e, 0xfd, 0x5e, 0x9e, 0x06, 0xd1, 0xe1,
+	0xce, 0x8c, 0x4b, 0xac, 0xfc, 0x01, 0xc0, 0xb1, 0x1c, 0xf5, 0x49, 0x3e, 0xb3, 0x6b, 0x24, 0x78,
+	0x73, 0x42, 0xf0, 0xb1, 0x1c, 0xd1, 0x36, 0xbb, 0x41, 0x96, 0xef, 0xcc, 0xb8, 0xed, 0x63, 0x0d,
+	0x67, 0xfc, 0xbd, 0xd2, 0x32, 0x75, 0x12, 0xbc, 0x3a, 0x21, 0xf8, 0x44, 0xd1, 0x76, 0x66, 0x4a,
+	0x8b, 0x5d, 0x87, 0xba, 0x17, 0x0f, 0xa3, 0xdc, 0x6e, 0x2c, 0x59, 0xcb, 0x55, 0x54, 0x9c, 0x40,
+	0xfe, 0x21, 0x34, 0xb3, 0x3c, 0x95, 0x62, 0x90, 0xd9, 0xcd, 0x29, 0xba, 0xf7, 0x88, 0xe6, 0x4a,
+	0xe1, 0x6b, 0x25, 0x0c, 0x37, 0x7f, 0x00, 0xcd, 0x44, 0x46, 0x7e, 0x10, 0x1d, 0xda, 0x2d, 0x12,
+	0x7c, 0x65, 0x8a, 0xe0, 0x9e, 0xe2, 0x30, 0xb2, 0x5a, 0x00, 0xaf, 0xef, 0x87, 0xf8, 0x20, 0xb3,
+	0xdb, 0x53, 0x74, 0xff, 0x2a, 0x3e, 0xd0, 0xec, 0xc4, 0xc3, 0x1f, 0x40, 0xe7, 0xc5, 0x50, 0x0e,
+	0x65, 0x3f, 0xcb, 0x45, 0x9e, 0xd9, 0x40, 0x22, 0x37, 0x26, 0x44, 0xf6, 0x91, 0xde, 0x43, 0xf2,
+	0xce, 0x8c, 0x0b, 0x2f, 0x0a, 0x88, 0xbf, 0x05, 0xb5, 0x30, 0xf6, 0x8e, 0xed, 0x0e, 0x09, 0x2d,
+	0x4c, 0x08, 0xed, 0xc6, 0xde, 0x31, 0x6e, 0x82, 0x0c, 0xfc, 0x33, 0x80, 0x54, 0xe4, 0xb2, 0x1f,
+	0x06, 0x83, 0x20, 0xb7, 0xbb, 0xc4, 0x7e, 0x67, 0xf2, 0x12, 0x45, 0x2e, 0x77, 0x91, 0xea, 0xca,
+	0x6c, 0x18, 0xd2, 0x75, 0xa4, 0x06, 0xc5, 0xdf, 0x83, 0xba, 0xd2, 0x6e, 0x96, 0x24, 0xed, 0x49,
+	0x4b, 0xc8, 0xf4, 0x44, 0xa6, 0x46, 0x3d, 0xc5, 0xc8, 0x3f, 0x84, 0x56, 0x16, 0x89, 0x24, 0x3b,
+	0x8a, 0x73, 0x7b, 0x6e, 0xca, 0xd5, 0xf7, 0x34, 0xf1, 0x51, 0xf4, 0x3c, 0xde, 0x99, 0x71, 0x0b,
+	0x66, 0xba, 0xc7, 0xa3, 0x61, 0x74, 0x6c, 0xcf, 0x2f, 0x59, 0xcb, 0x5d, 0xba, 0x47, 0x04, 0xf9,
+	0x7d, 0x68, 0x1c, 0x08, 0xef, 0x78, 0x98, 0xd8, 0x6c, 0x8a, 0x85, 0x36, 0x88, 0xa4, 0x3f, 0xa6,
+	0x19, 0xf9, 0x3a, 0x74, 0x52, 0x99, 0x84, 0x81, 0x27, 0xf2, 0x20, 0x8e, 0xec, 0x05, 0x92, 0xbb,
+	0x7b, 0xde, 0x75, 0x0d, 0x7d, 0xfb, 0x44, 0x46, 0x78, 0xec, 0x71, 0x99, 0x0d, 0x06, 0x73, 0xa9,
+	0x0e, 0x2d, 0xe5, 0xc8, 0xce, 0x5f, 0x9b, 0xd0, 0x74, 0xe5, 0x8b, 0xa1, 0xcc, 0x72, 0xce, 0xa0,
+	0x7a, 0x2c, 0x47, 0x3a, 0x76, 0x71, 0xc9, 0x3f, 0x80, 0x76, 0x9c, 0xc8, 0x54, 0x6d, 0x38, 0x2d,
+	0x78, 0xbf, 0x31, 0x54, 0xb7, 0x64, 0xe4, 0xcb, 0x26, 0x10, 0xab, 0x97, 0x05, 0xa2, 0x09, 0xc3,
+	0xab, 0x50, 0x0f, 0x22, 0x5f, 0x9e, 0x51, 0x38, 0xcd, 0xba, 0x0a, 0xe0, 0x37, 0xa0, 0x39, 0x10,
+	0x49, 0x1f, 0x75, 0xa9, 0x93, 0x2e, 0x8d, 0x81, 0x48, 0x1e, 0xcb, 0x11, 0x12, 0x64, 0xe4, 0x13,
+	0xa1, 0xa1, 0x08, 0x32, 0xf2, 0x91, 0x70, 0x15, 0xea, 0xca, 0x15, 0x9a, 0xea, 0x3b, 0x04, 0x60,
+	0x3e, 0x4a, 0xe5, 0x89, 0x4c, 0x33, 0x49, 0x2e, 0xdf, 0x72, 0x0d, 0xc8, 0xef, 0x02, 0x24, 0xe2,
+	0x50, 0xf6, 0xf3, 0xf8, 0x58, 0x46, 0xe4, 0xd6, 0x6d, 0xb7, 0x8d, 0x98, 0xa7, 0x88, 0xe0, 0xb7,
+	0xa0, 0xe5, 0x1d, 0x89, 0x28, 0x92, 0x21, 0x3a, 0x70, 0x75, 0xb9, 0xed, 0x16, 0x30, 0x7e, 0x34,
+	0x11, 0xa3, 0x30, 0x16, 0x3e, 0xb9, 0x69, 0xd7, 0x35, 0x20, 0x5f, 0x85, 0x86, 0x44, 0xa3, 0x67,
+	0x76, 0x77, 0xa9, 0x7a, 0x31, 0xcd, 0x21, 0xe9, 0xe9, 0x28, 0x91, 0xae, 0xe6, 0xe2, 0x5c, 0xe7,
+	0xa0, 0x59, 0xda, 0x81, 0xd6, 0xf8, 0xf5, 0x3c, 0x18, 0xc8, 0x78, 0xa8, 0xdc, 0xac, 0xea, 0x1a,
+	0x10, 0x2f, 0x27, 0xf0, 0x33, 0x7b, 0x9e, 0x98, 0x71, 0xa9, 0xcc, 0x74, 0xd6, 0x0f, 0x65, 0x44,
+	0x3e, 0x34, 0x8b, 0x66, 0x3a, 0xdb, 0x95, 0x11, 0x5a, 0xe3, 0x80, 0xe2, 0x68, 0x81, 0x4e, 0xad,
+	0x00, 0xc4, 0x1e, 0xa6, 0xf1, 0x30, 0xb1, 0x39, 0x1d, 0x57, 0x01, 0x74, 0xd4, 0x38, 0xca, 0x86,
+	0x03, 0x99, 0xda, 0x57, 0x88, 0x50, 0xc0, 0x28, 0xe1, 0xcb, 0x50, 0x8c, 0xec, 0xab, 0xa4, 0x8a,
+	0x02, 0xf8, 0x3d, 0xe0, 0x27, 0x41, 0x16, 0x1c, 0x04, 0x61, 0x90, 0x8f, 0xfa, 0x46, 0xdb, 0x6b,
+	0xc4, 0xb2, 0x50, 0x52, 0x9e, 0x6a, 0xbd, 0x5f, 0x85, 0x2e, 0x6a, 0x29, 0xf2, 0x5c, 0x0e, 0x92,
+	0x3c, 0xb3, 0xaf, 0x93, 0xaa, 0x9d, 0x81, 0x38, 0x5b, 0xd7, 0x28, 0xdc, 0x27, 0x3e, 0x8d, 0x64,
+	0x6a, 0xdf, 0x50, 0x9a, 0x11, 0x40, 0x77, 0x2a, 0x45, 0x26, 0x6d, 0x5b, 0xed, 0x4e, 0x00, 0xbf,
+	0x0e, 0x8d, 0xd3, 0x20, 0xf2, 0xe3, 0x53, 0xfb, 0x26, 0xa1, 0x35, 0x84, 0xc6, 0xf4, 0xe2, 0x2c,
+	0xb7, 0x6f, 0xd1, 0xe7, 0x69, 0xcd, 0x3f, 0x83, 0xb6, 0x08, 0x0f, 0xe3, 0x34, 0xc8, 0x8f, 0x06,
+	0xf6, 0x6d, 0xf2, 0xde, 0xc5, 0xe9, 0x49, 0x62, 0xdd, 0xb0, 0xb9, 0xa5, 0x04, 0x6e, 0x95, 0x79,
+	0x69, 0x90, 0xe4, 0xf6, 0x1d, 0xe5, 0x6c, 0x0a, 0xc2, 0x9b, 0xc8, 0x8e, 0x84, 0x7d, 0x57, 0x85,
+	0x49, 0x76, 0x24, 0x70, 0x73, 0x91, 0x1e, 0x66, 0xf6, 0x2b, 0xea, 0x26, 0x71, 0xcd, 0xef, 0x43,
+	0xcb, 0x17, 0xb9, 0x38, 0xc0, 0x13, 0x2c, 0x52, 0x1c, 0x5c, 0x9b, 0xd8, 0x7b, 0x4b, 0x13, 0xdd,
+	0x82, 0x0d, 0x2f, 0xdf, 0x1f, 0xa6, 0xe2, 0x20, 0x94, 0xf6, 0x92, 0xf2, 0x57, 0x0d, 0xe2, 0x06,
+	0x89, 0xc8, 0x8f, 0xec, 0x57, 0x69, 0x4f, 0x5a, 0xa3, 0x1a, 0x61, 0x16, 0xd9, 0xce, 0x92, 0xb5,
+	0x5c, 0x73, 0x71, 0xe9, 0xfc, 0xac, 0x0a, 0x75, 0x0a, 0x2f, 0xbe, 0x08, 0x90, 0x51, 0xf5, 0xc2,
+	0x28, 0x57, 0x01, 0x8d, 0x19, 0x50, 0xe1, 0xbe, 0x13, 0x21, 0xff, 0x7f, 0xe8, 0x6a, 0x86, 0x2c,
+	0x0c, 0x3c, 0x53, 0x32, 0x5f, 0x52, 0x07, 0x3b, 0x4a, 0xa4, 0x87, 0x12, 0xfc, 0xc3, 0x62, 0x8b,
+	0x81, 0x48, 0x74, 0xa4, 0x4f, 0x7a, 0xfc, 0x13, 0x91, 0x14, 0xa2, 0x7a, 0xeb, 0x27, 0x22, 0xe1,
+	0xf7, 0xa0, 0xa1, 0x6a, 0x92, 0x2e, 0x85, 0x57, 0xa6, 0xd4, 0x21, 0xcc, 0x7a, 0x8a, 0x09, 0xab,
+	0x3a, 0x55, 0x08, 0xbb, 0x31, 0x25, 0x99, 0x50, 0x25, 0xc1, 0xa4, 0x4a, 0x2c, 0x45, 0xfd, 0x68,
+	0xbe, 0xbc, 0x7e, 0x74, 0xcb, 0xfa, 0x21, 0x53, 0xbb, 0x35, 0xa5, 0x0e, 0x14, 0xce, 0x21, 0x53,
+	0x4a, 0xa3, 0x25, 0x88, 0xa6, 0xcf, 0xf3, 0x90, 0x92, 0x56, 0xd5, 0xc5, 0xe5, 0x46, 0x53, 0xa7,
+	0x3c, 0xe7, 0x01, 0xcc, 0x4d, 0xda, 0x8d, 0x2f, 0x03, 0xd3, 0x86, 0x12, 0x69, 0x2a, 0xa8, 0x81,
+	0xb0, 0x2b, 0xe4, 0x28, 0x73, 0x0a, 0xbf, 0x8e, 0xe8, 0xef, 0x44, 0xe8, 0xfc, 0xd2, 0x82, 0x76,
+	0x61, 0x34, 0xbe, 0x35, 0x61, 0x60, 0x6b, 0xa9, 0xba, 0xdc, 0x59, 0x7b, 0x63, 0xba, 0x81, 0x57,
+	0x7b, 0xc6, 0xba, 0xdb, 0x51, 0x9e, 0x8e, 0xc6, 0xac, 0x7d, 0xeb, 0x53, 0x98, 0x9b, 0x24, 0x4e,
+	0xc9, 0xf2, 0x57, 0xc7, 0x1b, 0xa7, 0xb6, 0xce, 0xcd, 0x0f, 0x2a, 0x1f, 0x59, 0xce, 0x43, 0x68,
+	0x99, 0xa6, 0x66, 0x8a, 0xdc, 0xf2, 0x4b, 0x1b, 0x2e, 0xfd, 0x2d, 0xc7, 0x83, 0xee, 0x78, 0x73,
+	0xc4, 0xdf, 0x86, 0x7a, 0x90, 0xcb, 0x41, 0xa6, 0x8f, 0x75, 0x6d, 0x6a, 0x1b, 0xe5, 0x2a, 0x1e,
+	0xfe, 0x26, 0xcc, 0x47, 0xf2, 0x2c, 0xef, 0x8f, 0x65, 0x6c, 0xa5, 0xe8, 0x2c, 0xa2, 0xf7, 0x4c,
+	0xd6, 0x76, 0xfe, 0x68, 0x41, 0x53, 0x77, 0x52, 0x18, 0x4a, 0x3a, 0x63, 0x9b, 0x56, 0x54, 0x83,
+	0x2a, 0x7f, 0xe7, 0xb9, 0x4c, 0xcd, 0x57, 0x0c, 0x38, 0x9e, 0xd9, 0xab, 0x93, 0x99, 0x5d, 0x1f,
+	0xbd, 0x56, 0x1e, 0xfd, 0x1d, 0xa8, 0x53, 0x16, 0x27, 0x1f, 0xbe, 0x3c, 0xd5, 0x2b, 0x26, 0x4c,
+	0xb2, 0x45, 0x2e, 0x68, 0x50, 0x82, 0x2a, 0x60, 0x67, 0x09, 0x5a, 0x26, 0x15, 0x94, 0xe5, 0xd0,
+	0x1a, 0x2b, 0x87, 0xce, 0x6f, 0x2c, 0xe8, 0xa8, 0xb0, 0x50, 0x17, 0x38, 0x07, 0x95, 0xc0, 0xd7,
+	0xc7, 0xaa, 0x04, 0x3e, 0xff, 0x14, 0x1a, 0xcf, 0x03, 0x19, 0xfa, 0x19, 0xb9, 0x55, 0x67, 0xed,
+	0xf5, 0x29, 0x01, 0x45, 0x92, 0xab, 0x0f, 0x89, 0x8d, 0xd6, 0xae, 0x96, 0xb9, 0xf5, 0x31, 0x74,
+	0xc6, 0xd0, 0xff, 0x96, 0x77, 0xfc, 0xc2, 0x02, 0x3e, 0xd1, 0x37, 0x4e, 0xd7, 0x6f, 0xbc, 0xc4,
+	0x54, 0xce, 0x95, 0x98, 0xd7, 0x60, 0xd6, 0x97, 0x61, 0x70, 0x22, 0x53, 0x55, 0x4a, 0xc8, 0xf2,
+	0x55, 0xb7, 0x6b, 0x90, 0x58, 0x45, 0xf8, 0x1b, 0x30, 0x57, 0x30, 0xa9, 0xa6, 0x58, 0x45, 0x5e,
+	0x21, 0xba, 0x89, 0x48, 0xe7, 0x57, 0x16, 0x5c, 0x51, 0xea, 0x6c, 0xea, 0xcf, 0x7f, 0x49, 0x25,
+	0x8e, 0x43, 0x2d, 0x12, 0x03, 0x33, 0x93, 0xd0, 0x9a, 0xaf, 0xc0, 0x42, 0x28, 0xb2, 0xbc, 0xaf,
+	0xbf, 0x20, 0xfd, 0x7e, 0xe0, 0x6b, 0xe5, 0xe6, 0x91, 0xb0, 0x65, 0xf0, 0x8f, 0x7c, 0xfe, 0x71,
+	0xd9, 0x39, 0x57, 0xc9, 0xc0, 0x8b, 0x97, 0x77, 0xce, 0xca, 0xb6, 0x86, 0x1f, 0x23, 0xba, 0xa1,
+	0xe8, 0x7c, 0x0d, 0x7b, 0x97, 0x3c, 0x0d, 0xa4, 0x71, 0x7a, 0xfb, 0xb2, 0x6b, 0x72, 0x0d, 0x23,
+	0x56, 0x78, 0xd2, 0xb2, 0xd0, 0xad, 0x81, 0xe0, 0x23, 0x9f, 0x7f, 0x04, 0x0d, 0x2a, 0xdf, 0x99,
+	0xd6, 0x68, 0x69, 0xca, 0xb7, 0x26, 0x8c, 0xe0, 0x6a, 0x7e, 0xc7, 0x05, 0x28, 0x67, 0x84, 0x29,
+	0xb7, 0x3d, 0xa6, 0x66, 0xe5, 0x5f, 0x54, 0xd3, 0xd9, 0x84, 0xb9, 0xf2, 0x9b, 0x14, 0xdf, 0xf7,
+	0xcb, 0x29, 0x45, 0x1d, 0xf6, 0xc6, 0x25, 0x53, 0x4a, 0x31, 0x9f, 0x38, 0x5f, 0xc3, 0xc2, 0x85,
+	0x19, 0x04, 0x4d, 0x3f, 0x69, 0xb4, 0x97, 0x9b, 0xde, 0x28, 0xf5, 0x07, 0x0b, 0xaa, 0x5f, 0xc5,
+	0x07, 0x17, 0xbc, 0x71, 0x2c, 0xca, 0x2b, 0x93, 0x51, 0x7e, 0x17, 0x80, 0xda, 0x97, 0x50, 0xf6,
+	0x45, 0xae, 0x1d, 0xb1, 0xad, 0x31, 0xeb, 0x14, 0xc4, 0x45, 0x13, 0xa3, 0xda, 0xd5, 0x02, 0xbe,
+	0xd0, 0xe4, 0xd4, 0x2f, 0x36, 0x39, 0x8b, 0xd0, 0x91, 0x11, 0x95, 0x29, 0x1f, 0x3f, 0x4f, 0x63,
+	0x9d, 0x0b, 0x06, 0xb5, 0x9e, 0x3b, 0xbf, 0xb3, 0xa0, 0x4e, 0xf5, 0x8c, 0xaf, 0x40, 0xf3, 0x54,
+	0x04, 0x39, 0x3a, 0x9c, 0x3a, 0x35, 0x3b, 0x3f, 0x71, 0xb9, 0x86, 0x81, 0xdf, 0x83, 0x76, 0x10,
+	0xf5, 0x9f, 0x87, 0xc1, 0xe1, 0x51, 0x6e, 0x57, 0x2e, 0xe1, 0x6e, 0x05, 0xd1, 0x43, 0xe2, 0xe0,
+	0xaf, 0x43, 0xcd, 0x97, 0x94, 0xe0, 0xa6, 0x73, 0x12, 0x75, 0xdc, 0xef, 0x6a, 0xd4, 0x5e, 0x68,
+	0xbf, 0x73, 0xde, 0x85, 0xa6, 0x9e, 0xf7, 0xf0, 0x4b, 0x34, 0x13, 0x5e, 0xa6, 0x21, 0x51, 0x9d,
+	0x01, 0x40, 0x39, 0xed, 0x61, 0x2a, 0x49, 0xa5, 0xf0, 0x95, 0xc3, 0x55, 0x5d, 0x05, 0x50, 0xdb,
+	0x83, 0x9d, 0xa5, 0x54, 0x37, 0x52, 0x75, 0x0d, 0xc8, 0x6f, 0x8f, 0x1f, 0x4e, 0x5d, 0x48, 0x79,
+	0x14, 0xae, 0x8f, 0xa2, 0x72, 0x01, 0xad, 0x9d, 0x7d, 0xa8, 0xed, 0xea, 0x5e, 0x57, 0x75, 0x94,
+	0xd6, 0xb9, 0x8e, 0xb2, 0x2c, 0x1f, 0x35, 0x57, 0x01, 0x78, 0xed, 0xf2, 0x2c, 0x09, 0x52, 0x99,
+	0x8d, 0x5d, 0xbb, 0xc6, 0xac, 0xe7, 0xce, 0x0f, 0x30, 0x7f, 0x6e, 0x96, 0x44, 0x85, 0x45, 0x18,
+	0xc6, 0xa7, 0x52, 0xf9, 0x55, 0xcb, 0x35, 0x20, 0xbf, 0x03, 0xed, 0x54, 0x0e, 0x44, 0x10, 0xe1,
+	0xdd, 0xa9, 0xc3, 0x94, 0x08, 0x74, 0x81, 0x54, 0xe6, 0xe9, 0xa8, 0x2f, 0x9e, 0x63, 0xd3, 0xa1,
+	0xb6, 0x02, 0x42, 0xad, 0x23, 0xc6, 0xf9, 0x04, 0x16, 0x8a, 0xbd, 0x76, 0x63, 0x9d, 0x4e, 0x39,
+	0xd4, 0x28, 0x33, 0x2a, 0x9b, 0xd1, 0xba, 0xe8, 0x76, 0x2b, 0x65, 0xb7, 0xeb, 0xfc, 0xc9, 0x82,
+	0xce, 0x58, 0xcf, 0x32, 0xd9, 0xfd, 0x5a, 0xff, 0x49, 0xf7, 0x4b, 0xf6, 0xc9, 0x68, 0x13, 0xcb,
+	0xd5, 0x10, 0x9a, 0x6b, 0x98, 0xf8, 0xd8, 0xdf, 0x8c, 0x99, 0x4b, 0x63, 0xd6, 0x71, 0xb4, 0xae,
+	0x86, 0xf1, 0xa1, 0x5d, 0x5b, 0xaa, 0x5e, 0x78, 0x62, 0xb8, 0x70, 0x34, 0x17, 0x59, 0x9d, 0xdf,
+	0x5b, 0x30, 0x6b, 0x2a, 0x60, 0xe1, 0x26, 0x17, 0xcb, 0x60, 0x31, 0x2e, 0x29, 0xb3, 0xd2, 0x9a,
+	0x26, 0x9d, 0x51, 0x2e, 0x33, 0xad, 0x87, 0x02, 0xf8, 0x4d, 0x68, 0x61, 0x34, 0x12, 0xb7, 0xf2,
+	0x0e, 0x1c, 0x94, 0x1e, 0xa3, 0xc0, 0x6d, 0x68, 0x23, 0x49, 0x09, 0xd5, 0x95, 0x47, 0x0d, 0xc4,
+	0xd9, 0x06, 0xc9, 0xdd, 0x82, 0x56, 0x2a, 0x7f, 0x90, 0x5e, 0x2e, 0x7d, 0x1d, 0x9f, 0x05, 0xec,
+	0x48, 0xe8, 0x6c, 0x86, 0x81, 0x8c, 0x72, 0xa5, 0x22, 0xba, 0x80, 0xef, 0xa7, 0x32, 0xcb, 0x4c,
+	0x7f, 0xa1, 0x41, 0xbe, 0x04, 0x1d, 0x2f, 0x8e, 0x22, 0xe9, 0xe1, 0x28, 0x6c, 0xb4, 0x1d, 0x47,
+	0x4d, 0x6c, 0x53, 0x3d, 0xb7, 0xcd, 0x9f, 0x2b, 0xd0, 0x19, 0x7b, 0x80, 0xe0, 0x1f, 0x41, 0xdb,
+	0x74, 0x0a, 0x26, 0xd4, 0x6e, 0x4d, 0x1d, 0x23, 0x88, 0xdd, 0x2d, 0x99, 0x31, 0x91, 0x7b, 0xa4,
+	0xf0, 0xf4, 0x44, 0x3e, 0x76, 0x18, 0xd7, 0x30, 0xf2, 0xcf, 0x27, 0x75, 0xaf, 0x4e, 0x79, 0x57,
+	0xd9, 0x2c, 0xe8, 0x4a, 0x76, 0xe2, 0x64, 0x9f, 0xc3, 0x2c, 0xe5, 0x8d, 0xe2, 0xa9, 0xa4, 0xf6,
+	0x92, 0xa7, 0x12, 0xb7, 0x8b, 0xfc, 0x06, 0xc3, 0xbf, 0x98, 0x7c, 0xe1, 0xa8, 0xff, 0xf4, 0x0b,
+	0x87, 0x56, 0x60, 0x4c, 0xc2, 0xf9, 0xad, 0x05, 0xec, 0xfc, 0x1b, 0x08, 0xce, 0xe5, 0xa9, 0xf4,
+	0xe2, 0xd4, 0xd7, 0x8f, 0x88, 0x93, 0xcd, 0xda, 0xf7, 0x22, 0x74, 0x89, 0xea, 0x6a, 0x2e, 0xf4,
+	0x91, 0xe7, 0xc3, 0x30, 0xec, 0x67, 0xa3, 0xc8, 0xa3, 0xfb, 0x6b, 0xb9, 0x2d, 0x44, 0xf4, 0x46,
+	0x91, 0x47, 0x43, 0xe1, 0x28, 0xf2, 0xf4, 0xd5, 0xb5, 0x5c, 0x0d, 0x99, 0x69, 0xac, 0x56, 0x4c,
+	0x63, 0x45, 0xdc, 0xd6, 0xcb, 0xb8, 0xa5, 0x0e, 0xbf, 0xd8, 0x90, 0x9a, 0xd4, 0x78, 0x30, 0x10,
+	0x91, 0xaf, 0x3d, 0xdd, 0x80, 0x13, 0x0d, 0x63, 0x65, 0xb2, 0x61, 0x34, 0x35, 0x5b, 0xb5, 0xa8,
+	0x93, 0x1d, 0x5a, 0x8d, 0x70, 0x0a, 0x30, 0x1a, 0xd5, 0x2f, 0x6a, 0xd4, 0x18, 0xd3, 0xe8, 0x6f,
+	0x93, 0x16, 0x2b, 0xbc, 0x3b, 0x49, 0x83, 0x81, 0x48, 0x4d, 0x6b, 0x60, 0x40, 0x4c, 0x70, 0xfa,
+	0xc2, 0x75, 0xb6, 0x6e, 0xb9, 0x25, 0xc2, 0x6c, 0x59, 0x2d, 0xb7, 0x5c, 0x84, 0x8e, 0x16, 0xed,
+	0x97, 0xe6, 0x01, 0x8d, 0xda, 0xcd, 0x22, 0x12, 0x11, 0x87, 0xda, 0x48, 0xb8, 0xc4, 0x04, 0x53,
+	0x98, 0x3f, 0xd3, 0xba, 0xb6, 0x8d, 0xfd, 0x75, 0xf4, 0x90, 0xbe, 0xea, 0x05, 0xb4, 0xea, 0x16,
+	0xb0, 0xf3, 0x6b, 0x0b, 0xa0, 0x7c, 0x3a, 0xc3, 0xaa, 0x7c, 0x2a, 0xc2, 0x7e, 0x12, 0x67, 0x01,
+	0xf9, 0x93, 0x45, 0xbb, 0x77, 0x4e, 0x45, 0xb8, 0xa7, 0x51, 0x53, 0x93, 0x0a, 0x87, 0x5a, 0x16,
+	0xfc, 0x68, 0x5a, 0x51, 0x5a, 0xd3, 0xb5, 0x1f, 0x89, 0xb5, 0xff, 0xfd, 0x3f, 0x3d, 0x04, 0x68,
+	0xa8, 0x18, 0xcc, 0xeb, 0x17, 0x07, 0xf3, 0x46, 0x39, 0x98, 0xff, 0xdc, 0x82, 0xee, 0xb8, 0xdb,
+	0xff, 0x37, 0x35, 0x43, 0x27, 0x4a, 0x69, 0xde, 0x34, 0xb9, 0x4e, 0x83, 0x17, 0x1d, 0xc0, 0xf9,
+	0x87, 0x05, 0xf3, 0xe7, 0x02, 0x18, 0x4f, 0x26, 0xbc, 0x3c, 0x38, 0x31, 0x05, 0x46, 0x43, 0xd4,
+	0xee, 0x78, 0x9e, 0x4c, 0xf2, 0xa2, 0x2c, 0x17, 0xf0, 0x4f, 0x65, 0x30, 0xec, 0xac, 0xcd, 0xba,
+	0x9f, 0xc8, 0xb4, 0x7f, 0x84, 0x75, 0x4a, 0x69, 0x36, 0x6f, 0x08, 0x7b, 0x32, 0xdd, 0x89, 0xb3,
+	0x1c, 0xbd, 0x23, 0xf0, 0x43, 0xd9, 0xf7, 0xc2, 0x38, 0x93, 0xbe, 0x76, 0x02, 0x40, 0xd4, 0x26,
+	0x61, 0x70, 0x3c, 0xc0, 0x1e, 0xc1, 0xbc, 0x32, 0x19, 0x77, 0xe8, 0x22, 0x52, 0x3f, 0x30, 0x65,
+	0x38, 0x1e, 0x9c, 0xa6, 0x41, 0x2e, 0x4b, 0x2e, 0xe5, 0x17, 0xb3, 0x84, 0x35, 0x6c, 0x2b, 0x23,
+	0x68, 0x17, 0xbf, 0x1a, 0x38, 0x83, 0xee, 0xb7, 0xd1, 0x71, 0x14, 0x9f, 0x46, 0x84, 0x63, 0x33,
+	0x7c, 0x01, 0x66, 0xf7, 0x87, 0x71, 0x2e, 0xb6, 0xcf, 0x3c, 0x29, 0x7d, 0xe9, 0x33, 0x0b, 0x51,
+	0x54, 0xaf, 0x0a, 0x54, 0x85, 0xcf, 0xa1, 0x83, 0xf9, 0xfa, 0xc1, 0x94, 0x55, 0xf9, 0x75, 0xe0,
+	0x4f, 0xe3, 0xf8, 0x89, 0x88, 0x46, 0xa5, 0x5d, 0x33, 0x56, 0xe3, 0x5d, 0xfc, 0x87, 0x21, 0xfc,
+	0x6f, 0xa2, 0x70, 0xc4, 0xea, 0x2b, 0x7f, 0xa9, 0x43, 0xbb, 0x78, 0x29, 0xe5, 0x00, 0x0d, 0x57,
+	0x0e, 0xe2, 0x13, 0xc9, 0x66, 0x78, 0x13, 0xaa, 0x5f, 0xca, 0x9c, 0x59, 0xb8, 0xe8, 0xc9, 0x9c,
+	0x55, 0x78, 0x0b, 0x6a, 0x58, 0xa9, 0x58, 0x15, 0xf7, 0xfa, 0x52, 0xe6, 0x1b, 0xa3, 0x47, 0x58,
+	0xfe, 0xd4, 0x37, 0x09, 0x7e, 0x2c, 0x47, 0xac, 0xce, 0xdb, 0x50, 0x77, 0x45, 0x74, 0x28, 0x59,
+	0x83, 0x77, 0xa0, 0xb9, 0x37, 0x3c, 0x08, 0x83, 0xec, 0x88, 0x35, 0xf9, 0x2c, 0xb4, 0x7b, 0xc3,
+	0x03, 0x7c, 0xaa, 0x3a, 0x90, 0xac, 0x85, 0x1f, 0xd9, 0x2b, 0xe1, 0x36, 0x9f, 0x87, 0xce, 0xb7,
+	0x51, 0x56, 0x20, 0x00, 0x2d, 0xb1, 0x37, 0x8e, 0xe9, 0xf0, 0x6b, 0xb0, 0x50, 0x48, 0xa0, 0x2a,
+	0x89, 0xf0, 0x24, 0xeb, 0xf2, 0x1b, 0x70, 0x65, 0x8c, 0xaf, 0x20, 0xcc, 0xa2, 0x26, 0xbb, 0x7b,
+	0xc3, 0xec, 0x88, 0xcd, 0x91, 0x52, 0xb4, 0x9c, 0xc7, 0x73, 0xec, 0xee, 0xc5, 0x09, 0x63, 0xb8,
+	0x72, 0x71, 0xb5, 0x80, 0xe4, 0x0d, 0x42, 0x72, 0x5a, 0x12, 0xf6, 0x0a, 0xd2, 0x9f, 0xad, 0xfb,
+	0x3e, 0xbb, 0x8a, 0x96, 0x79, 0xa6, 0x0e, 0x75, 0x8d, 0xb0, 0xbb, 0x32, 0x62, 0xd7, 0x91, 0xf5,
+	0xd9, 0xd3, 0x34, 0x18, 0xb0, 0x1b, 0xb4, 0x44, 0xbb, 0x32, 0x1b, 0xf5, 0x7e, 0x46, 0xa3, 0xcd,
+	0x26, 0xb9, 0x3b, 0xbb, 0x89, 0x47, 0x25, 0x22, 0x61, 0xd9, 0x2d, 0xf5, 0x5d, 0xef, 0x98, 0xdd,
+	0x46, 0xcb, 0x3d, 0xd3, 0x53, 0x02, 0xbb, 0x83, 0xd0, 0xfe, 0xb6, 0xea, 0xbb, 0xd9, 0x5d, 0x82,
+	0xb6, 0xa4, 0x82, 0x5e, 0x41, 0x99, 0x7d, 0x94, 0x59, 0xc4, 0xad, 0xf6, 0xbf, 0x16, 0xde, 0x31,
+	0x5b, 0x42, 0xb5, 0xf6, 0x29, 0x58, 0xd8, 0xab, 0x84, 0xde, 0x42, 0x0d, 0x1c, 0x34, 0x25, 0x36,
+	0x9b, 0xeb, 0xde, 0x8b, 0x61, 0x90, 0x4a, 0xf6, 0x1a, 0x9a, 0x1e, 0x11, 0xae, 0x8c, 0xe4, 0x29,
+	0x7b, 0xdd, 0xd0, 0x5d, 0x49, 0x2f, 0x97, 0xec, 0x0d, 0xa4, 0x17, 0x3d, 0x10, 0x7b, 0x13, 0xf7,
+	0xda, 0x3e, 0x11, 0x21, 0x7b, 0x0b, 0x2f, 0x10, 0x57, 0xbd, 0x9d, 0x75, 0xb6, 0x8c, 0xc7, 0xe8,
+	0xd1, 0x43, 0xe3, 0x6e, 0x2c, 0x7c, 0xf6, 0x3f, 0xb8, 0x7b, 0x4f, 0x86, 0xd2, 0xcb, 0xd9, 0x0a,
+	0x32, 0x3e, 0x0c, 0x87, 0xd9, 0xd1, 0xd6, 0x06, 0x7b, 0x1b, 0x09, 0x5b, 0x1b, 0xbd, 0xe0, 0x47,
+	0xc9, 0xde, 0x41, 0xb5, 0x94, 0x86, 0xf7, 0xf0, 0x40, 0x26, 0xd7, 0xb0, 0x55, 0x64, 0x52, 0x19,
+	0x91, 0xbd, 0x8b, 0xeb, 0x6d, 0xea, 0x6b, 0xd9, 0x7b, 0xb8, 0xf9, 0x0e, 0x3a, 0xdc, 0x7d, 0x5a,
+	0x6d, 0xc9, 0x90, 0xad, 0xe1, 0xea, 0x51, 0xe4, 0xa5, 0xec, 0x7d, 0xd2, 0x54, 0x17, 0x05, 0xc9,
+	0x3e, 0x58, 0xf9, 0x04, 0xf8, 0xc5, 0x66, 0x11, 0xcf, 0x47, 0x0f, 0x2f, 0x1b, 0x43, 0xef, 0x58,
+	0xe6, 0x6c, 0x86, 0x5f, 0x05, 0xd6, 0x0b, 0x03, 0xb4, 0xf2, 0xf7, 0xf4, 0x28, 0xbb, 0x1b, 0x1f,
+	0x32, 0x6b, 0xe5, 0x0b, 0x68, 0x17, 0x0f, 0x22, 0x78, 0x80, 0xaf, 0x63, 0x02, 0xd9, 0x0c, 0x02,
+	0xdf, 0xa7, 0x41, 0x9e, 0xcb, 0x88, 0x59, 0x08, 0xa8, 0xa8, 0xc0, 0x30, 0x43, 0x83, 0x90, 0xa6,
+	0x3e, 0xab, 0x1e, 0x34, 0xa8, 0x50, 0xbf, 0xff, 0xcf, 0x01, 0x00, 0xd5, 0xc0, 0xae, 0x03, 0x82,
+	0x1c, 0x00, 0x00,
 }
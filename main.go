@@ -1,23 +1,112 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/storage"
+	"github.com/minaevmike/godis/wal"
 	"go.uber.org/zap"
 )
 
+var (
+	addr       = flag.String("addr", "localhost:4321", "address to listen")
+	ordered    = flag.Bool("ordered", false, "keep keys ordered, required for range queries")
+	walFile    = flag.String("wal", "./godis.wal", "write ahead log file")
+	durability = flag.String("durability", "interval", "wal durability mode: none, interval, fsync or group-commit")
+	walStrict  = flag.Bool("wal-strict", false, "refuse to start if wal is corrupted before its tail")
+	dbs        = flag.Int("databases", 16, "amount of numbered databases")
+
+	snapshotFile     = flag.String("snapshot", "./godis.snapshot", "snapshot file, empty disables snapshots")
+	snapshotInterval = flag.Duration("snapshot-interval", time.Hour, "how often snapshot is written, zero disables scheduled snapshots")
+	backupDir        = flag.String("backup-dir", "", "directory where backups requested by clients with path are written, empty disables them")
+	restore          = flag.String("restore", "", "backup restored before start, snapshot and wal must not exist")
+	encryptionKeys   = flag.String("encryption-keys", "", "file with keys encrypting wal and snapshots, "+encryptionKeysEnv+" is used if it isn't set")
+	replicaOf        = flag.String("replica-of", "", "address of primary, server becomes read-only replica keeping data in memory")
+
+	shutdownTimeout = flag.Duration("shutdown-timeout", 30*time.Second, "max time of waiting for in-flight requests on shutdown")
+)
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backup":
+			os.Exit(runBackup(os.Args[2:]))
+		case "recover":
+			os.Exit(runRecover(os.Args[2:]))
+		}
+	}
+	flag.Parse()
+
 	log, err := zap.NewDevelopment()
 	if err != nil {
 		fmt.Printf("can't create logger: %v", err)
 		os.Exit(1)
 	}
 
-	s := server.NewServer(log)
-	err = s.Run("localhost:4321")
+	mode, err := wal.ParseMode(*durability)
 	if err != nil {
+		log.Fatal("bad durability", zap.Error(err))
+	}
+
+	opts := []server.Option{
+		server.WithWALFile(*walFile),
+		server.WithDatabases(*dbs),
+		server.WithDurability(mode),
+		server.WithSnapshotFile(*snapshotFile),
+		server.WithSnapshotInterval(*snapshotInterval),
+		server.WithBackupDir(*backupDir),
+	}
+	if *ordered {
+		opts = append(opts, server.WithStorageFactory(storage.NewOrderedStorage))
+	}
+	keys, err := loadKeys(*encryptionKeys)
+	if err != nil {
+		log.Fatal("can't load encryption keys", zap.Error(err))
+	}
+	if keys != nil {
+		opts = append(opts, server.WithEncryption(keys))
+	}
+	if *replicaOf != "" {
+		opts = append(opts, server.WithReplicaOf(server.ReplicaOptions{Primary: *replicaOf}))
+	}
+	if *walStrict {
+		walOpts := wal.DefaultOptions()
+		walOpts.Strict = true
+		opts = append(opts, server.WithWALOptions(walOpts))
+	}
+
+	if *restore != "" {
+		if err := server.Restore(*restore, *snapshotFile, *walFile); err != nil {
+			log.Fatal("can't restore backup", zap.Error(err))
+		}
+		log.Info("backup restored", zap.String("backup", *restore), zap.String("snapshot", *snapshotFile))
+	}
+
+	s := server.NewServer(log, opts...)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-signals
+		log.Info("shutting down", zap.String("signal", sig.String()))
+		ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			log.Error("shutdown", zap.Error(err))
+		}
+	}()
+
+	err = s.Run(*addr)
+	if err != server.ErrServerClosed {
 		log.Fatal("run server", zap.Error(err))
 	}
+	<-stopped
 }
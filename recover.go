@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/storage"
+	"github.com/minaevmike/godis/wal"
+	"go.uber.org/zap"
+)
+
+// runRecover implements recover subcommand: godis recover (-lsn n | -time t) -dir dir, it restores dataset
+// as it was at target from snapshot and wal of stopped or running server
+func runRecover(args []string) int {
+	flags := flag.NewFlagSet("recover", flag.ExitOnError)
+	snapshotFile := flags.String("snapshot", "./godis.snapshot", "snapshot recovery starts from, empty means only wal")
+	walFile := flags.String("wal", "./godis.wal", "write ahead log file")
+	lsn := flags.Uint64("lsn", 0, "LSN of the last replayed record")
+	at := flags.String("time", "", "records written after this time (RFC3339) aren't replayed")
+	dir := flags.String("dir", "", "new data directory for recovered dataset")
+	dbs := flags.Int("databases", 16, "amount of numbered databases")
+	ordered := flags.Bool("ordered", false, "keep keys ordered, required for range queries")
+	encryptionKeys := flags.String("encryption-keys", "", "file with keys of encrypted wal and snapshot, "+encryptionKeysEnv+" is used if it isn't set")
+	flags.Parse(args)
+
+	if *dir == "" || (*lsn == 0 && *at == "") {
+		fmt.Fprintln(os.Stderr, "-dir and -lsn or -time must be set")
+		return 2
+	}
+	target := wal.Target{LSN: *lsn}
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339Nano, *at)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bad time: %v\n", err)
+			return 2
+		}
+		target.Time = t
+	}
+	opts := []server.Option{server.WithDatabases(*dbs)}
+	if *ordered {
+		opts = append(opts, server.WithStorageFactory(storage.NewOrderedStorage))
+	}
+	keys, err := loadKeys(*encryptionKeys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "can't load encryption keys: %v\n", err)
+		return 2
+	}
+	if keys != nil {
+		opts = append(opts, server.WithEncryption(keys))
+	}
+
+	info, err := server.Recover(zap.NewNop(), server.RecoveryOptions{
+		SnapshotFile: *snapshotFile,
+		WALFile:      *walFile,
+		Target:       target,
+		Dir:          *dir,
+	}, opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "recovery failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("recovered %d keys up to lsn %d into %s\n", info.Keys, info.LSN, info.Snapshot)
+	return 0
+}
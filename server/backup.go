@@ -0,0 +1,251 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/snapshot"
+	"github.com/minaevmike/godis/wal"
+)
+
+// backupChunkSize - max size of chunk of streamed backup
+const backupChunkSize = 1 << 20
+
+var errBackupDirDisabled = errors.New("backup directory isn't set")
+
+// handleBackup streams backup to connection or writes it to file in backup directory
+func (s *Server) handleBackup(c *connection, req *godis_proto.Request) *godis_proto.Response {
+	var (
+		info *godis_proto.BackupInfo
+		err  error
+	)
+	if req.GetPath() != "" {
+		info, err = s.backupToFile(req.GetPath())
+	} else {
+		info, err = s.streamBackup(c)
+	}
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Backup{Backup: info}}
+}
+
+// backup writes consistent backup to w. Wal is rotated before dump and after it, records of segments
+// between rotations are written after dump, so backup contains all writes logged before the second rotation,
+// writes made during dump can be included as well. Backup has snapshot format, so it's restored by loading it as snapshot
+func (s *Server) backup(w io.Writer) (*godis_proto.BackupInfo, error) {
+	// segments aren't truncated by snapshots while they are read
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+	end, err := s.beginDump()
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	checkpointer, ok := s.wal.(wal.Checkpointer)
+	var from, to uint64
+	if ok {
+		release := checkpointer.Hold()
+		defer release()
+		if from, err = checkpointer.Checkpoint(); err != nil {
+			return nil, err
+		}
+	}
+
+	h := sha256.New()
+	keys := int64(0)
+	info, err := snapshot.Write(io.MultiWriter(w, h), from, s.cipher, func(write func(record *wal.Record) error) (uint64, error) {
+		err := s.dumpWAL(func(record *wal.Record) error {
+			if record.Cmd == wal.Write {
+				keys++
+			}
+			return write(record)
+		})
+		if err != nil || !ok {
+			return s.lastLSN(), err
+		}
+		// records buffered during dump are flushed, so they are in segments before rotation
+		if err := s.wal.Sync(); err != nil {
+			return 0, err
+		}
+		lsn := s.lastLSN()
+		if to, err = checkpointer.Checkpoint(); err != nil {
+			return 0, err
+		}
+		var writeErr error
+		err = checkpointer.ReadSegments(from, to, func(record *wal.Record) {
+			// records written after sync can be in segments as well, they are dropped,
+			// so backup has all records up to its LSN and nothing after it
+			if writeErr == nil && record.LSN <= lsn {
+				writeErr = write(record)
+			}
+		})
+		if err == nil {
+			err = writeErr
+		}
+		return lsn, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &godis_proto.BackupInfo{
+		WalPosition: to,
+		Keys:        keys,
+		Size:        info.Size,
+		Sha256:      hex.EncodeToString(h.Sum(nil)),
+		Lsn:         info.LSN,
+	}, nil
+}
+
+// streamBackup writes backup to temporary file and sends it to connection after that,
+// so slow client doesn't block writes and snapshots while backup is taken
+func (s *Server) streamBackup(c *connection) (*godis_proto.BackupInfo, error) {
+	f, err := s.tempFile("backup")
+	if err != nil {
+		return nil, err
+	}
+	defer removeTempFile(f)
+	info, err := s.backup(f)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return nil, err
+	}
+	w := &chunkWriter{c: c}
+	if _, err = io.Copy(w, f); err == nil {
+		err = w.flush()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// backupToFile writes backup to file with given name in backup directory and its checksum next to it
+func (s *Server) backupToFile(name string) (*godis_proto.BackupInfo, error) {
+	if s.backupDir == "" {
+		return nil, errBackupDirDisabled
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, fmt.Errorf("backup path %q must be file name", name)
+	}
+	path := filepath.Join(s.backupDir, name)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.backup(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := snapshot.WriteChecksumFile(path, info.Sha256); err != nil {
+		return nil, err
+	}
+	info.Path = path
+	return info, nil
+}
+
+// chunkWriter sends written data to connection as backup chunks
+type chunkWriter struct {
+	c   *connection
+	buf []byte
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	cw.buf = append(cw.buf, p...)
+	for len(cw.buf) >= backupChunkSize {
+		if err := cw.send(cw.buf[:backupChunkSize]); err != nil {
+			return 0, err
+		}
+		cw.buf = cw.buf[backupChunkSize:]
+	}
+	return len(p), nil
+}
+
+func (cw *chunkWriter) flush() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	err := cw.send(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+func (cw *chunkWriter) send(chunk []byte) error {
+	err := cw.c.write(&godis_proto.Response{ResponseValue: &godis_proto.Response_Chunk{Chunk: chunk}})
+	if err != nil {
+		// rest of stream can't be sent, so connection is closed after request
+		cw.c.broken = true
+	}
+	return err
+}
+
+// Restore checks backup and installs it as snapshot, so server started with the same snapshot and wal files
+// loads data of backup. Existing snapshot and wal aren't overwritten
+func Restore(backup, snapshotFile, walFile string) error {
+	if _, err := snapshot.Verify(backup); err != nil {
+		return fmt.Errorf("backup %s is invalid: %v", backup, err)
+	}
+	if err := checkNoData(snapshotFile, walFile); err != nil {
+		return err
+	}
+
+	src, err := os.Open(backup)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := snapshotFile + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, snapshotFile)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// checkNoData returns error if snapshot or wal exist
+func checkNoData(snapshotFile, walFile string) error {
+	if _, err := os.Stat(snapshotFile); err == nil {
+		return fmt.Errorf("snapshot %s already exists", snapshotFile)
+	}
+	segments, err := filepath.Glob(walFile + ".*")
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(walFile); err == nil || len(segments) > 0 {
+		return fmt.Errorf("wal %s already exists", walFile)
+	}
+	return nil
+}
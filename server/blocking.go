@@ -0,0 +1,127 @@
+package server
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+// tryFunc is called when key waiter is waiting for was changed,
+// it returns response and true if waiter was served
+type tryFunc func(key string) (*godis_proto.Response, bool)
+
+type waiter struct {
+	keys     []string
+	try      tryFunc
+	elements map[string]*list.Element
+	result   chan *godis_proto.Response
+}
+
+// blockingQueues keeps connections blocked on keys, waiters are served in FIFO order
+type blockingQueues struct {
+	mu      sync.Mutex
+	waiters map[string]*list.List
+	// count - amount of waiting connections, allows to skip locking when nobody waits
+	count int64
+}
+
+func newBlockingQueues() *blockingQueues {
+	return &blockingQueues{waiters: make(map[string]*list.List)}
+}
+
+// wait tries to serve request immediately and if it's not possible waits until one of keys is changed,
+// timeout is reached or cancel is closed. Zero timeout means wait forever.
+// nil response is returned if request wasn't served
+func (b *blockingQueues) wait(keys []string, timeout time.Duration, cancel <-chan struct{}, try tryFunc) *godis_proto.Response {
+	b.mu.Lock()
+	for _, key := range keys {
+		if resp, ok := try(key); ok {
+			b.mu.Unlock()
+			return resp
+		}
+	}
+
+	w := &waiter{
+		keys:     keys,
+		try:      try,
+		elements: make(map[string]*list.Element, len(keys)),
+		result:   make(chan *godis_proto.Response, 1),
+	}
+	for _, key := range keys {
+		if _, ok := w.elements[key]; ok {
+			continue
+		}
+		l, ok := b.waiters[key]
+		if !ok {
+			l = list.New()
+			b.waiters[key] = l
+		}
+		w.elements[key] = l.PushBack(w)
+	}
+	atomic.AddInt64(&b.count, 1)
+	b.mu.Unlock()
+
+	var timer <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case resp := <-w.result:
+		return resp
+	case <-timer:
+	case <-cancel:
+	}
+
+	b.mu.Lock()
+	served := w.elements == nil
+	if !served {
+		b.remove(w)
+	}
+	b.mu.Unlock()
+	if served {
+		return <-w.result
+	}
+	return nil
+}
+
+// signal serves waiters of key, must be called after key was changed
+func (b *blockingQueues) signal(key string) {
+	if atomic.LoadInt64(&b.count) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	l, ok := b.waiters[key]
+	if ok {
+		for e := l.Front(); e != nil; {
+			w := e.Value.(*waiter)
+			e = e.Next()
+			resp, ok := w.try(key)
+			if !ok {
+				continue
+			}
+			b.remove(w)
+			w.result <- resp
+		}
+	}
+	b.mu.Unlock()
+}
+
+// remove must be called under lock
+func (b *blockingQueues) remove(w *waiter) {
+	for key, e := range w.elements {
+		l := b.waiters[key]
+		l.Remove(e)
+		if l.Len() == 0 {
+			delete(b.waiters, key)
+		}
+	}
+	w.elements = nil
+	atomic.AddInt64(&b.count, -1)
+}
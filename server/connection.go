@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/wire"
+)
+
+// connection wraps client connection, writes can be made from several goroutines
+// e.g. when messages are pushed to subscribed connection
+type connection struct {
+	// Conn reads data through reader
+	net.Conn
+	reader       *bufio.Reader
+	wireProtocol wire.Protocol
+	writeTimeout time.Duration
+	mu           sync.Mutex
+	// subscriber is not nil when connection is in subscribe mode
+	subscriber *subscriber
+	// broken is set when client sent data while request was blocked
+	broken bool
+	// db is index of database selected by Select
+	db int
+	// quota is nil if client isn't limited
+	quota *quotaState
+}
+
+func newConnection(conn net.Conn, wireProtocol wire.Protocol, writeTimeout time.Duration) *connection {
+	reader := bufio.NewReader(conn)
+	return &connection{
+		Conn:         bufferedConn{Conn: conn, reader: reader},
+		reader:       reader,
+		wireProtocol: wireProtocol,
+		writeTimeout: writeTimeout,
+	}
+}
+
+func (c *connection) write(resp *godis_proto.Response) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.writeTimeout > 0 {
+		c.Conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+	return c.wireProtocol.Write(c.Conn, resp)
+}
+
+// bufferedConn reads from buffer, so server can wait for request without reading it
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// watchClose returns channel which is closed when client closes connection or sends anything,
+// it's used while request is blocked and client isn't expected to send data.
+// stop must be called before next read from connection
+func (c *connection) watchClose() (closed <-chan struct{}, stop func()) {
+	ch := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var b [1]byte
+		_, err := c.Conn.Read(b[:])
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return
+		}
+		// connection is closed or client violated protocol, in both cases connection can't be used
+		c.broken = true
+		close(ch)
+	}()
+	return ch, func() {
+		c.Conn.SetReadDeadline(time.Now())
+		<-done
+		c.Conn.SetReadDeadline(time.Time{})
+	}
+}
+
+// watchCancel returns channel which is closed like watchClose or when server is stopped,
+// so requests blocked for long time don't delay Shutdown
+func (s *Server) watchCancel(c *connection) (cancel <-chan struct{}, stop func()) {
+	closed, stopWatch := c.watchClose()
+	ch := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-closed:
+		case <-s.stop:
+		case <-done:
+			return
+		}
+		close(ch)
+	}()
+	return ch, func() {
+		close(done)
+		stopWatch()
+	}
+}
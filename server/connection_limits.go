@@ -0,0 +1,172 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"go.uber.org/zap"
+)
+
+var (
+	errTooManyConnections     = errors.New("too many connections")
+	errTooManyHostConnections = errors.New("too many connections from host")
+)
+
+// rejectWriteTimeout - max time of writing error to rejected connection
+const rejectWriteTimeout = time.Second
+
+// ConnectionLimits configure client connections, zero field means no limit
+type ConnectionLimits struct {
+	// MaxConnections - max amount of open connections, new connections are rejected
+	MaxConnections int
+	// MaxConnectionsPerHost - max amount of open connections from one remote host
+	MaxConnectionsPerHost int
+	// IdleTimeout - connection is closed when client doesn't send requests during this time,
+	// connections in subscribe mode aren't closed
+	IdleTimeout time.Duration
+	// ReadTimeout - max time of reading request after its first byte is received
+	ReadTimeout time.Duration
+	// WriteTimeout - max time of writing response or pushed message
+	WriteTimeout time.Duration
+	// KeepAlive - period of TCP keepalive probes, negative disables keepalive
+	KeepAlive time.Duration
+}
+
+// DefaultConnectionLimits returns connection limits used by server by default
+func DefaultConnectionLimits() ConnectionLimits {
+	return ConnectionLimits{
+		MaxConnections: 10000,
+		ReadTimeout:    30 * time.Second,
+		WriteTimeout:   30 * time.Second,
+		KeepAlive:      time.Minute,
+	}
+}
+
+// connectionTracker enforces connection limits and counts connection metrics
+type connectionTracker struct {
+	limits ConnectionLimits
+
+	mu      sync.Mutex
+	active  int
+	perHost map[string]int
+
+	// metrics are updated atomically
+	accepted        int64
+	rejected        int64
+	rejectedPerHost int64
+	idleClosed      int64
+	readTimeouts    int64
+	writeTimeouts   int64
+}
+
+func newConnectionTracker(limits ConnectionLimits) *connectionTracker {
+	return &connectionTracker{limits: limits, perHost: make(map[string]int)}
+}
+
+// acquire registers new connection, it returns error if connection exceeds limits
+func (ct *connectionTracker) acquire(addr net.Addr) error {
+	host := remoteHost(addr)
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.limits.MaxConnections > 0 && ct.active >= ct.limits.MaxConnections {
+		atomic.AddInt64(&ct.rejected, 1)
+		return errTooManyConnections
+	}
+	if ct.limits.MaxConnectionsPerHost > 0 && ct.perHost[host] >= ct.limits.MaxConnectionsPerHost {
+		atomic.AddInt64(&ct.rejectedPerHost, 1)
+		return errTooManyHostConnections
+	}
+	ct.active++
+	ct.perHost[host]++
+	atomic.AddInt64(&ct.accepted, 1)
+	return nil
+}
+
+func (ct *connectionTracker) release(addr net.Addr) {
+	host := remoteHost(addr)
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.active--
+	ct.perHost[host]--
+	if ct.perHost[host] == 0 {
+		delete(ct.perHost, host)
+	}
+}
+
+// configure sets keepalive of accepted connection
+func (ct *connectionTracker) configure(conn net.Conn) {
+	tcp, ok := conn.(*net.TCPConn)
+	if !ok || ct.limits.KeepAlive == 0 {
+		return
+	}
+	if ct.limits.KeepAlive < 0 {
+		tcp.SetKeepAlive(false)
+		return
+	}
+	tcp.SetKeepAlive(true)
+	tcp.SetKeepAlivePeriod(ct.limits.KeepAlive)
+}
+
+// writeFailed counts write timeouts
+func (ct *connectionTracker) writeFailed(err error) {
+	if isTimeout(err) {
+		atomic.AddInt64(&ct.writeTimeouts, 1)
+	}
+}
+
+func (ct *connectionTracker) stats() *godis_proto.ConnectionStats {
+	ct.mu.Lock()
+	active := ct.active
+	ct.mu.Unlock()
+	return &godis_proto.ConnectionStats{
+		Active:          int64(active),
+		Accepted:        atomic.LoadInt64(&ct.accepted),
+		Rejected:        atomic.LoadInt64(&ct.rejected),
+		RejectedPerHost: atomic.LoadInt64(&ct.rejectedPerHost),
+		IdleClosed:      atomic.LoadInt64(&ct.idleClosed),
+		ReadTimeouts:    atomic.LoadInt64(&ct.readTimeouts),
+		WriteTimeouts:   atomic.LoadInt64(&ct.writeTimeouts),
+	}
+}
+
+// reject tells client why connection is closed
+func (s *Server) reject(conn net.Conn, err error) {
+	defer conn.Close()
+	s.log.Warn("connection rejected", zap.String("remote", conn.RemoteAddr().String()), zap.Error(err))
+	conn.SetWriteDeadline(time.Now().Add(rejectWriteTimeout))
+	s.wireProtocol.Write(conn, getCodeErrorResponse(godis_proto.ErrorCode_TooManyConnections, err.Error()))
+}
+
+// waitRequest waits for the first byte of the next request, it returns false if connection is idle for too long
+// or closed. After that the rest of request must be read during read timeout
+func (s *Server) waitRequest(c *connection) bool {
+	limits := s.connections.limits
+	if limits.IdleTimeout > 0 && c.subscriber == nil {
+		c.SetReadDeadline(time.Now().Add(limits.IdleTimeout))
+	} else {
+		c.SetReadDeadline(time.Time{})
+	}
+	_, err := c.reader.Peek(1)
+	if err != nil {
+		if isTimeout(err) {
+			atomic.AddInt64(&s.connections.idleClosed, 1)
+			s.log.Debug("closing idle connection", zap.String("remote", c.RemoteAddr().String()))
+		}
+		return false
+	}
+	if limits.ReadTimeout > 0 {
+		c.SetReadDeadline(time.Now().Add(limits.ReadTimeout))
+	} else {
+		c.SetReadDeadline(time.Time{})
+	}
+	return true
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/snapshot"
+	"github.com/minaevmike/godis/storage"
+	"github.com/minaevmike/godis/wal"
+	"go.uber.org/zap"
+)
+
+const defaultDatabases = 16
+
+// database is numbered keyspace with its own storage, every request works with one database
+type database struct {
+	index    int
+	storage  storage.Storage
+	blocking *blockingQueues
+	// quota is nil if database isn't limited
+	quota *quotaState
+}
+
+func newDatabase(index int, st storage.Storage) *database {
+	return &database{index: index, storage: st, blocking: newBlockingQueues()}
+}
+
+func defaultStorageFactory() storage.Storage {
+	return storage.NewShardMapStorage(32)
+}
+
+// database returns database selected by request or, if request doesn't select it, by connection
+func (s *Server) database(c *connection, req *godis_proto.Request) (*database, error) {
+	index := c.db
+	if req.GetDatabase() != nil {
+		index = int(req.GetDatabase().GetIndex())
+	}
+	if index < 0 || index >= len(s.databases) {
+		return nil, fmt.Errorf("database index must be less than %d", len(s.databases))
+	}
+	return s.databases[index], nil
+}
+
+// size returns amount of keys in database
+func (db *database) size() int {
+	return storageLen(db.storage)
+}
+
+// storageLen returns amount of keys in storage or its view
+func storageLen(st storage.Storage) int {
+	if counter, ok := st.(storage.Counter); ok {
+		return counter.Len()
+	}
+	count := 0
+	st.ForEach(func(string, *godis_proto.Value) {
+		count++
+	})
+	return count
+}
+
+func (db *database) stats() *godis_proto.DatabaseStats {
+	stats := &godis_proto.DatabaseStats{Index: uint32(db.index), Keys: int64(db.size())}
+	if sizer, ok := db.storage.(storage.Sizer); ok {
+		stats.Bytes = sizer.Size()
+	}
+	if db.quota != nil {
+		stats.MaxKeys = int64(db.quota.quota.MaxKeys)
+		stats.MaxBytes = db.quota.quota.MaxBytes
+		stats.Rejected = atomic.LoadInt64(&db.quota.rejected)
+	}
+	return stats
+}
+
+// flush deletes all keys of database, keyspace notifications aren't sent for them
+func (s *Server) flush(db *database) {
+	deleteAll := func(st storage.Storage) error {
+		var keys []string
+		st.ForEach(func(key string, _ *godis_proto.Value) {
+			keys = append(keys, key)
+		})
+		for _, key := range keys {
+			st.Delete(key)
+		}
+		return nil
+	}
+
+	if st, ok := db.storage.(storage.Atomic); ok {
+		st.Atomically(func(view storage.Storage) error {
+			deleteAll(view)
+			// written under lock, so writes made after flush aren't replayed before it
+			s.writeWAL(&wal.Record{Cmd: wal.Flush, DB: uint32(db.index)})
+			return nil
+		})
+		return
+	}
+	deleteAll(db.storage)
+	s.writeWAL(&wal.Record{Cmd: wal.Flush, DB: uint32(db.index)})
+}
+
+// writeWAL writes records to wal as one batch if wal supports it
+func (s *Server) writeWAL(records ...*wal.Record) {
+	var err error
+	if b, ok := s.wal.(wal.Batcher); ok {
+		err = b.WriteBatch(records)
+	} else {
+		for _, r := range records {
+			if r.DB != 0 {
+				err = fmt.Errorf("wal doesn't support databases")
+				break
+			}
+			if err = s.wal.Write(r.Cmd, r.Key, r.Value); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		s.log.Error("can't write to wal", zap.Error(err))
+	}
+}
+
+// lastLSN returns LSN of the last record written to wal
+func (s *Server) lastLSN() uint64 {
+	if s.wal == nil {
+		return 0
+	}
+	return s.wal.LastLSN()
+}
+
+// dumpWAL writes content of all databases as wal records, it's used by wal compaction.
+// Every database starts with Flush record, so keys of replaced segments don't survive if they are replayed
+// before dump
+func (s *Server) dumpWAL(write func(record *wal.Record) error) error {
+	for _, db := range s.databases {
+		if err := write(&wal.Record{Cmd: wal.Flush, DB: uint32(db.index)}); err != nil {
+			return err
+		}
+		var (
+			mu  sync.Mutex
+			err error
+		)
+		// storage can call fn concurrently
+		db.storage.ForEach(func(key string, v *godis_proto.Value) {
+			record := &wal.Record{Cmd: wal.Write, DB: uint32(db.index), Key: []byte(key), Value: s.marshal(v)}
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				err = write(record)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpFile writes records of dumpWAL to temporary file in snapshot format and returns file positioned at its start.
+// Records are sent to clients from file, so slow client doesn't block writes while dump holds storage locks
+func (s *Server) dumpFile() (*os.File, error) {
+	f, err := s.tempFile("dump")
+	if err != nil {
+		return nil, err
+	}
+	_, err = snapshot.Write(f, 0, s.cipher, func(write func(record *wal.Record) error) (uint64, error) {
+		return 0, s.dumpWAL(write)
+	})
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		removeTempFile(f)
+		return nil, err
+	}
+	return f, nil
+}
+
+// tempFile creates temporary file next to wal, it's removed by removeTempFile
+func (s *Server) tempFile(name string) (*os.File, error) {
+	return ioutil.TempFile(filepath.Dir(s.walFile), name+"-*.tmp")
+}
+
+func removeTempFile(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// replay applies wal record to its database
+func (s *Server) replay(record *wal.Record) {
+	if int(record.DB) >= len(s.databases) {
+		s.log.Error("wal record of unknown database", zap.Uint32("db", record.DB))
+		return
+	}
+	st := s.databases[record.DB].storage
+	switch record.Cmd {
+	case wal.Write:
+		v := &godis_proto.Value{}
+		err := s.cd.Unmarshal(record.Value, v)
+		if err != nil {
+			s.log.Error("can't unmarshal data from wal", zap.Error(err))
+			return
+		}
+		err = st.Set(string(record.Key), v)
+		if err != nil {
+			s.log.Error("can't set value from wal", zap.Error(err))
+		}
+	case wal.Delete:
+		err := st.Delete(string(record.Key))
+		if err != nil {
+			s.log.Error("can't delete value from wal", zap.Error(err))
+		}
+	case wal.Flush:
+		var (
+			mu   sync.Mutex
+			keys []string
+		)
+		// storage can call fn concurrently
+		st.ForEach(func(key string, _ *godis_proto.Value) {
+			mu.Lock()
+			keys = append(keys, key)
+			mu.Unlock()
+		})
+		for _, key := range keys {
+			st.Delete(key)
+		}
+	default:
+		if err := s.replayDelta(st, record); err != nil {
+			s.log.Error("can't apply change from wal", zap.ByteString("key", record.Key), zap.Error(err))
+		}
+	}
+}
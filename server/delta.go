@@ -0,0 +1,323 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/storage"
+	"github.com/minaevmike/godis/wal"
+)
+
+// errNotChanged is returned by update which doesn't change value, nothing is written to wal then
+var errNotChanged = errors.New("value isn't changed")
+
+// dumpGate makes updates write whole values to wal while dump is made. Dump is made while writes go on and
+// records written during it are replayed after it, so they must not depend on values they are applied to
+type dumpGate struct {
+	// mu is held for reading by update while it changes storage and writes wal
+	mu     sync.RWMutex
+	active int
+}
+
+// beginDump makes updates write whole values until end is called. Records written before it are flushed,
+// so wal rotated after it has only records which can be replayed after dump
+func (s *Server) beginDump() (func(), error) {
+	s.dumps.mu.Lock()
+	s.dumps.active++
+	s.dumps.mu.Unlock()
+	end := func() {
+		s.dumps.mu.Lock()
+		s.dumps.active--
+		s.dumps.mu.Unlock()
+	}
+	if s.wal != nil {
+		if err := s.wal.Sync(); err != nil {
+			end()
+			return nil, err
+		}
+	}
+	return end, nil
+}
+
+// change is delta of value written to wal with cmd
+type change struct {
+	cmd   wal.Command
+	delta *godis_proto.Value
+}
+
+// mutate updates value of key with fn and writes delta with cmd to wal instead of new value.
+// While dump is made new value is written, see dumpGate
+func (s *Server) mutate(db *database, key string, cmd wal.Command, delta *godis_proto.Value, fn storage.UpdateFunc) (*godis_proto.Value, error) {
+	return s.mutateChanges(db, key, func(old *godis_proto.Value) (*godis_proto.Value, []change, error) {
+		v, err := fn(old)
+		return v, []change{{cmd: cmd, delta: delta}}, err
+	})
+}
+
+// mutateChanges is like mutate, but deltas are returned by fn with new value, they are written to wal as one batch.
+// errNotChanged is returned if fn returns no deltas
+func (s *Server) mutateChanges(db *database, key string, fn func(old *godis_proto.Value) (*godis_proto.Value, []change, error)) (*godis_proto.Value, error) {
+	s.dumps.mu.RLock()
+	defer s.dumps.mu.RUnlock()
+	v, err := db.storage.Update(key, func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		v, changes, err := fn(old)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 {
+			return nil, errNotChanged
+		}
+		if s.dumps.active > 0 {
+			s.writeWAL(s.valueRecord(db, key, v))
+			return v, nil
+		}
+		// deltas depend on order, so they are written under lock of key
+		records := make([]*wal.Record, len(changes))
+		for i, c := range changes {
+			records[i] = &wal.Record{Cmd: c.cmd, DB: uint32(db.index), Key: []byte(key), Value: s.marshal(c.delta)}
+		}
+		s.writeWAL(records...)
+		return v, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyUpdate(db, key, v)
+	return v, nil
+}
+
+// replayDelta applies wal record which contains change of value
+func (s *Server) replayDelta(st storage.Storage, record *wal.Record) error {
+	delta := &godis_proto.Value{}
+	if len(record.Value) > 0 {
+		if err := s.cd.Unmarshal(record.Value, delta); err != nil {
+			return err
+		}
+	}
+	_, err := st.Update(string(record.Key), func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		switch record.Cmd {
+		case wal.Expire:
+			return withTTL(old, delta.GetTtl())
+		case wal.MapSet:
+			v, _, err := setFields(old, delta.GetStringMap().GetStringMap(), delta.GetTtl())
+			return v, err
+		case wal.MapDelete:
+			v, _, err := deleteFields(old, delta.GetStringSlice().GetStringArrayVal())
+			return v, err
+		case wal.PushLeft, wal.PushRight:
+			return pushElements(old, delta.GetStringSlice().GetStringArrayVal(), delta.GetTtl(), record.Cmd == wal.PushLeft)
+		case wal.PopLeft, wal.PopRight:
+			v, _, err := popElement(old, record.Cmd == wal.PopLeft)
+			return v, err
+		case wal.Incr:
+			n, err := strconv.ParseInt(delta.GetStringVal(), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			v, _, err := increment(old, n, delta.GetTtl())
+			return v, err
+		case wal.StreamAdd:
+			entries := delta.GetStream().GetEntries()
+			if len(entries) != 1 {
+				return nil, errors.New("stream record must contain one entry")
+			}
+			return appendEntry(old, entries[0], delta.GetTtl())
+		case wal.StreamTrim:
+			maxLen, err := strconv.Atoi(delta.GetStringVal())
+			if err != nil {
+				return nil, err
+			}
+			v, _, err := trimEntries(old, maxLen)
+			return v, err
+		case wal.StreamGroupCreate, wal.StreamDeliver, wal.StreamAck:
+			groups := delta.GetStream().GetGroups()
+			if len(groups) != 1 {
+				return nil, errors.New("stream record must contain one group")
+			}
+			if record.Cmd == wal.StreamGroupCreate {
+				return addGroup(old, groups[0])
+			}
+			return applyGroupDelta(old, record.Cmd, groups[0])
+		}
+		return nil, fmt.Errorf("unknown wal command %d", record.Cmd)
+	})
+	if err == errNotChanged {
+		return nil
+	}
+	return err
+}
+
+// expire sets ttl of existing key and returns 1, or 0 if key doesn't exist
+func (s *Server) expire(db *database, req *godis_proto.Request) *godis_proto.Response {
+	ttl := req.GetValue().GetTtl()
+	_, err := s.mutate(db, req.GetKey(), wal.Expire, &godis_proto.Value{Ttl: ttl}, func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		return withTTL(old, ttl)
+	})
+	if err == errNotChanged {
+		return getCountResponse(0)
+	}
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getCountResponse(1)
+}
+
+// hset sets fields of map stored by key and returns amount of added fields
+func (s *Server) hset(db *database, req *godis_proto.Request) *godis_proto.Response {
+	fields := req.GetValue().GetStringMap().GetStringMap()
+	if len(fields) == 0 {
+		return getErrorResponse("no fields to set")
+	}
+	ttl := req.GetValue().GetTtl()
+	delta := &godis_proto.Value{
+		Value: &godis_proto.Value_StringMap{StringMap: &godis_proto.MapString{StringMap: fields}},
+		Ttl:   ttl,
+	}
+	added := 0
+	_, err := s.mutate(db, req.GetKey(), wal.MapSet, delta, func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		v, n, err := setFields(old, fields, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if s.limits.MaxElements > 0 && len(v.GetStringMap().GetStringMap()) > s.limits.MaxElements {
+			return nil, s.limits.errTooManyElements()
+		}
+		added = n
+		return v, nil
+	})
+	if err != nil {
+		return getLimitErrorResponse(err)
+	}
+	return getCountResponse(int64(added))
+}
+
+// hdel deletes fields of map stored by key and returns amount of deleted fields, empty map is deleted
+func (s *Server) hdel(db *database, req *godis_proto.Request) *godis_proto.Response {
+	fields := req.GetValue().GetStringSlice().GetStringArrayVal()
+	delta := &godis_proto.Value{
+		Value: &godis_proto.Value_StringSlice{StringSlice: &godis_proto.RepeatedString{StringArrayVal: fields}},
+	}
+	deleted := 0
+	_, err := s.mutate(db, req.GetKey(), wal.MapDelete, delta, func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		v, n, err := deleteFields(old, fields)
+		deleted = n
+		return v, err
+	})
+	if err == errNotChanged {
+		return getCountResponse(0)
+	}
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getCountResponse(int64(deleted))
+}
+
+// incr adds number to integer stored by key and returns result, missing key is created with given ttl
+func (s *Server) incr(db *database, req *godis_proto.Request) *godis_proto.Response {
+	n, err := strconv.ParseInt(req.GetValue().GetStringVal(), 10, 64)
+	if err != nil {
+		return getErrorResponse("increment is not an integer")
+	}
+	ttl := req.GetValue().GetTtl()
+	delta := &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: strconv.FormatInt(n, 10)}, Ttl: ttl}
+	var result int64
+	_, err = s.mutate(db, req.GetKey(), wal.Incr, delta, func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		v, sum, err := increment(old, n, ttl)
+		result = sum
+		return v, err
+	})
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getCountResponse(result)
+}
+
+// withTTL returns copy of value with new ttl
+func withTTL(old *godis_proto.Value, ttl int64) (*godis_proto.Value, error) {
+	if old == nil {
+		return nil, errNotChanged
+	}
+	return &godis_proto.Value{Value: old.GetValue(), Ttl: ttl}, nil
+}
+
+// setFields returns copy of map with fields set and amount of added fields, ttl is used for new map
+func setFields(old *godis_proto.Value, fields map[string]string, ttl int64) (*godis_proto.Value, int, error) {
+	m := map[string]string{}
+	if old != nil {
+		if _, ok := old.GetValue().(*godis_proto.Value_StringMap); !ok {
+			return nil, 0, badKeyType(old)
+		}
+		ttl = old.GetTtl()
+		m = make(map[string]string, len(old.GetStringMap().GetStringMap())+len(fields))
+		for k, v := range old.GetStringMap().GetStringMap() {
+			m[k] = v
+		}
+	}
+	added := 0
+	for k, v := range fields {
+		if _, ok := m[k]; !ok {
+			added++
+		}
+		m[k] = v
+	}
+	return newStringMap(m, ttl), added, nil
+}
+
+// deleteFields returns copy of map without fields and amount of deleted fields, empty map is deleted
+func deleteFields(old *godis_proto.Value, fields []string) (*godis_proto.Value, int, error) {
+	if old == nil {
+		return nil, 0, errNotChanged
+	}
+	if _, ok := old.GetValue().(*godis_proto.Value_StringMap); !ok {
+		return nil, 0, badKeyType(old)
+	}
+	m := make(map[string]string, len(old.GetStringMap().GetStringMap()))
+	for k, v := range old.GetStringMap().GetStringMap() {
+		m[k] = v
+	}
+	deleted := 0
+	for _, k := range fields {
+		if _, ok := m[k]; ok {
+			deleted++
+			delete(m, k)
+		}
+	}
+	switch {
+	case deleted == 0:
+		return nil, 0, errNotChanged
+	case len(m) == 0:
+		return nil, deleted, nil
+	}
+	return newStringMap(m, old.GetTtl()), deleted, nil
+}
+
+// increment returns string value with integer increased by n and the result, missing value is zero
+func increment(old *godis_proto.Value, n int64, ttl int64) (*godis_proto.Value, int64, error) {
+	current := int64(0)
+	if old != nil {
+		t, ok := old.GetValue().(*godis_proto.Value_StringVal)
+		if !ok {
+			return nil, 0, badKeyType(old)
+		}
+		var err error
+		if current, err = strconv.ParseInt(t.StringVal, 10, 64); err != nil {
+			return nil, 0, errors.New("value is not an integer")
+		}
+		ttl = old.GetTtl()
+	}
+	sum := current + n
+	if (n > 0 && sum < current) || (n < 0 && sum > current) {
+		return nil, 0, errors.New("increment overflows value")
+	}
+	return &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: strconv.FormatInt(sum, 10)}, Ttl: ttl}, sum, nil
+}
+
+func newStringMap(m map[string]string, ttl int64) *godis_proto.Value {
+	return &godis_proto.Value{
+		Value: &godis_proto.Value_StringMap{StringMap: &godis_proto.MapString{StringMap: m}},
+		Ttl:   ttl,
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/wal"
+)
+
+var errDurabilityDisabled = errors.New("durable request isn't possible: wal is disabled")
+
+// handleDurableRequest handles request and, if request is durable or wal is synchronous, waits until its writes
+// are synced. Error is returned if they can't be synced, although request is already applied in memory
+func (s *Server) handleDurableRequest(c *connection, req *godis_proto.Request) *godis_proto.Response {
+	if !req.GetDurable() && !s.durability.Synchronous() {
+		return s.handleRequest(c, req)
+	}
+	if s.durability == wal.ModeNone {
+		return getErrorResponse(errDurabilityDisabled.Error())
+	}
+	resp := s.handleRequest(c, req)
+	if resp.GetError() != nil {
+		return resp
+	}
+	if err := s.syncWAL(); err != nil {
+		return getErrorResponse("write isn't durable: " + err.Error())
+	}
+	return resp
+}
+
+// syncWAL waits until all records written to wal are synced
+func (s *Server) syncWAL() error {
+	if s.durability == wal.ModeNone {
+		return errDurabilityDisabled
+	}
+	return s.wal.Sync()
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/wire"
+)
+
+var errTooManyKeys = limitError{errors.New("too many keys match pattern")}
+
+// limitError is returned when request exceeds limits
+type limitError struct {
+	error
+}
+
+// Limits protect server from huge requests, zero field means no limit
+type Limits struct {
+	// MaxFrameSize - max size of encoded request, connection is closed when client sends larger frame
+	MaxFrameSize uint32
+	// MaxKeyLength - max length of keys used by request
+	MaxKeyLength int
+	// MaxValueSize - max size of value, payload and script arguments of request
+	MaxValueSize int
+	// MaxElements - max amount of elements in slice or map, it's checked for requests and for slices grown by push
+	MaxElements int
+	// MaxKeysResult - max amount of keys returned by Keys, request matching more keys is rejected
+	MaxKeysResult int
+}
+
+// DefaultLimits returns limits used by server by default
+func DefaultLimits() Limits {
+	return Limits{
+		MaxFrameSize:  wire.DefaultMaxFrameSize,
+		MaxKeyLength:  64 << 10,
+		MaxValueSize:  32 << 20,
+		MaxElements:   1 << 20,
+		MaxKeysResult: 1 << 20,
+	}
+}
+
+// check returns error if request exceeds limits
+func (l Limits) check(req *godis_proto.Request) error {
+	if l.MaxKeyLength > 0 {
+		keys := append([]string{req.GetKey(), req.GetEndKey(), req.GetMapKey()}, req.GetKeys()...)
+		for _, key := range keys {
+			if len(key) > l.MaxKeyLength {
+				return limitError{fmt.Errorf("key is longer than %d bytes", l.MaxKeyLength)}
+			}
+		}
+	}
+	if l.MaxValueSize > 0 && requestValueSize(req) > l.MaxValueSize {
+		return limitError{fmt.Errorf("value is larger than %d bytes", l.MaxValueSize)}
+	}
+	if l.MaxElements > 0 {
+		elements := len(req.GetValue().GetStringSlice().GetStringArrayVal()) + len(req.GetValue().GetStringMap().GetStringMap())
+		if elements > l.MaxElements {
+			return l.errTooManyElements()
+		}
+	}
+	return nil
+}
+
+func (l Limits) errTooManyElements() error {
+	return limitError{fmt.Errorf("value has more than %d elements", l.MaxElements)}
+}
+
+// getLimitErrorResponse returns response with LimitExceeded code for limit errors
+func getLimitErrorResponse(err error) *godis_proto.Response {
+	if _, ok := err.(limitError); ok {
+		return getCodeErrorResponse(godis_proto.ErrorCode_LimitExceeded, err.Error())
+	}
+	return getErrorResponse(err.Error())
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/wal"
+)
+
+var errListEmpty = errors.New("list is empty")
+
+func badKeyType(v *godis_proto.Value) error {
+	return fmt.Errorf("bad key type: %T", v.GetValue())
+}
+
+// push adds elements to the head (left) or the tail of slice stored by key and returns new length
+func (s *Server) push(db *database, req *godis_proto.Request, left bool) *godis_proto.Response {
+	elements := req.GetValue().GetStringSlice().GetStringArrayVal()
+	if len(elements) == 0 {
+		return getErrorResponse("no elements to push")
+	}
+
+	cmd := wal.PushRight
+	if left {
+		cmd = wal.PushLeft
+	}
+	ttl := req.GetValue().GetTtl()
+	v, err := s.mutate(db, req.GetKey(), cmd, newStringSlice(elements, ttl), func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		v, err := pushElements(old, elements, ttl, left)
+		if err != nil {
+			return nil, err
+		}
+		if s.limits.MaxElements > 0 && len(v.GetStringSlice().GetStringArrayVal()) > s.limits.MaxElements {
+			return nil, s.limits.errTooManyElements()
+		}
+		return v, nil
+	})
+	if err != nil {
+		return getLimitErrorResponse(err)
+	}
+	db.blocking.signal(req.GetKey())
+	return getCountResponse(int64(len(v.GetStringSlice().GetStringArrayVal())))
+}
+
+// pop removes and returns first (left) or last element of slice stored by key, empty slice is deleted
+func (s *Server) pop(db *database, key string, left bool) (string, error) {
+	cmd := wal.PopRight
+	if left {
+		cmd = wal.PopLeft
+	}
+	var element string
+	_, err := s.mutate(db, key, cmd, nil, func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		v, e, err := popElement(old, left)
+		element = e
+		return v, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return element, nil
+}
+
+// pushElements returns copy of slice with elements added to its head (left) or tail, ttl is used for new slice.
+// Elements are added to the head one after another, so the last element becomes the first
+func pushElements(old *godis_proto.Value, elements []string, ttl int64, left bool) (*godis_proto.Value, error) {
+	if old == nil {
+		old = &godis_proto.Value{Ttl: ttl}
+	} else if _, ok := old.GetValue().(*godis_proto.Value_StringSlice); !ok {
+		return nil, badKeyType(old)
+	}
+	arr := old.GetStringSlice().GetStringArrayVal()
+	newArr := make([]string, 0, len(arr)+len(elements))
+	if left {
+		for i := len(elements) - 1; i >= 0; i-- {
+			newArr = append(newArr, elements[i])
+		}
+		newArr = append(newArr, arr...)
+	} else {
+		newArr = append(newArr, arr...)
+		newArr = append(newArr, elements...)
+	}
+	return newStringSlice(newArr, old.GetTtl()), nil
+}
+
+// popElement returns copy of slice without first (left) or last element and the element, empty slice is deleted
+func popElement(old *godis_proto.Value, left bool) (*godis_proto.Value, string, error) {
+	if old == nil {
+		return nil, "", errListEmpty
+	}
+	if _, ok := old.GetValue().(*godis_proto.Value_StringSlice); !ok {
+		return nil, "", badKeyType(old)
+	}
+	arr := old.GetStringSlice().GetStringArrayVal()
+	if len(arr) == 0 {
+		return nil, "", errListEmpty
+	}
+	var element string
+	if left {
+		element, arr = arr[0], arr[1:]
+	} else {
+		element, arr = arr[len(arr)-1], arr[:len(arr)-1]
+	}
+	if len(arr) == 0 {
+		return nil, element, nil
+	}
+	return newStringSlice(append([]string(nil), arr...), old.GetTtl()), element, nil
+}
+
+// blockingPop pops element from the first non empty slice of keys,
+// if all of them are empty it waits until element is pushed by another connection
+func (s *Server) blockingPop(db *database, c *connection, req *godis_proto.Request, left bool) *godis_proto.Response {
+	keys := req.GetKeys()
+	if len(keys) == 0 {
+		return getErrorResponse("no keys given")
+	}
+
+	var popErr error
+	try := func(key string) (*godis_proto.Response, bool) {
+		element, err := s.pop(db, key, left)
+		if err != nil {
+			if err != errListEmpty {
+				popErr = err
+				return nil, true
+			}
+			return nil, false
+		}
+		return getKeyValueResponse(key, &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: element}}), true
+	}
+
+	cancel, stop := s.watchCancel(c)
+	resp := db.blocking.wait(keys, time.Duration(req.GetTimeout()), cancel, try)
+	stop()
+	if popErr != nil {
+		return getErrorResponse(popErr.Error())
+	}
+	if resp == nil {
+		// timeout
+		return &godis_proto.Response{ResponseValue: &godis_proto.Response_KeyValues{KeyValues: &godis_proto.KeyValueList{}}}
+	}
+	return resp
+}
+
+func newStringSlice(arr []string, ttl int64) *godis_proto.Value {
+	return &godis_proto.Value{
+		Value: &godis_proto.Value_StringSlice{StringSlice: &godis_proto.RepeatedString{StringArrayVal: arr}},
+		Ttl:   ttl,
+	}
+}
+
+func getKeyValueResponse(key string, v *godis_proto.Value) *godis_proto.Response {
+	return &godis_proto.Response{
+		ResponseValue: &godis_proto.Response_KeyValues{KeyValues: &godis_proto.KeyValueList{
+			Items: []*godis_proto.KeyValue{{Key: key, Value: v}},
+		}},
+	}
+}
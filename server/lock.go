@@ -0,0 +1,123 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+var (
+	errEmptyOwner = errors.New("lock owner must be set")
+	errBadLease   = errors.New("lease must be positive")
+)
+
+// getLock returns lock stored in value, expired lease is treated as free lock.
+// Lock keys never expire, so fencing token keeps increasing after lock is released
+func getLock(v *godis_proto.Value, now int64) (*godis_proto.Lock, error) {
+	if v == nil {
+		return &godis_proto.Lock{}, nil
+	}
+	t, ok := v.GetValue().(*godis_proto.Value_Lock)
+	if !ok {
+		return nil, badKeyType(v)
+	}
+	lock := t.Lock
+	if lock.GetOwner() != "" && lock.GetExpiresAt() <= now {
+		lock = &godis_proto.Lock{Token: lock.GetToken()}
+	}
+	return lock, nil
+}
+
+func newLockValue(lock *godis_proto.Lock) *godis_proto.Value {
+	return &godis_proto.Value{Value: &godis_proto.Value_Lock{Lock: lock}, Ttl: math.MaxInt64}
+}
+
+// lockAcquire takes free lock for owner and increases fencing token.
+// Acquire of lock already held by the same owner extends lease and keeps token
+func (s *Server) lockAcquire(db *database, req *godis_proto.Request) *godis_proto.Response {
+	if req.GetLease() <= 0 {
+		return getErrorResponse(errBadLease.Error())
+	}
+	lock, _, err := s.updateLock(db, req, func(lock *godis_proto.Lock, now int64) *godis_proto.Lock {
+		switch lock.GetOwner() {
+		case "":
+			return &godis_proto.Lock{Owner: req.GetOwner(), Token: lock.GetToken() + 1, ExpiresAt: now + req.GetLease()}
+		case req.GetOwner():
+			return &godis_proto.Lock{Owner: req.GetOwner(), Token: lock.GetToken(), ExpiresAt: now + req.GetLease()}
+		}
+		return nil
+	})
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getLockResponse(lock)
+}
+
+// lockRenew extends lease of lock held by owner
+func (s *Server) lockRenew(db *database, req *godis_proto.Request) *godis_proto.Response {
+	if req.GetLease() <= 0 {
+		return getErrorResponse(errBadLease.Error())
+	}
+	lock, _, err := s.updateLock(db, req, func(lock *godis_proto.Lock, now int64) *godis_proto.Lock {
+		if lock.GetOwner() != req.GetOwner() {
+			return nil
+		}
+		return &godis_proto.Lock{Owner: req.GetOwner(), Token: lock.GetToken(), ExpiresAt: now + req.GetLease()}
+	})
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getLockResponse(lock)
+}
+
+// lockRelease frees lock held by owner, response count is 1 if lock was released
+func (s *Server) lockRelease(db *database, req *godis_proto.Request) *godis_proto.Response {
+	_, released, err := s.updateLock(db, req, func(lock *godis_proto.Lock, _ int64) *godis_proto.Lock {
+		if lock.GetOwner() != req.GetOwner() {
+			return nil
+		}
+		return &godis_proto.Lock{Token: lock.GetToken()}
+	})
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	if released {
+		return getCountResponse(1)
+	}
+	return getCountResponse(0)
+}
+
+// updateLock atomically replaces lock with one returned by fn, nil means that lock isn't changed.
+// It returns state of lock after update
+func (s *Server) updateLock(db *database, req *godis_proto.Request, fn func(lock *godis_proto.Lock, now int64) *godis_proto.Lock) (*godis_proto.Lock, bool, error) {
+	if req.GetOwner() == "" {
+		return nil, false, errEmptyOwner
+	}
+
+	var lock *godis_proto.Lock
+	changed := false
+	_, err := s.update(db, req.GetKey(), func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		now := time.Now().UnixNano()
+		current, err := getLock(old, now)
+		if err != nil {
+			return nil, err
+		}
+		lock = fn(current, now)
+		changed = lock != nil
+		if !changed {
+			lock = current
+			return nil, errNotChanged
+		}
+		return newLockValue(lock), nil
+	})
+	if err != nil && err != errNotChanged {
+		return nil, false, err
+	}
+	return lock, changed, nil
+}
+
+func getLockResponse(lock *godis_proto.Lock) *godis_proto.Response {
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Lock{Lock: lock}}
+}
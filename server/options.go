@@ -0,0 +1,150 @@
+package server
+
+import (
+	"time"
+
+	"github.com/minaevmike/godis/storage"
+	"github.com/minaevmike/godis/wal"
+)
+
+const (
+	defaultExpireInterval = 100 * time.Millisecond
+	defaultWALFile        = "./godis.wal"
+	defaultSnapshotFile   = "./godis.snapshot"
+	// defaultSnapshotInterval - how often snapshot is written by default
+	defaultSnapshotInterval = time.Hour
+)
+
+// Option configures Server
+type Option func(s *Server)
+
+// WithStorage sets storage of database 0, other databases use storage created by storage factory.
+// Range queries are supported only by storages which implement storage.Ranger
+func WithStorage(st storage.Storage) Option {
+	return func(s *Server) {
+		s.storage = st
+	}
+}
+
+// WithStorageFactory sets function creating storage for every database, by default sharded map storage is used
+func WithStorageFactory(factory func() storage.Storage) Option {
+	return func(s *Server) {
+		s.storageFactory = factory
+	}
+}
+
+// WithDatabases sets amount of numbered databases, 16 by default
+func WithDatabases(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.databasesCount = n
+		}
+	}
+}
+
+// WithDatabaseQuota sets quota of database with given index
+func WithDatabaseQuota(index int, q Quota) Option {
+	return func(s *Server) {
+		s.databaseQuotas[index] = q
+	}
+}
+
+// WithClientQuota sets quota applied to every client, requests of all connections made from the same host
+// share one quota. Only ops and value size limits are applied to clients
+func WithClientQuota(q Quota) Option {
+	return func(s *Server) {
+		s.clientQuotas = newClientQuotas(q)
+	}
+}
+
+// WithLimits sets limits of requests, DefaultLimits are used by default
+func WithLimits(l Limits) Option {
+	return func(s *Server) {
+		s.limits = l
+	}
+}
+
+// WithConnectionLimits sets timeouts and limits of client connections, DefaultConnectionLimits are used by default
+func WithConnectionLimits(l ConnectionLimits) Option {
+	return func(s *Server) {
+		s.connections = newConnectionTracker(l)
+	}
+}
+
+// WithExpireInterval sets how often server deletes expired keys, zero disables active expiration
+// and keys are deleted only on access
+func WithExpireInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.expireInterval = interval
+	}
+}
+
+// WithWALFile sets path of write ahead log file, by default ./godis.wal is used
+func WithWALFile(file string) Option {
+	return func(s *Server) {
+		s.walFile = file
+	}
+}
+
+// WithSnapshotFile sets path of snapshot, by default ./godis.snapshot is used. Empty path disables snapshots,
+// they are disabled in wal.ModeNone as well
+func WithSnapshotFile(file string) Option {
+	return func(s *Server) {
+		s.snapshotFile = file
+	}
+}
+
+// WithSnapshotInterval sets how often snapshot is written, zero disables scheduled snapshots,
+// snapshot is still written at shutdown and by Snapshot request
+func WithSnapshotInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.snapshotInterval = interval
+	}
+}
+
+// WithBackupDir sets directory where server writes backups requested with path, by default such backups
+// are disabled and backups are only streamed to client
+func WithBackupDir(dir string) Option {
+	return func(s *Server) {
+		s.backupDir = dir
+	}
+}
+
+// WithEncryption makes server encrypt wal records and snapshots with c, usually encryption.Keyring.
+// Data written without encryption is still read, encrypted data can't be read without keys it's sealed with
+func WithEncryption(c wal.Cipher) Option {
+	return func(s *Server) {
+		s.cipher = c
+	}
+}
+
+// WithReplicaOf makes server read-only replica of primary. Replica receives all data of primary and then
+// its wal records, it keeps data only in memory, so wal and snapshot options aren't used
+func WithReplicaOf(opts ReplicaOptions) Option {
+	return func(s *Server) {
+		s.replica = newReplicaState(opts)
+	}
+}
+
+// WithDurability sets durability mode of write ahead log, wal.ModeInterval is used by default.
+// In synchronous modes response is sent after writes of request are synced
+func WithDurability(mode wal.Mode) Option {
+	return func(s *Server) {
+		s.durability = mode
+	}
+}
+
+// WithWALOptions sets flush interval, batch size and buffer limit of write ahead log in interval
+// and group commit modes, wal.DefaultOptions are used by default
+func WithWALOptions(opts wal.Options) Option {
+	return func(s *Server) {
+		s.walOptions = opts
+	}
+}
+
+// WithScriptTimeout sets default max execution time of scripts, requests can set their own limit
+func WithScriptTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.scriptTimeout = timeout
+	}
+}
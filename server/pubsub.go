@@ -0,0 +1,289 @@
+package server
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+// subscriberBufferSize is amount of messages which can wait for delivery to subscriber,
+// when buffer is full subscriber is considered slow and its connection is closed
+const subscriberBufferSize = 1024
+
+type subscriber struct {
+	messages     chan *godis_proto.Message
+	overflow     chan struct{}
+	overflowOnce sync.Once
+	done         chan struct{}
+
+	// channels, patterns and keyspace are guarded by pubSub mutex
+	channels map[string]struct{}
+	patterns map[string]struct{}
+	keyspace map[keyspacePattern]struct{}
+}
+
+func newSubscriber(bufferSize int) *subscriber {
+	return &subscriber{
+		messages: make(chan *godis_proto.Message, bufferSize),
+		overflow: make(chan struct{}),
+		done:     make(chan struct{}),
+		channels: make(map[string]struct{}),
+		patterns: make(map[string]struct{}),
+		keyspace: make(map[keyspacePattern]struct{}),
+	}
+}
+
+// send delivers message without blocking publisher, returns false if subscriber buffer is full
+func (s *subscriber) send(msg *godis_proto.Message) bool {
+	select {
+	case s.messages <- msg:
+		return true
+	default:
+		s.overflowOnce.Do(func() { close(s.overflow) })
+		return false
+	}
+}
+
+func (s *subscriber) close() {
+	close(s.done)
+}
+
+type patternSubscribers struct {
+	re          *regexp.Regexp
+	subscribers map[*subscriber]struct{}
+}
+
+// eventMask is set of event types, zero mask contains all events
+type eventMask uint32
+
+func newEventMask(events []godis_proto.EventType) eventMask {
+	var mask eventMask
+	for _, event := range events {
+		mask |= 1 << uint(event)
+	}
+	return mask
+}
+
+func (m eventMask) contains(event godis_proto.EventType) bool {
+	return m == 0 || m&(1<<uint(event)) != 0
+}
+
+// keyspacePattern is key pattern in one database
+type keyspacePattern struct {
+	db      int
+	pattern string
+}
+
+type keyspaceSubscribers struct {
+	re          *regexp.Regexp
+	subscribers map[*subscriber]eventMask
+}
+
+// pubSub routes published messages to subscribers of channels and patterns.
+// Patterns use go regexp syntax as Keys does
+type pubSub struct {
+	mu       sync.RWMutex
+	channels map[string]map[*subscriber]struct{}
+	patterns map[string]*patternSubscribers
+	keyspace map[keyspacePattern]*keyspaceSubscribers
+}
+
+func newPubSub() *pubSub {
+	return &pubSub{
+		channels: make(map[string]map[*subscriber]struct{}),
+		patterns: make(map[string]*patternSubscribers),
+		keyspace: make(map[keyspacePattern]*keyspaceSubscribers),
+	}
+}
+
+// publish sends message to all subscribers of channel, returns amount of subscribers which received message
+func (ps *pubSub) publish(channel string, payload []byte) int {
+	received := 0
+	ps.mu.RLock()
+	if subs, ok := ps.channels[channel]; ok {
+		msg := &godis_proto.Message{Channel: channel, Payload: payload}
+		for sub := range subs {
+			if sub.send(msg) {
+				received++
+			}
+		}
+	}
+	for pattern, p := range ps.patterns {
+		if !p.re.MatchString(channel) {
+			continue
+		}
+		msg := &godis_proto.Message{Channel: channel, Pattern: pattern, Payload: payload}
+		for sub := range p.subscribers {
+			if sub.send(msg) {
+				received++
+			}
+		}
+	}
+	ps.mu.RUnlock()
+	return received
+}
+
+// notify sends keyspace notification to subscribers of keys of database db matching to key
+func (ps *pubSub) notify(db int, key string, event godis_proto.EventType) {
+	ps.mu.RLock()
+	for kp, k := range ps.keyspace {
+		if kp.db != db || !k.re.MatchString(key) {
+			continue
+		}
+		msg := &godis_proto.Message{Pattern: kp.pattern, Key: key, Event: event, Database: uint32(db)}
+		for sub, mask := range k.subscribers {
+			if mask.contains(event) {
+				sub.send(msg)
+			}
+		}
+	}
+	ps.mu.RUnlock()
+}
+
+func (ps *pubSub) subscribe(sub *subscriber, channels ...string) {
+	ps.mu.Lock()
+	for _, channel := range channels {
+		subs, ok := ps.channels[channel]
+		if !ok {
+			subs = make(map[*subscriber]struct{})
+			ps.channels[channel] = subs
+		}
+		subs[sub] = struct{}{}
+		sub.channels[channel] = struct{}{}
+	}
+	ps.mu.Unlock()
+}
+
+func (ps *pubSub) psubscribe(sub *subscriber, patterns ...string) error {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		compiled = append(compiled, re)
+	}
+
+	ps.mu.Lock()
+	for i, pattern := range patterns {
+		p, ok := ps.patterns[pattern]
+		if !ok {
+			p = &patternSubscribers{re: compiled[i], subscribers: make(map[*subscriber]struct{})}
+			ps.patterns[pattern] = p
+		}
+		p.subscribers[sub] = struct{}{}
+		sub.patterns[pattern] = struct{}{}
+	}
+	ps.mu.Unlock()
+	return nil
+}
+
+// subscribeKeyspace subscribes to events of keys of database db matching to pattern,
+// repeated subscription to the same pattern replaces events
+func (ps *pubSub) subscribeKeyspace(sub *subscriber, db int, pattern string, events []godis_proto.EventType) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	kp := keyspacePattern{db: db, pattern: pattern}
+	ps.mu.Lock()
+	k, ok := ps.keyspace[kp]
+	if !ok {
+		k = &keyspaceSubscribers{re: re, subscribers: make(map[*subscriber]eventMask)}
+		ps.keyspace[kp] = k
+	}
+	k.subscribers[sub] = newEventMask(events)
+	sub.keyspace[kp] = struct{}{}
+	ps.mu.Unlock()
+	return nil
+}
+
+// unsubscribeKeyspace removes subscriber from given key patterns of database db,
+// if no patterns given subscriber is removed from all of them in all databases
+func (ps *pubSub) unsubscribeKeyspace(sub *subscriber, db int, patterns ...string) {
+	ps.mu.Lock()
+	kps := make([]keyspacePattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		kps = append(kps, keyspacePattern{db: db, pattern: pattern})
+	}
+	if len(kps) == 0 {
+		for kp := range sub.keyspace {
+			kps = append(kps, kp)
+		}
+	}
+	for _, kp := range kps {
+		if k, ok := ps.keyspace[kp]; ok {
+			delete(k.subscribers, sub)
+			if len(k.subscribers) == 0 {
+				delete(ps.keyspace, kp)
+			}
+		}
+		delete(sub.keyspace, kp)
+	}
+	ps.mu.Unlock()
+}
+
+// unsubscribe removes subscriber from given channels, if no channels given subscriber is removed from all channels
+func (ps *pubSub) unsubscribe(sub *subscriber, channels ...string) {
+	ps.mu.Lock()
+	if len(channels) == 0 {
+		for channel := range sub.channels {
+			channels = append(channels, channel)
+		}
+	}
+	for _, channel := range channels {
+		if subs, ok := ps.channels[channel]; ok {
+			delete(subs, sub)
+			if len(subs) == 0 {
+				delete(ps.channels, channel)
+			}
+		}
+		delete(sub.channels, channel)
+	}
+	ps.mu.Unlock()
+}
+
+// punsubscribe removes subscriber from given patterns, if no patterns given subscriber is removed from all patterns
+func (ps *pubSub) punsubscribe(sub *subscriber, patterns ...string) {
+	ps.mu.Lock()
+	if len(patterns) == 0 {
+		for pattern := range sub.patterns {
+			patterns = append(patterns, pattern)
+		}
+	}
+	for _, pattern := range patterns {
+		if p, ok := ps.patterns[pattern]; ok {
+			delete(p.subscribers, sub)
+			if len(p.subscribers) == 0 {
+				delete(ps.patterns, pattern)
+			}
+		}
+		delete(sub.patterns, pattern)
+	}
+	ps.mu.Unlock()
+}
+
+// count returns amount of channels and patterns subscriber is subscribed to
+func (ps *pubSub) count(sub *subscriber) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return len(sub.channels) + len(sub.patterns) + len(sub.keyspace)
+}
+
+func (ps *pubSub) unsubscribeAll(sub *subscriber) {
+	ps.unsubscribe(sub)
+	ps.punsubscribe(sub)
+	ps.unsubscribeKeyspace(sub, 0)
+}
+
+func isSubscribeOperation(op godis_proto.Operation) bool {
+	switch op {
+	case godis_proto.Operation_Subscribe, godis_proto.Operation_PSubscribe,
+		godis_proto.Operation_Unsubscribe, godis_proto.Operation_PUnsubscribe,
+		godis_proto.Operation_SubscribeKeyspace, godis_proto.Operation_UnsubscribeKeyspace:
+		return true
+	}
+	return false
+}
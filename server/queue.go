@@ -0,0 +1,292 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+// defaultVisibilityTimeout is used when dequeue request doesn't set visibility timeout
+const defaultVisibilityTimeout = 30 * time.Second
+
+var errEmptyJob = errors.New("job payload is empty")
+
+// getQueue returns queue stored in value, nil value is treated as empty queue
+func getQueue(v *godis_proto.Value) (*godis_proto.Queue, error) {
+	if v == nil {
+		return &godis_proto.Queue{}, nil
+	}
+	t, ok := v.GetValue().(*godis_proto.Value_Queue)
+	if !ok {
+		return nil, badKeyType(v)
+	}
+	return t.Queue, nil
+}
+
+// newQueueValue wraps queue into value, queues never expire
+func newQueueValue(queue *godis_proto.Queue) *godis_proto.Value {
+	return &godis_proto.Value{Value: &godis_proto.Value_Queue{Queue: queue}, Ttl: math.MaxInt64}
+}
+
+// copyJob returns copy of job, stored jobs are never modified
+func copyJob(job *godis_proto.Job) *godis_proto.Job {
+	return &godis_proto.Job{
+		Id:          job.GetId(),
+		Payload:     job.GetPayload(),
+		VisibleAt:   job.GetVisibleAt(),
+		Attempts:    job.GetAttempts(),
+		MaxAttempts: job.GetMaxAttempts(),
+		EnqueuedAt:  job.GetEnqueuedAt(),
+	}
+}
+
+// insertWaiting returns copy of waiting jobs with job inserted in visible_at order,
+// jobs with same visible_at keep order they were added in
+func insertWaiting(waiting []*godis_proto.Job, job *godis_proto.Job) []*godis_proto.Job {
+	i := sort.Search(len(waiting), func(i int) bool {
+		return waiting[i].GetVisibleAt() > job.GetVisibleAt()
+	})
+	result := make([]*godis_proto.Job, 0, len(waiting)+1)
+	result = append(result, waiting[:i]...)
+	result = append(result, job)
+	return append(result, waiting[i:]...)
+}
+
+// retry returns failed job back to waiting jobs or moves it to dead letters if it has no attempts left
+func retry(queue *godis_proto.Queue, job *godis_proto.Job, visibleAt int64) {
+	job = copyJob(job)
+	if job.MaxAttempts > 0 && job.Attempts >= job.MaxAttempts {
+		queue.Dead = append(queue.Dead[:len(queue.Dead):len(queue.Dead)], job)
+		return
+	}
+	job.VisibleAt = visibleAt
+	queue.Waiting = insertWaiting(queue.Waiting, job)
+}
+
+// requeueExpired returns copy of queue where in flight jobs with expired visibility timeout are retried
+func requeueExpired(queue *godis_proto.Queue, now int64) *godis_proto.Queue {
+	result := &godis_proto.Queue{
+		Waiting: queue.GetWaiting(),
+		Dead:    queue.GetDead(),
+		LastId:  queue.GetLastId(),
+	}
+	for _, job := range queue.GetInFlight() {
+		if job.GetVisibleAt() > now {
+			result.InFlight = append(result.InFlight, job)
+			continue
+		}
+		retry(result, job, now)
+	}
+	return result
+}
+
+func (s *Server) queueEnqueue(db *database, req *godis_proto.Request) *godis_proto.Response {
+	if len(req.GetPayload()) == 0 {
+		return getErrorResponse(errEmptyJob.Error())
+	}
+
+	var id string
+	_, err := s.update(db, req.GetKey(), func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		queue, err := getQueue(old)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now().UnixNano()
+		queue = requeueExpired(queue, now)
+		queue.LastId++
+		id = strconv.FormatUint(queue.LastId, 10)
+		queue.Waiting = insertWaiting(queue.Waiting, &godis_proto.Job{
+			Id:          id,
+			Payload:     req.GetPayload(),
+			VisibleAt:   now + req.GetDelay(),
+			MaxAttempts: req.GetMaxAttempts(),
+			EnqueuedAt:  now,
+		})
+		return newQueueValue(queue), nil
+	})
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getStringResponse(id)
+}
+
+// queueDequeue delivers up to limit ready jobs, they are hidden from other workers until visibility timeout
+// is reached, after that they are delivered again unless they are acknowledged
+func (s *Server) queueDequeue(db *database, req *godis_proto.Request) *godis_proto.Response {
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = 1
+	}
+	visibilityTimeout := req.GetVisibilityTimeout()
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = defaultVisibilityTimeout.Nanoseconds()
+	}
+
+	var jobs []*godis_proto.Job
+	_, err := s.update(db, req.GetKey(), func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		if old == nil {
+			return nil, errNotChanged
+		}
+		queue, err := getQueue(old)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now().UnixNano()
+		inFlight := len(queue.GetInFlight())
+		queue = requeueExpired(queue, now)
+		requeued := inFlight != len(queue.InFlight)
+
+		jobs = nil
+		i := 0
+		for ; i < len(queue.Waiting) && len(jobs) < limit && queue.Waiting[i].GetVisibleAt() <= now; i++ {
+			job := copyJob(queue.Waiting[i])
+			job.Attempts++
+			job.VisibleAt = now + visibilityTimeout
+			jobs = append(jobs, job)
+		}
+		if len(jobs) == 0 && !requeued {
+			// idle workers poll queue, so nothing is written when nothing is delivered
+			return nil, errNotChanged
+		}
+		queue.Waiting = queue.Waiting[i:]
+		delivered := make([]*godis_proto.Job, 0, len(queue.InFlight)+len(jobs))
+		delivered = append(delivered, queue.InFlight...)
+		queue.InFlight = append(delivered, jobs...)
+		return newQueueValue(queue), nil
+	})
+	if err == errNotChanged {
+		return getJobsResponse(nil)
+	}
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getJobsResponse(jobs)
+}
+
+// queueAck removes delivered jobs from queue, it returns amount of acknowledged jobs.
+// Jobs which visibility timeout expired are delivered again and can't be acknowledged
+func (s *Server) queueAck(db *database, req *godis_proto.Request) *godis_proto.Response {
+	return s.queueFinish(db, req, false)
+}
+
+// queueNack returns delivered jobs back to queue after delay, jobs without attempts left are moved to dead letters.
+// Dead jobs are requeued with reset attempts, it returns amount of found jobs
+func (s *Server) queueNack(db *database, req *godis_proto.Request) *godis_proto.Response {
+	return s.queueFinish(db, req, true)
+}
+
+func (s *Server) queueFinish(db *database, req *godis_proto.Request, nack bool) *godis_proto.Response {
+	ids := make(map[string]struct{}, len(req.GetIds()))
+	for _, id := range req.GetIds() {
+		ids[id] = struct{}{}
+	}
+
+	count := 0
+	_, err := s.update(db, req.GetKey(), func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		if old == nil {
+			return nil, errNotChanged
+		}
+		queue, err := getQueue(old)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now().UnixNano()
+		inFlight := len(queue.GetInFlight())
+		queue = requeueExpired(queue, now)
+		requeued := inFlight != len(queue.InFlight)
+
+		count = 0
+		if nack {
+			// dead jobs are requeued before in flight ones are nacked, so job isn't requeued right after it died
+			dead := queue.Dead
+			queue.Dead = nil
+			for _, job := range dead {
+				if _, ok := ids[job.GetId()]; !ok {
+					queue.Dead = append(queue.Dead, job)
+					continue
+				}
+				count++
+				job = copyJob(job)
+				job.Attempts = 0
+				job.VisibleAt = now + req.GetDelay()
+				queue.Waiting = insertWaiting(queue.Waiting, job)
+			}
+		}
+		delivered := queue.InFlight
+		queue.InFlight = nil
+		for _, job := range delivered {
+			if _, ok := ids[job.GetId()]; !ok {
+				queue.InFlight = append(queue.InFlight, job)
+				continue
+			}
+			count++
+			if nack {
+				retry(queue, job, now+req.GetDelay())
+			}
+		}
+		if count == 0 && !requeued {
+			return nil, errNotChanged
+		}
+		return newQueueValue(queue), nil
+	})
+	if err == errNotChanged {
+		return getCountResponse(0)
+	}
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getCountResponse(int64(count))
+}
+
+func (s *Server) queueStats(db *database, req *godis_proto.Request) *godis_proto.Response {
+	queue, err := s.getQueue(db, req.GetKey())
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	now := time.Now().UnixNano()
+	queue = requeueExpired(queue, now)
+
+	stats := &godis_proto.QueueStats{
+		InFlight: int64(len(queue.GetInFlight())),
+		Dead:     int64(len(queue.GetDead())),
+	}
+	for _, job := range queue.GetWaiting() {
+		if job.GetVisibleAt() <= now {
+			stats.Ready++
+		} else {
+			stats.Delayed++
+		}
+	}
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_QueueStats{QueueStats: stats}}
+}
+
+// queueDead returns up to limit dead jobs, zero limit means all of them
+func (s *Server) queueDead(db *database, req *godis_proto.Request) *godis_proto.Response {
+	queue, err := s.getQueue(db, req.GetKey())
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	queue = requeueExpired(queue, time.Now().UnixNano())
+	dead := queue.GetDead()
+	if limit := int(req.GetLimit()); limit > 0 && len(dead) > limit {
+		dead = dead[:limit]
+	}
+	return getJobsResponse(dead)
+}
+
+// getQueue returns queue stored by key, missing key means empty queue
+func (s *Server) getQueue(db *database, key string) (*godis_proto.Queue, error) {
+	v, err := db.storage.Get(key)
+	if err != nil {
+		return &godis_proto.Queue{}, nil
+	}
+	return getQueue(v)
+}
+
+func getJobsResponse(jobs []*godis_proto.Job) *godis_proto.Response {
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Jobs{Jobs: &godis_proto.JobList{Jobs: jobs}}}
+}
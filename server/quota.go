@@ -0,0 +1,223 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/storage"
+)
+
+var (
+	errOpsQuota       = errors.New("quota exceeded: too many requests")
+	errKeysQuota      = errors.New("quota exceeded: too many keys")
+	errBytesQuota     = errors.New("quota exceeded: too much data")
+	errValueSizeQuota = errors.New("quota exceeded: value is too large")
+)
+
+// Quota limits usage of database or client, zero field means no limit
+type Quota struct {
+	// MaxKeys - max amount of keys in database, it isn't applied to clients
+	MaxKeys int
+	// MaxBytes - max total size of keys and encoded values in database, it isn't applied to clients.
+	// Storage must implement storage.Sizer
+	MaxBytes int64
+	// MaxValueSize - max size of value, payload and script arguments of one write request
+	MaxValueSize int
+	// OpsPerSecond - max sustained rate of requests
+	OpsPerSecond float64
+	// Burst - amount of requests which can be made at once, OpsPerSecond (at least 1) by default
+	Burst int
+}
+
+// quotaState tracks usage of one quota
+type quotaState struct {
+	quota Quota
+	// limiter is nil if ops aren't limited
+	limiter  *opsLimiter
+	rejected int64
+}
+
+func newQuotaState(q Quota) *quotaState {
+	st := &quotaState{quota: q}
+	if q.OpsPerSecond > 0 {
+		burst := float64(q.Burst)
+		if burst <= 0 {
+			burst = q.OpsPerSecond
+			if burst < 1 {
+				burst = 1
+			}
+		}
+		st.limiter = &opsLimiter{rate: q.OpsPerSecond, burst: burst, tokens: burst, updated: time.Now()}
+	}
+	return st
+}
+
+func (st *quotaState) reject(err error) error {
+	atomic.AddInt64(&st.rejected, 1)
+	return err
+}
+
+// checkRequest checks limits which don't depend on database usage
+func (st *quotaState) checkRequest(req *godis_proto.Request) error {
+	if st.limiter != nil && !st.limiter.allow(time.Now()) {
+		return st.reject(errOpsQuota)
+	}
+	if st.quota.MaxValueSize > 0 && isGrowingOperation(req.Operation) && requestValueSize(req) > st.quota.MaxValueSize {
+		return st.reject(errValueSizeQuota)
+	}
+	return nil
+}
+
+// opsLimiter is token bucket, every request takes one token
+type opsLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	updated time.Time
+}
+
+func (l *opsLimiter) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens += now.Sub(l.updated).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.updated = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// clientQuotas keeps quota state of every client, clients are identified by remote host
+type clientQuotas struct {
+	mu      sync.Mutex
+	quota   Quota
+	clients map[string]*clientQuota
+}
+
+type clientQuota struct {
+	*quotaState
+	connections int
+}
+
+func newClientQuotas(q Quota) *clientQuotas {
+	return &clientQuotas{quota: q, clients: make(map[string]*clientQuota)}
+}
+
+// acquire returns quota state of client connected from addr, state is shared by all client connections
+func (cq *clientQuotas) acquire(addr net.Addr) *quotaState {
+	host := remoteHost(addr)
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	c, ok := cq.clients[host]
+	if !ok {
+		c = &clientQuota{quotaState: newQuotaState(cq.quota)}
+		cq.clients[host] = c
+	}
+	c.connections++
+	return c.quotaState
+}
+
+// release forgets client state when its last connection is closed
+func (cq *clientQuotas) release(addr net.Addr) {
+	host := remoteHost(addr)
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	if c, ok := cq.clients[host]; ok {
+		c.connections--
+		if c.connections == 0 {
+			delete(cq.clients, host)
+		}
+	}
+}
+
+func (cq *clientQuotas) stats() []*godis_proto.ClientStats {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	result := make([]*godis_proto.ClientStats, 0, len(cq.clients))
+	for host, c := range cq.clients {
+		result = append(result, &godis_proto.ClientStats{
+			Address:     host,
+			Connections: int64(c.connections),
+			Rejected:    atomic.LoadInt64(&c.rejected),
+		})
+	}
+	return result
+}
+
+func remoteHost(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// checkQuota returns error if request exceeds quota of client or database
+func (s *Server) checkQuota(c *connection, db *database, req *godis_proto.Request) error {
+	if c.quota != nil {
+		if err := c.quota.checkRequest(req); err != nil {
+			return err
+		}
+	}
+	if db.quota == nil {
+		return nil
+	}
+	if err := db.quota.checkRequest(req); err != nil {
+		return err
+	}
+	if !isGrowingOperation(req.Operation) {
+		return nil
+	}
+
+	q := db.quota.quota
+	// keys written by script are known only after it's executed, they are checked by scriptWrites.checkQuota
+	isScript := req.Operation == godis_proto.Operation_Eval || req.Operation == godis_proto.Operation_EvalSHA
+	if q.MaxKeys > 0 && !isScript && db.size() >= q.MaxKeys {
+		if _, err := db.storage.Get(req.GetKey()); err != nil {
+			return db.quota.reject(errKeysQuota)
+		}
+	}
+	if sizer, ok := db.storage.(storage.Sizer); ok && q.MaxBytes > 0 {
+		// encoded request is used as estimate of bytes it adds
+		if sizer.Size()+int64(proto.Size(req)) > q.MaxBytes {
+			return db.quota.reject(errBytesQuota)
+		}
+	}
+	return nil
+}
+
+// isGrowingOperation returns true for operations which can add keys or make values larger,
+// operations which only delete data are always allowed, so client can free space
+func isGrowingOperation(op godis_proto.Operation) bool {
+	switch op {
+	case godis_proto.Operation_Set, godis_proto.Operation_LPush, godis_proto.Operation_RPush,
+		godis_proto.Operation_HSet, godis_proto.Operation_Incr,
+		godis_proto.Operation_XAdd, godis_proto.Operation_XGroupCreate, godis_proto.Operation_QEnqueue,
+		godis_proto.Operation_LockAcquire, godis_proto.Operation_RateLimit,
+		godis_proto.Operation_Eval, godis_proto.Operation_EvalSHA:
+		return true
+	}
+	return false
+}
+
+// requestValueSize returns size of data written by request
+func requestValueSize(req *godis_proto.Request) int {
+	size := len(req.GetPayload())
+	if req.GetValue() != nil {
+		size += proto.Size(req.GetValue())
+	}
+	for _, arg := range req.GetArgs() {
+		size += len(arg)
+	}
+	return size
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+var (
+	errBadRateLimit         = errors.New("limit and window must be positive")
+	errCostExceedsLimit     = errors.New("cost exceeds limit")
+	errRateLimiterAlgorithm = errors.New("key holds rate limiter with another algorithm")
+)
+
+// getRateLimiter returns rate limiter stored in value, nil value is treated as new rate limiter
+func getRateLimiter(v *godis_proto.Value, algorithm godis_proto.RateLimitAlgorithm) (*godis_proto.RateLimiter, error) {
+	if v == nil {
+		return nil, nil
+	}
+	t, ok := v.GetValue().(*godis_proto.Value_RateLimiter)
+	if !ok {
+		return nil, badKeyType(v)
+	}
+	if t.RateLimiter.GetAlgorithm() != algorithm {
+		return nil, errRateLimiterAlgorithm
+	}
+	return t.RateLimiter, nil
+}
+
+// rateLimit checks whether request with given cost is allowed and takes cost from limiter if it is.
+// Limiter key expires when it would be in initial state, so idle limiters are deleted
+func (s *Server) rateLimit(db *database, req *godis_proto.Request) *godis_proto.Response {
+	limit, window := int64(req.GetLimit()), req.GetWindow()
+	if limit <= 0 || window <= 0 {
+		return getErrorResponse(errBadRateLimit.Error())
+	}
+	cost := int64(req.GetCost())
+	if cost == 0 {
+		cost = 1
+	}
+	if cost > limit {
+		return getErrorResponse(errCostExceedsLimit.Error())
+	}
+
+	var result *godis_proto.RateLimitResult
+	_, err := s.update(db, req.GetKey(), func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		limiter, err := getRateLimiter(old, req.GetAlgorithm())
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now().UnixNano()
+		var v *godis_proto.Value
+		switch req.GetAlgorithm() {
+		case godis_proto.RateLimitAlgorithm_TokenBucket:
+			v, result = tokenBucket(limiter, now, limit, window, cost)
+		case godis_proto.RateLimitAlgorithm_SlidingWindowLog:
+			v, result = slidingWindowLog(limiter, now, limit, window, cost)
+		}
+		if v == nil {
+			// denied request doesn't change limiter
+			return nil, errNotChanged
+		}
+		return v, nil
+	})
+	if err != nil && err != errNotChanged {
+		return getErrorResponse(err.Error())
+	}
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_RateLimit{RateLimit: result}}
+}
+
+// tokenBucket refills limit tokens during window, request takes cost tokens.
+// It returns nil value if request is denied
+func tokenBucket(limiter *godis_proto.RateLimiter, now, limit, window, cost int64) (*godis_proto.Value, *godis_proto.RateLimitResult) {
+	rate := float64(limit) / float64(window)
+	tokens := float64(limit)
+	if limiter != nil {
+		tokens = math.Min(tokens, limiter.GetTokens()+float64(now-limiter.GetUpdatedAt())*rate)
+	}
+
+	if tokens < float64(cost) {
+		return nil, &godis_proto.RateLimitResult{
+			Remaining:  int64(tokens),
+			RetryAfter: int64(math.Ceil((float64(cost) - tokens) / rate)),
+		}
+	}
+	tokens -= float64(cost)
+	return &godis_proto.Value{
+		Value: &godis_proto.Value_RateLimiter{RateLimiter: &godis_proto.RateLimiter{
+			Algorithm: godis_proto.RateLimitAlgorithm_TokenBucket,
+			Tokens:    tokens,
+			UpdatedAt: now,
+		}},
+		// bucket is full after that
+		Ttl: now + int64(math.Ceil((float64(limit)-tokens)/rate)),
+	}, &godis_proto.RateLimitResult{
+		Allowed:   true,
+		Remaining: int64(tokens),
+	}
+}
+
+// slidingWindowLog allows request if sum of costs of requests allowed during the last window and cost
+// doesn't exceed limit. It returns nil value if request is denied
+func slidingWindowLog(limiter *godis_proto.RateLimiter, now, limit, window, cost int64) (*godis_proto.Value, *godis_proto.RateLimitResult) {
+	log := limiter.GetLog()
+	for len(log) > 0 && log[0].GetTime() <= now-window {
+		log = log[1:]
+	}
+	used := int64(0)
+	for _, e := range log {
+		used += int64(e.GetCost())
+	}
+
+	if used+cost > limit {
+		result := &godis_proto.RateLimitResult{Remaining: limit - used}
+		// wait until enough entries leave window
+		for _, e := range log {
+			used -= int64(e.GetCost())
+			if used+cost <= limit {
+				result.RetryAfter = e.GetTime() + window - now
+				break
+			}
+		}
+		return nil, result
+	}
+
+	newLog := make([]*godis_proto.RateLimitLogEntry, 0, len(log)+1)
+	newLog = append(newLog, log...)
+	newLog = append(newLog, &godis_proto.RateLimitLogEntry{Time: now, Cost: uint32(cost)})
+	return &godis_proto.Value{
+		Value: &godis_proto.Value_RateLimiter{RateLimiter: &godis_proto.RateLimiter{
+			Algorithm: godis_proto.RateLimitAlgorithm_SlidingWindowLog,
+			Log:       newLog,
+		}},
+		// the last entry leaves window after that
+		Ttl: now + window,
+	}, &godis_proto.RateLimitResult{
+		Allowed:   true,
+		Remaining: limit - used - cost,
+	}
+}
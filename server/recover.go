@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/minaevmike/godis/snapshot"
+	"github.com/minaevmike/godis/wal"
+	"go.uber.org/zap"
+)
+
+// names of data files in directory written by recovery
+const (
+	recoveredSnapshot = "godis.snapshot"
+	recoveredWAL      = "godis.wal"
+)
+
+// RecoveryOptions configure point in time recovery
+type RecoveryOptions struct {
+	// SnapshotFile and WALFile - data of server, they are only read. Recovery starts from snapshot if it exists,
+	// so snapshot must be written before target
+	SnapshotFile string
+	WALFile      string
+	Target       wal.Target
+	// Dir - new data directory, recovered dataset is written to snapshot godis.snapshot in it.
+	// Server started with this snapshot and wal godis.wal in the directory serves recovered data
+	Dir string
+}
+
+// RecoveryInfo describes recovered dataset
+type RecoveryInfo struct {
+	// Snapshot - file with recovered dataset
+	Snapshot string
+	// LSN and Time of the last replayed wal record, they are zero if no records were replayed
+	LSN  uint64
+	Time time.Time
+	Keys int64
+}
+
+// Recover restores dataset as it was at target into new data directory. Options set databases and storages
+// like options of server
+func Recover(logger *zap.Logger, ro RecoveryOptions, opts ...Option) (RecoveryInfo, error) {
+	var result RecoveryInfo
+	if ro.Target.LSN == 0 && ro.Target.Time.IsZero() {
+		return result, errors.New("recovery target isn't set")
+	}
+	if err := os.MkdirAll(ro.Dir, 0755); err != nil {
+		return result, err
+	}
+	snapshotFile := filepath.Join(ro.Dir, recoveredSnapshot)
+	if err := checkNoData(snapshotFile, filepath.Join(ro.Dir, recoveredWAL)); err != nil {
+		return result, err
+	}
+
+	// dataset is replayed into server without wal and snapshots
+	s := NewServer(logger, append(opts, WithDurability(wal.ModeNone), WithSnapshotFile(""))...)
+	if s.loadErr != nil {
+		return result, s.loadErr
+	}
+	from, floor := uint64(0), wal.Point{}
+	if ro.SnapshotFile != "" {
+		info, err := snapshot.ReadFile(ro.SnapshotFile, s.cipher, s.replay)
+		switch {
+		case err == nil:
+			from, floor = info.Position, wal.Point{LSN: info.LSN, Time: info.Time}
+		case !os.IsNotExist(err):
+			return result, fmt.Errorf("can't read snapshot: %v", err)
+		}
+	}
+	point, err := wal.ReplayTo(ro.WALFile, from, floor, ro.Target, s.cipher, s.replay)
+	if err != nil {
+		return result, err
+	}
+	lsn := point.LSN
+	if lsn < floor.LSN {
+		lsn = floor.LSN
+	}
+
+	keys := int64(0)
+	_, err = snapshot.WriteFile(snapshotFile, 0, s.cipher, func(write func(record *wal.Record) error) (uint64, error) {
+		err := s.dumpWAL(func(record *wal.Record) error {
+			if record.Cmd == wal.Write {
+				keys++
+			}
+			return write(record)
+		})
+		return lsn, err
+	})
+	if err != nil {
+		return result, err
+	}
+	logger.Info("dataset recovered", zap.String("target", ro.Target.String()), zap.String("snapshot", snapshotFile),
+		zap.Uint64("lsn", point.LSN), zap.Int64("keys", keys))
+	return RecoveryInfo{Snapshot: snapshotFile, LSN: point.LSN, Time: point.Time, Keys: keys}, nil
+}
@@ -0,0 +1,391 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/snapshot"
+	"github.com/minaevmike/godis/wal"
+	"github.com/minaevmike/godis/wire"
+	"go.uber.org/zap"
+)
+
+const (
+	// replicationHeartbeat - how often primary sends its state to replica which has all records
+	replicationHeartbeat        = 500 * time.Millisecond
+	defaultReplicaRetryInterval = time.Second
+	defaultReplicaTimeout       = 5 * time.Second
+)
+
+var (
+	errReadOnly            = errors.New("replica is read-only, writes must be sent to primary")
+	errReplicationDisabled = errors.New("replication requires wal, server is replica or wal is disabled")
+)
+
+// ReplicaOptions configures replica of primary server
+type ReplicaOptions struct {
+	// Primary - address of primary server
+	Primary string
+	// RetryInterval - delay before reconnection to primary, 1 second by default
+	RetryInterval time.Duration
+	// Timeout - replica reconnects if primary sends nothing during it, 5 seconds by default.
+	// Primary sends its state every 500 milliseconds
+	Timeout time.Duration
+}
+
+// replicaState is progress of replica
+type replicaState struct {
+	opts ReplicaOptions
+	mu   sync.Mutex
+	// connected is set while wal stream is received
+	connected bool
+	// lsn - LSN of the last applied record, zero until full resync is finished
+	lsn        uint64
+	primaryLSN uint64
+	lag        time.Duration
+	fullSyncs  int64
+}
+
+func newReplicaState(opts ReplicaOptions) *replicaState {
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultReplicaRetryInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultReplicaTimeout
+	}
+	return &replicaState{opts: opts}
+}
+
+func (r *replicaState) position() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lsn
+}
+
+func (r *replicaState) setConnected(connected bool) {
+	r.mu.Lock()
+	r.connected = connected
+	r.mu.Unlock()
+}
+
+// apply updates state by event received from primary
+func (r *replicaState) apply(e *godis_proto.ReplicationEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connected = true
+	if e.GetLsn() > r.primaryLSN {
+		r.primaryLSN = e.GetLsn()
+	}
+	record := e.GetRecord()
+	switch {
+	case record != nil:
+		if record.GetLsn() == 0 {
+			return
+		}
+		r.lsn = record.GetLsn()
+		if r.lsn > r.primaryLSN {
+			r.primaryLSN = r.lsn
+		}
+		r.lag = time.Since(time.Unix(0, record.GetTime()))
+		if r.lag < 0 {
+			r.lag = 0
+		}
+	case e.GetFullSync():
+		// data isn't consistent until dump is applied, so next connection starts full resync again
+		r.lsn = 0
+	case e.GetSynced():
+		r.lsn = e.GetLsn()
+		r.fullSyncs++
+	}
+	if r.lsn >= r.primaryLSN {
+		r.lag = 0
+	}
+}
+
+func (r *replicaState) stats() *godis_proto.ReplicationStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &godis_proto.ReplicationStats{
+		Primary:    r.opts.Primary,
+		Connected:  r.connected,
+		Lsn:        r.lsn,
+		PrimaryLsn: r.primaryLSN,
+		Lag:        int64(r.lag),
+		FullSyncs:  r.fullSyncs,
+	}
+}
+
+// replicationStats returns progress of replica or position of primary
+func (s *Server) replicationStats() *godis_proto.ReplicationStats {
+	if s.replica != nil {
+		return s.replica.stats()
+	}
+	return &godis_proto.ReplicationStats{Lsn: s.lastLSN(), Replicas: atomic.LoadInt64(&s.replicas)}
+}
+
+// handleReplicate streams wal to replica until it disconnects or server is stopped,
+// the connection isn't used for other requests after it
+func (s *Server) handleReplicate(c *connection, req *godis_proto.Request) *godis_proto.Response {
+	if s.replica != nil || s.durability == wal.ModeNone {
+		return getErrorResponse(errReplicationDisabled.Error())
+	}
+	atomic.AddInt64(&s.replicas, 1)
+	defer atomic.AddInt64(&s.replicas, -1)
+	c.broken = true
+
+	closed, stopWatch := c.watchClose()
+	defer stopWatch()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-closed:
+		case <-s.stop:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	s.log.Info("replica connected", zap.String("addr", c.RemoteAddr().String()), zap.Uint64("lsn", req.GetLsn()))
+	err := s.streamWAL(ctx, c, req.GetLsn())
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	s.log.Info("replica disconnected", zap.String("addr", c.RemoteAddr().String()), zap.Error(err))
+	return getErrorResponse(fmt.Sprintf("replication is stopped: %v", err))
+}
+
+// streamWAL sends records with LSN greater than lsn to replica and waits for new ones,
+// full resync is made if these records aren't available
+func (s *Server) streamWAL(ctx context.Context, c *connection, lsn uint64) error {
+	var (
+		it  wal.Iterator
+		err error
+	)
+	if lsn > 0 {
+		it, err = s.wal.Subscribe(lsn + 1)
+		if err == nil {
+			err = c.write(replicationState(s.lastLSN()))
+			if err != nil {
+				it.Close()
+				return err
+			}
+		}
+	}
+	if lsn == 0 || err != nil {
+		if lsn > 0 {
+			s.log.Info("replica position isn't available, making full resync", zap.Uint64("lsn", lsn), zap.Error(err))
+		}
+		if it, err = s.fullSync(c); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if it != nil {
+			it.Close()
+		}
+	}()
+
+	for {
+		next, cancel := context.WithTimeout(ctx, replicationHeartbeat)
+		record, err := it.Next(next)
+		cancel()
+		switch {
+		case err == nil:
+			err = c.write(replicationRecord(record, s.lastLSN()))
+		case err == wal.ErrUnavailable:
+			// records were deleted by snapshot or compaction while replica was reading them
+			s.log.Info("records aren't available to replica anymore, making full resync")
+			it.Close()
+			it, err = s.fullSync(c)
+		case err == context.DeadlineExceeded && ctx.Err() == nil:
+			err = c.write(replicationState(s.lastLSN()))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fullSync sends all data to replica and returns iterator over records written after it. Writes go on
+// during dump, their records contain whole values, so they lead to the same state when they're applied after dump.
+// Dump is written to temporary file and sent after it's finished, so slow replica doesn't block writes and snapshots
+func (s *Server) fullSync(c *connection) (wal.Iterator, error) {
+	end, err := s.beginDump()
+	if err != nil {
+		return nil, err
+	}
+	lsn := s.lastLSN()
+	it, err := s.wal.Subscribe(lsn + 1)
+	if err != nil {
+		end()
+		return nil, err
+	}
+	f, err := s.dumpFile()
+	end()
+	if err != nil {
+		it.Close()
+		return nil, err
+	}
+	defer removeTempFile(f)
+
+	event := replicationState(lsn)
+	event.GetReplication().FullSync = true
+	err = c.write(event)
+	if err == nil {
+		var writeErr error
+		_, err = snapshot.Read(f, s.cipher, func(record *wal.Record) {
+			if writeErr == nil {
+				writeErr = c.write(replicationRecord(record, lsn))
+			}
+		})
+		if err == nil {
+			err = writeErr
+		}
+	}
+	if err == nil {
+		event = replicationState(lsn)
+		event.GetReplication().Synced = true
+		err = c.write(event)
+	}
+	if err != nil {
+		it.Close()
+		return nil, err
+	}
+	return it, nil
+}
+
+func replicationState(lsn uint64) *godis_proto.Response {
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Replication{Replication: &godis_proto.ReplicationEvent{
+		Lsn:  lsn,
+		Time: time.Now().UnixNano(),
+	}}}
+}
+
+// replicationRecord returns event with record, lsn is the last LSN of primary
+func replicationRecord(r *wal.Record, lsn uint64) *godis_proto.Response {
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Replication{Replication: &godis_proto.ReplicationEvent{
+		Record: &godis_proto.WalRecord{
+			Command:  uint32(r.Cmd),
+			Database: r.DB,
+			Key:      r.Key,
+			Value:    r.Value,
+			Lsn:      r.LSN,
+			Time:     r.Time,
+		},
+		Lsn:  lsn,
+		Time: time.Now().UnixNano(),
+	}}}
+}
+
+// replicate receives data from primary until stop is closed, it reconnects after errors
+func (s *Server) replicate(stop chan struct{}) {
+	for {
+		err := s.receiveWAL(stop)
+		s.replica.setConnected(false)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		s.log.Warn("replication is interrupted", zap.String("primary", s.replica.opts.Primary), zap.Error(err))
+		select {
+		case <-stop:
+			return
+		case <-time.After(s.replica.opts.RetryInterval):
+		}
+	}
+}
+
+// receiveWAL connects to primary and applies data received from it, replica continues from its last LSN
+func (s *Server) receiveWAL(stop chan struct{}) error {
+	opts := s.replica.opts
+	conn, err := net.DialTimeout("tcp", opts.Primary, opts.Timeout)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	// records are limited by primary, so replica reads frames of any size
+	protocol := wire.NewSimpleWireProtocol(s.cd)
+	req := &godis_proto.Request{Operation: godis_proto.Operation_Replicate, Lsn: s.replica.position()}
+	if err := protocol.Write(conn, req); err != nil {
+		return err
+	}
+	for {
+		conn.SetReadDeadline(time.Now().Add(opts.Timeout))
+		resp := &godis_proto.Response{}
+		if err := protocol.Read(conn, resp); err != nil {
+			return err
+		}
+		if resp.GetError() != nil {
+			return errors.New(resp.GetError().GetMessage())
+		}
+		event := resp.GetReplication()
+		if event == nil {
+			return fmt.Errorf("unexpected response of primary: %T", resp.GetResponseValue())
+		}
+		if event.GetFullSync() {
+			s.log.Info("full resync from primary", zap.String("primary", opts.Primary), zap.Uint64("lsn", event.GetLsn()))
+		}
+		if record := event.GetRecord(); record != nil {
+			s.applyRecord(record)
+		}
+		s.replica.apply(event)
+	}
+}
+
+// applyRecord applies record received from primary and notifies keyspace subscribers and blocked readers
+func (s *Server) applyRecord(r *godis_proto.WalRecord) {
+	record := &wal.Record{
+		Cmd:   wal.Command(r.GetCommand()),
+		DB:    r.GetDatabase(),
+		Key:   r.GetKey(),
+		Value: r.GetValue(),
+		LSN:   r.GetLsn(),
+		Time:  r.GetTime(),
+	}
+	s.replay(record)
+	if record.Cmd == wal.Flush || int(record.DB) >= len(s.databases) {
+		return
+	}
+	db := s.databases[record.DB]
+	key := string(record.Key)
+	event := godis_proto.EventType_Written
+	if v, err := db.storage.Get(key); err != nil || v == nil {
+		event = godis_proto.EventType_Removed
+	}
+	s.pubSub.notify(db.index, key, event)
+	db.blocking.signal(key)
+}
+
+// isWriteOperation returns true if operation changes data, such operations are rejected by replica
+func isWriteOperation(op godis_proto.Operation) bool {
+	switch op {
+	case godis_proto.Operation_Set, godis_proto.Operation_Remove, godis_proto.Operation_FlushDB,
+		godis_proto.Operation_Expire, godis_proto.Operation_HSet, godis_proto.Operation_HDel, godis_proto.Operation_Incr,
+		godis_proto.Operation_LPush, godis_proto.Operation_RPush, godis_proto.Operation_LPop, godis_proto.Operation_RPop,
+		godis_proto.Operation_BLPop, godis_proto.Operation_BRPop,
+		godis_proto.Operation_XAdd, godis_proto.Operation_XTrim, godis_proto.Operation_XGroupCreate,
+		godis_proto.Operation_XReadGroup, godis_proto.Operation_XAck,
+		godis_proto.Operation_QEnqueue, godis_proto.Operation_QDequeue, godis_proto.Operation_QAck, godis_proto.Operation_QNack,
+		godis_proto.Operation_LockAcquire, godis_proto.Operation_LockRenew, godis_proto.Operation_LockRelease,
+		godis_proto.Operation_RateLimit, godis_proto.Operation_Eval, godis_proto.Operation_EvalSHA:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,378 @@
+package server
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/storage"
+	"github.com/minaevmike/godis/wal"
+	"github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+const defaultScriptTimeout = 5 * time.Second
+
+var (
+	errScriptNotFound  = errors.New("script not found, load it with ScriptLoad")
+	errScriptTimeout   = errors.New("script execution time limit exceeded")
+	errScriptsDisabled = errors.New("storage doesn't support scripts")
+)
+
+// scriptCache keeps compiled scripts by sha1 of their source
+type scriptCache struct {
+	mu      sync.RWMutex
+	scripts map[string]*lua.FunctionProto
+}
+
+func newScriptCache() *scriptCache {
+	return &scriptCache{scripts: make(map[string]*lua.FunctionProto)}
+}
+
+// load compiles script and caches it, it returns sha of script
+func (sc *scriptCache) load(src string) (string, *lua.FunctionProto, error) {
+	sum := sha1.Sum([]byte(src))
+	sha := hex.EncodeToString(sum[:])
+	if proto, ok := sc.get(sha); ok {
+		return sha, proto, nil
+	}
+
+	chunk, err := parse.Parse(strings.NewReader(src), "script")
+	if err != nil {
+		return "", nil, err
+	}
+	proto, err := lua.Compile(chunk, "script")
+	if err != nil {
+		return "", nil, err
+	}
+	sc.mu.Lock()
+	sc.scripts[sha] = proto
+	sc.mu.Unlock()
+	return sha, proto, nil
+}
+
+func (sc *scriptCache) get(sha string) (*lua.FunctionProto, bool) {
+	sc.mu.RLock()
+	proto, ok := sc.scripts[strings.ToLower(sha)]
+	sc.mu.RUnlock()
+	return proto, ok
+}
+
+func (s *Server) scriptLoad(req *godis_proto.Request) *godis_proto.Response {
+	sha, _, err := s.scripts.load(req.GetScript())
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getStringResponse(sha)
+}
+
+func (s *Server) eval(db *database, req *godis_proto.Request) *godis_proto.Response {
+	_, proto, err := s.scripts.load(req.GetScript())
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return s.runScript(db, proto, req)
+}
+
+func (s *Server) evalSHA(db *database, req *godis_proto.Request) *godis_proto.Response {
+	proto, ok := s.scripts.get(req.GetSha())
+	if !ok {
+		return getErrorResponse(errScriptNotFound.Error())
+	}
+	return s.runScript(db, proto, req)
+}
+
+// runScript executes script while all other storage operations are blocked. Script writes are buffered
+// and applied only if script succeeds, they are written to wal as one batch
+func (s *Server) runScript(db *database, proto *lua.FunctionProto, req *godis_proto.Request) *godis_proto.Response {
+	st, ok := db.storage.(storage.Atomic)
+	if !ok {
+		return getErrorResponse(errScriptsDisabled.Error())
+	}
+	timeout := time.Duration(req.GetTimeout())
+	if timeout <= 0 {
+		timeout = s.scriptTimeout
+	}
+
+	var (
+		result  *godis_proto.Response
+		changed *scriptWrites
+	)
+	err := st.Atomically(func(view storage.Storage) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		writes := newScriptWrites(view)
+		L := newScriptState(writes, req.GetKeys(), req.GetArgs())
+		defer L.Close()
+		L.SetContext(ctx)
+
+		L.Push(L.NewFunctionFromProto(proto))
+		if err := L.PCall(0, 1, nil); err != nil {
+			if ctx.Err() != nil {
+				return errScriptTimeout
+			}
+			if apiErr, ok := err.(*lua.ApiError); ok {
+				return errors.New(apiErr.Object.String())
+			}
+			return err
+		}
+		resp, err := scriptResponse(L.Get(-1))
+		if err != nil {
+			return err
+		}
+
+		result, changed = resp, writes
+		return writes.apply(view, s, db)
+	})
+	if err == errKeysQuota || err == errBytesQuota {
+		return getCodeErrorResponse(godis_proto.ErrorCode_QuotaExceeded, err.Error())
+	}
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+
+	for _, key := range changed.keys {
+		if changed.values[key] == nil {
+			s.pubSub.notify(db.index, key, godis_proto.EventType_Removed)
+		} else {
+			s.pubSub.notify(db.index, key, godis_proto.EventType_Written)
+			db.blocking.signal(key)
+		}
+	}
+	return result
+}
+
+// scriptWrites buffers values written by script, nil value means deleted key
+type scriptWrites struct {
+	view   storage.Storage
+	values map[string]*godis_proto.Value
+	// keys in order of the first write
+	keys []string
+}
+
+func newScriptWrites(view storage.Storage) *scriptWrites {
+	return &scriptWrites{view: view, values: make(map[string]*godis_proto.Value)}
+}
+
+func (sw *scriptWrites) get(key string) *godis_proto.Value {
+	if v, ok := sw.values[key]; ok {
+		return v
+	}
+	v, err := sw.view.Get(key)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func (sw *scriptWrites) set(key string, v *godis_proto.Value) {
+	if _, ok := sw.values[key]; !ok {
+		sw.keys = append(sw.keys, key)
+	}
+	sw.values[key] = v
+}
+
+// apply writes buffered values to storage and wal, it's called under storage lock,
+// so wal records order is same as order of changes
+func (sw *scriptWrites) apply(view storage.Storage, s *Server, db *database) error {
+	if err := sw.checkQuota(view, db); err != nil {
+		return err
+	}
+	records := make([]*wal.Record, 0, len(sw.keys))
+	for _, key := range sw.keys {
+		v := sw.values[key]
+		record := &wal.Record{Cmd: wal.Write, DB: uint32(db.index), Key: []byte(key), Value: s.marshal(v)}
+		if v == nil {
+			record.Cmd = wal.Delete
+			view.Delete(key)
+		} else {
+			view.Set(key, v)
+		}
+		records = append(records, record)
+	}
+	if len(records) > 0 {
+		s.writeWAL(records...)
+	}
+	return nil
+}
+
+// checkQuota returns error if buffered values add keys or data over quota of database,
+// writes which don't make database larger are always allowed
+func (sw *scriptWrites) checkQuota(view storage.Storage, db *database) error {
+	if db.quota == nil {
+		return nil
+	}
+	keys, bytes := 0, int64(0)
+	for _, key := range sw.keys {
+		if old, err := view.Get(key); err == nil {
+			keys--
+			bytes -= int64(len(key) + proto.Size(old))
+		}
+		if v := sw.values[key]; v != nil {
+			keys++
+			bytes += int64(len(key) + proto.Size(v))
+		}
+	}
+
+	q := db.quota.quota
+	if q.MaxKeys > 0 && keys > 0 && storageLen(view)+keys > q.MaxKeys {
+		return db.quota.reject(errKeysQuota)
+	}
+	if sizer, ok := view.(storage.Sizer); ok && q.MaxBytes > 0 && bytes > 0 && sizer.Size()+bytes > q.MaxBytes {
+		return db.quota.reject(errBytesQuota)
+	}
+	return nil
+}
+
+// newScriptState creates lua state with safe libraries, KEYS and ARGV globals and godis module
+func newScriptState(writes *scriptWrites, keys, args []string) *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		fn   lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.fn))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	// base library can read files, load modules and write to stdout of server
+	for _, name := range []string{"dofile", "loadfile", "module", "require", "print", "_printregs"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	L.SetGlobal("KEYS", stringsTable(L, keys))
+	L.SetGlobal("ARGV", stringsTable(L, args))
+	L.SetGlobal("godis", L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		// get(key) returns string, table or nil if key doesn't exist
+		"get": func(L *lua.LState) int {
+			v := writes.get(L.CheckString(1))
+			if v == nil {
+				L.Push(lua.LNil)
+				return 1
+			}
+			lv, err := luaValue(L, v)
+			if err != nil {
+				L.RaiseError("%s", err.Error())
+			}
+			L.Push(lv)
+			return 1
+		},
+		// set(key, value [, ttl_ms]) sets string or table (array or map of strings) value,
+		// without ttl existing key keeps its ttl and new key never expires
+		"set": func(L *lua.LState) int {
+			key := L.CheckString(1)
+			v, err := protoValue(L.CheckAny(2))
+			if err != nil {
+				L.ArgError(2, err.Error())
+			}
+			v.Ttl = math.MaxInt64
+			if ttl := L.OptInt64(3, 0); ttl > 0 {
+				v.Ttl = time.Now().Add(time.Duration(ttl) * time.Millisecond).UnixNano()
+			} else if old := writes.get(key); old != nil {
+				v.Ttl = old.GetTtl()
+			}
+			writes.set(key, v)
+			return 0
+		},
+		// del(key) deletes key and returns true if it existed
+		"del": func(L *lua.LState) int {
+			key := L.CheckString(1)
+			existed := writes.get(key) != nil
+			if existed {
+				writes.set(key, nil)
+			}
+			L.Push(lua.LBool(existed))
+			return 1
+		},
+		"exists": func(L *lua.LState) int {
+			L.Push(lua.LBool(writes.get(L.CheckString(1)) != nil))
+			return 1
+		},
+	}))
+	return L
+}
+
+func stringsTable(L *lua.LState, arr []string) *lua.LTable {
+	t := L.CreateTable(len(arr), 0)
+	for _, s := range arr {
+		t.Append(lua.LString(s))
+	}
+	return t
+}
+
+// luaValue converts stored value to lua value, only strings, slices and maps are supported
+func luaValue(L *lua.LState, v *godis_proto.Value) (lua.LValue, error) {
+	switch t := v.GetValue().(type) {
+	case *godis_proto.Value_StringVal:
+		return lua.LString(t.StringVal), nil
+	case *godis_proto.Value_StringSlice:
+		return stringsTable(L, t.StringSlice.GetStringArrayVal()), nil
+	case *godis_proto.Value_StringMap:
+		m := t.StringMap.GetStringMap()
+		table := L.CreateTable(0, len(m))
+		for k, v := range m {
+			table.RawSetString(k, lua.LString(v))
+		}
+		return table, nil
+	}
+	return nil, badKeyType(v)
+}
+
+// protoValue converts lua string or number to string value, array table to slice and other tables to map
+func protoValue(lv lua.LValue) (*godis_proto.Value, error) {
+	switch t := lv.(type) {
+	case lua.LString, lua.LNumber:
+		return &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: lv.String()}}, nil
+	case *lua.LTable:
+		n := t.MaxN()
+		if n > 0 && n == t.Len() {
+			arr := make([]string, 0, n)
+			for i := 1; i <= n; i++ {
+				arr = append(arr, t.RawGetInt(i).String())
+			}
+			return newStringSlice(arr, 0), nil
+		}
+		m := make(map[string]string)
+		var err error
+		t.ForEach(func(k, v lua.LValue) {
+			if _, ok := v.(*lua.LTable); ok {
+				err = errors.New("nested tables aren't supported")
+			}
+			m[k.String()] = v.String()
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &godis_proto.Value{Value: &godis_proto.Value_StringMap{StringMap: &godis_proto.MapString{StringMap: m}}}, nil
+	}
+	return nil, errors.New("string, number or table expected, got " + lv.Type().String())
+}
+
+// scriptResponse converts value returned by script to response: nil and false are empty responses,
+// true is "1", other values are converted like values of godis.set
+func scriptResponse(lv lua.LValue) (*godis_proto.Response, error) {
+	switch lv {
+	case lua.LNil, lua.LFalse:
+		return &godis_proto.Response{}, nil
+	case lua.LTrue:
+		return getStringResponse("1"), nil
+	}
+	v, err := protoValue(lv)
+	if err != nil {
+		return nil, err
+	}
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{Value: v}}, nil
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"regexp"
 
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/minaevmike/godis/codec"
@@ -30,47 +32,111 @@ func ListenAndServe(addr string) error {
 
 func NewServer(
 	logger *zap.Logger,
+	opts ...Option,
 ) *Server {
-	st := storage.NewShardMapStorage(32)
 	cd := codec.NewProtoCodec()
-	return &Server{
-		log:          logger,
-		wireProtocol: wire.NewSimpleWireProtocol(cd),
-		stopChan:     make(chan struct{}),
-		storage:      st,
-		wal: wal.NewIntervalWAL("./godis.wal", time.Second, logger, func(record *wal.Record) {
-			v := &godis_proto.Value{}
-			err := cd.Unmarshal(record.Value, v)
-			if err != nil {
-				logger.Error("can't unmarshal data from wal", zap.Error(err))
-				return
-			}
-			switch record.Cmd {
-			case wal.Write:
-				err = st.Set(string(record.Key), v)
-				if err != nil {
-					logger.Error("can't set value from wal", zap.Error(err))
-					return
-				}
-			case wal.Delete:
-				err = st.Delete(string(record.Key))
-				if err != nil {
-					logger.Error("can't delete value from wal", zap.Error(err))
-					return
-				}
-			}
-		}),
-		cd: cd,
+	s := &Server{
+		log:              logger,
+		stop:             make(chan struct{}),
+		conns:            make(map[*connection]bool),
+		storageFactory:   defaultStorageFactory,
+		databasesCount:   defaultDatabases,
+		cd:               cd,
+		pubSub:           newPubSub(),
+		expireInterval:   defaultExpireInterval,
+		walFile:          defaultWALFile,
+		snapshotFile:     defaultSnapshotFile,
+		snapshotInterval: defaultSnapshotInterval,
+		walOptions:       wal.DefaultOptions(),
+		durability:       wal.ModeInterval,
+		scripts:          newScriptCache(),
+		scriptTimeout:    defaultScriptTimeout,
+		databaseQuotas:   make(map[int]Quota),
+		limits:           DefaultLimits(),
+		connections:      newConnectionTracker(DefaultConnectionLimits()),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.replica != nil {
+		// replica gets data from primary after every start, so it keeps data only in memory
+		s.durability = wal.ModeNone
+	}
+	s.wireProtocol = wire.NewSimpleWireProtocol(cd)
+	if s.limits.MaxFrameSize > 0 {
+		s.wireProtocol = wire.NewSimpleWireProtocolWithMaxFrameSize(cd, s.limits.MaxFrameSize)
+	}
+	for i := 0; i < s.databasesCount; i++ {
+		st := s.storage
+		if i > 0 || st == nil {
+			st = s.storageFactory()
+		}
+		db := newDatabase(i, st)
+		if q, ok := s.databaseQuotas[i]; ok {
+			db.quota = newQuotaState(q)
+		}
+		if n, ok := st.(storage.ExpireNotifier); ok {
+			n.OnExpire(func(key string) {
+				s.pubSub.notify(db.index, key, godis_proto.EventType_Expired)
+			})
+		}
+		s.databases = append(s.databases, db)
 	}
+	if err := s.load(); err != nil {
+		// server without its data must not accept requests, so error is returned by Run
+		logger.Error("can't load data", zap.Error(err))
+		s.loadErr = err
+	}
+	return s
 }
 
 type Server struct {
 	log          *zap.Logger
 	wireProtocol wire.Protocol
-	stopChan     chan struct{}
-	storage      storage.Storage
-	wal          wal.WAL
-	cd           codec.Codec
+	stop         chan struct{}
+	// mu guards listener, conns and closing
+	mu       sync.Mutex
+	listener net.Listener
+	// conns contains open connections, value is true while connection handles request
+	conns   map[*connection]bool
+	closing bool
+	// storage - storage of database 0 set by WithStorage, other databases use storageFactory
+	storage        storage.Storage
+	storageFactory func() storage.Storage
+	databasesCount int
+	databases      []*database
+	wal            wal.WAL
+	cd             codec.Codec
+	pubSub         *pubSub
+	// expireInterval - how often expired keys are deleted from storage
+	expireInterval time.Duration
+	walFile        string
+	walOptions     wal.Options
+	durability     wal.Mode
+	// cipher encrypts wal records and snapshots, nil if encryption is disabled
+	cipher wal.Cipher
+	// loadErr is set if snapshot or wal can't be loaded
+	loadErr error
+	// snapshotFile - path of snapshot, empty if snapshots are disabled
+	snapshotFile     string
+	snapshotInterval time.Duration
+	snapshots        snapshotState
+	// dumps makes updates write whole values while snapshot, backup or compaction dump is made
+	dumps dumpGate
+	// backupDir - directory where backups requested with path are written, empty if they are disabled
+	backupDir string
+	scripts   *scriptCache
+	// scriptTimeout - default max execution time of script
+	scriptTimeout  time.Duration
+	databaseQuotas map[int]Quota
+	// clientQuotas is nil if clients aren't limited
+	clientQuotas *clientQuotas
+	limits       Limits
+	connections  *connectionTracker
+	// replica is nil if server isn't replica
+	replica *replicaState
+	// replicas - amount of replicas receiving wal stream
+	replicas int64
 }
 
 func errorPermament(err error) bool {
@@ -83,22 +149,37 @@ func errorPermament(err error) bool {
 
 }
 
-func (s *Server) Shutdown() {
-	s.stopChan <- struct{}{}
-}
-
+// Run accepts connections until Shutdown is called, then ErrServerClosed is returned
 func (s *Server) Run(addr string) error {
+	if s.loadErr != nil {
+		return s.loadErr
+	}
 	l, err := net.Listen("tcp", addr)
 	if err != nil {
-		return nil
+		return err
 	}
-	go func() {
-		<-s.stopChan
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
 		l.Close()
-	}()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+	go s.expireLoop(s.stop)
+	go s.snapshotLoop(s.stop)
+	if s.replica != nil {
+		go s.replicate(s.stop)
+	}
 	for {
 		conn, err := l.Accept()
 		if err != nil {
+			s.mu.Lock()
+			closing := s.closing
+			s.mu.Unlock()
+			if closing {
+				return ErrServerClosed
+			}
 			if errorPermament(err) {
 				return err
 			}
@@ -106,10 +187,41 @@ func (s *Server) Run(addr string) error {
 			continue
 		}
 
+		if err := s.connections.acquire(conn.RemoteAddr()); err != nil {
+			go s.reject(conn, err)
+			continue
+		}
+		s.connections.configure(conn)
 		go s.handleConnection(conn)
 	}
 }
 
+// expireLoop actively deletes expired keys, so expire notifications are sent
+// even for keys which are never accessed
+func (s *Server) expireLoop(stop chan struct{}) {
+	var expirers []storage.Expirer
+	for _, db := range s.databases {
+		if expirer, ok := db.storage.(storage.Expirer); ok {
+			expirers = append(expirers, expirer)
+		}
+	}
+	if len(expirers) == 0 || s.expireInterval <= 0 {
+		return
+	}
+	t := time.NewTicker(s.expireInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			for _, expirer := range expirers {
+				expirer.DeleteExpired()
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
 func (s *Server) marshal(v *godis_proto.Value) []byte {
 	if v == nil {
 		return nil
@@ -123,142 +235,445 @@ func (s *Server) marshal(v *godis_proto.Value) []byte {
 }
 
 func (s *Server) handleConnection(conn net.Conn) {
-	defer conn.Close()
+	c := newConnection(conn, s.wireProtocol, s.connections.limits.WriteTimeout)
+	if s.clientQuotas != nil {
+		c.quota = s.clientQuotas.acquire(conn.RemoteAddr())
+	}
+	defer s.closeConnection(c)
+	if !s.trackConnection(c) {
+		return
+	}
 	for {
+		if !s.waitRequest(c) || !s.setActive(c, true) {
+			return
+		}
 		req := &godis_proto.Request{}
-		err := s.wireProtocol.Read(conn, req)
+		err := s.wireProtocol.Read(c.Conn, req)
 		if err != nil {
+			if isTimeout(err) {
+				atomic.AddInt64(&s.connections.readTimeouts, 1)
+				s.log.Warn("request read timeout", zap.String("remote", conn.RemoteAddr().String()))
+				return
+			}
+			if _, ok := err.(*wire.DecodeError); ok || err == wire.ErrFrameTooLarge {
+				// rest of stream can't be parsed, so client is told why connection is closed
+				s.log.Warn("bad frame", zap.String("remote", conn.RemoteAddr().String()), zap.Error(err))
+				c.write(getCodeErrorResponse(godis_proto.ErrorCode_BadRequest, err.Error()))
+				return
+			}
 			if err != io.EOF {
 				s.log.Error("can't read", zap.Error(err))
 			}
 			return
 		}
+		// blocking requests watch connection while they wait, so read deadline is removed
+		c.SetReadDeadline(time.Time{})
 
-		switch req.Operation {
-		case godis_proto.Operation_Get:
-			v, err := s.storage.Get(req.GetKey())
-			if err != nil {
-				s.wireProtocol.Write(conn, getErrorResponse(err.Error()))
-				continue
-			}
-			s.wireProtocol.Write(conn, &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{
-				Value: v,
-			}})
+		err = c.write(s.handleDurableRequest(c, req))
+		if err != nil {
+			s.connections.writeFailed(err)
+			s.log.Error("can't write", zap.Error(err))
+			return
+		}
+		if c.broken || !s.setActive(c, false) {
+			return
+		}
+	}
+}
 
-		case godis_proto.Operation_Set:
-			err := s.storage.Set(req.GetKey(), req.GetValue())
-			if err != nil {
-				s.wireProtocol.Write(conn, getErrorResponse(err.Error()))
-				continue
-			}
-			err = s.wal.Write(wal.Write, []byte(req.GetKey()), s.marshal(req.GetValue()))
-			if err != nil {
-				s.log.Error("can't write to wal", zap.Error(err))
-			}
-			s.wireProtocol.Write(conn, &godis_proto.Response{})
+func (s *Server) closeConnection(c *connection) {
+	if c.subscriber != nil {
+		s.pubSub.unsubscribeAll(c.subscriber)
+		c.subscriber.close()
+	}
+	if c.quota != nil {
+		s.clientQuotas.release(c.RemoteAddr())
+	}
+	s.connections.release(c.RemoteAddr())
+	s.untrackConnection(c)
+	c.Close()
+}
 
-		case godis_proto.Operation_Remove:
-			err := s.storage.Delete(req.GetKey())
-			if err != nil {
-				s.wireProtocol.Write(conn, getErrorResponse(err.Error()))
-				continue
-			}
-			err = s.wal.Write(wal.Delete, []byte(req.GetKey()), s.marshal(req.GetValue()))
-			if err != nil {
-				s.log.Error("can't write to wal", zap.Error(err))
-			}
-			s.wireProtocol.Write(conn, &godis_proto.Response{})
+func (s *Server) handleRequest(c *connection, req *godis_proto.Request) *godis_proto.Response {
+	if c.subscriber != nil && !isSubscribeOperation(req.Operation) {
+		return getErrorResponse("only subscribe and unsubscribe requests are allowed in subscribe mode")
+	}
+	db, err := s.database(c, req)
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	if err := s.limits.check(req); err != nil {
+		return getLimitErrorResponse(err)
+	}
+	if s.replica != nil && isWriteOperation(req.Operation) {
+		return getCodeErrorResponse(godis_proto.ErrorCode_ReadOnly, errReadOnly.Error())
+	}
+	if err := s.checkQuota(c, db, req); err != nil {
+		return getCodeErrorResponse(godis_proto.ErrorCode_QuotaExceeded, err.Error())
+	}
 
-		case godis_proto.Operation_Keys:
-			keyReg, err := regexp.Compile(req.GetKey())
-			if err != nil {
-				s.wireProtocol.Write(conn, getErrorResponse(err.Error()))
-				continue
-			}
+	switch req.Operation {
+	case godis_proto.Operation_Get:
+		v, err := db.storage.Get(req.GetKey())
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		return &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{
+			Value: v,
+		}}
 
-			result := &syncStringSlice{}
+	case godis_proto.Operation_Set:
+		err := db.storage.Set(req.GetKey(), req.GetValue())
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		s.writeWAL(&wal.Record{Cmd: wal.Write, DB: uint32(db.index), Key: []byte(req.GetKey()), Value: s.marshal(req.GetValue())})
+		s.pubSub.notify(db.index, req.GetKey(), godis_proto.EventType_Written)
+		db.blocking.signal(req.GetKey())
+		return &godis_proto.Response{}
 
-			s.storage.ForEach(func(key string, _ *godis_proto.Value) {
-				if keyReg.MatchString(key) {
-					result.add(key)
-				}
-			})
+	case godis_proto.Operation_Remove:
+		err := db.storage.Delete(req.GetKey())
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		s.writeWAL(&wal.Record{Cmd: wal.Delete, DB: uint32(db.index), Key: []byte(req.GetKey())})
+		s.pubSub.notify(db.index, req.GetKey(), godis_proto.EventType_Removed)
+		return &godis_proto.Response{}
 
-			s.wireProtocol.Write(conn, &godis_proto.Response{
-				ResponseValue: &godis_proto.Response_Value{
-					Value: &godis_proto.Value{
-						Value: &godis_proto.Value_StringSlice{
-							StringSlice: &godis_proto.RepeatedString{
-								StringArrayVal: result.data,
-							},
+	case godis_proto.Operation_Keys:
+		keyReg, err := regexp.Compile(req.GetKey())
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+
+		result := &syncStringSlice{}
+
+		db.storage.ForEach(func(key string, _ *godis_proto.Value) {
+			if keyReg.MatchString(key) {
+				result.add(key)
+			}
+		})
+		if s.limits.MaxKeysResult > 0 && len(result.data) > s.limits.MaxKeysResult {
+			return getLimitErrorResponse(errTooManyKeys)
+		}
+
+		return &godis_proto.Response{
+			ResponseValue: &godis_proto.Response_Value{
+				Value: &godis_proto.Value{
+					Value: &godis_proto.Value_StringSlice{
+						StringSlice: &godis_proto.RepeatedString{
+							StringArrayVal: result.data,
 						},
 					},
 				},
-			})
+			},
+		}
 
-		case godis_proto.Operation_GetByIndex:
-			v, err := s.storage.Get(req.GetKey())
-			if err != nil {
-				s.wireProtocol.Write(conn, getErrorResponse(err.Error()))
-				continue
+	case godis_proto.Operation_GetByIndex:
+		v, err := db.storage.Get(req.GetKey())
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		switch t := v.GetValue().(type) {
+		case *godis_proto.Value_StringSlice:
+			arr := t.StringSlice.GetStringArrayVal()
+			if int(req.GetIndex()) > len(arr) {
+				return getErrorResponse("index out of range")
 			}
-			switch t := v.GetValue().(type) {
-			case *godis_proto.Value_StringSlice:
-				arr := t.StringSlice.GetStringArrayVal()
-				if int(req.GetIndex()) > len(arr) {
-					s.wireProtocol.Write(conn, getErrorResponse("index out of range"))
-					continue
-				}
-				s.wireProtocol.Write(conn, &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{
-					Value: &godis_proto.Value{
-						Value: &godis_proto.Value_StringVal{
-							StringVal: arr[int(req.GetIndex())],
-						},
-						Ttl: v.GetTtl(),
+			return &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{
+				Value: &godis_proto.Value{
+					Value: &godis_proto.Value_StringVal{
+						StringVal: arr[int(req.GetIndex())],
 					},
-				}})
-			default:
-				s.wireProtocol.Write(conn, getErrorResponse(fmt.Sprintf("bad key type: %T", t)))
-				continue
-			}
-		case godis_proto.Operation_GetByKey:
-			v, err := s.storage.Get(req.GetKey())
-			if err != nil {
-				s.wireProtocol.Write(conn, getErrorResponse(err.Error()))
-				continue
+					Ttl: v.GetTtl(),
+				},
+			}}
+		default:
+			return getErrorResponse(fmt.Sprintf("bad key type: %T", t))
+		}
+	case godis_proto.Operation_GetByKey:
+		v, err := db.storage.Get(req.GetKey())
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		switch t := v.GetValue().(type) {
+		case *godis_proto.Value_StringMap:
+			m := t.StringMap.GetStringMap()
+			val, ok := m[req.GetMapKey()]
+			if !ok {
+				return getErrorResponse(fmt.Sprintf("key `%s` doesn't exists", req.GetMapKey()))
 			}
-			switch t := v.GetValue().(type) {
-			case *godis_proto.Value_StringMap:
-				m := t.StringMap.GetStringMap()
-				val, ok := m[req.GetMapKey()]
-				if !ok {
-					s.wireProtocol.Write(conn, getErrorResponse(fmt.Sprintf("key `%s` doesn't exists", req.GetMapKey())))
-					continue
-				}
-				s.wireProtocol.Write(conn, &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{
-					Value: &godis_proto.Value{
-						Value: &godis_proto.Value_StringVal{
-							StringVal: val,
-						},
-						Ttl: v.GetTtl(),
+			return &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{
+				Value: &godis_proto.Value{
+					Value: &godis_proto.Value_StringVal{
+						StringVal: val,
 					},
-				}})
-			default:
-				s.wireProtocol.Write(conn, getErrorResponse(fmt.Sprintf("bad key type: %T", t)))
-				continue
-			}
-
+					Ttl: v.GetTtl(),
+				},
+			}}
 		default:
-			s.wireProtocol.Write(conn, getErrorResponse("not implemented"))
+			return getErrorResponse(fmt.Sprintf("bad key type: %T", t))
+		}
+
+	case godis_proto.Operation_Range:
+		ranger, ok := db.storage.(storage.Ranger)
+		if !ok {
+			return getErrorResponse("storage doesn't support range queries")
+		}
+		items, nextPageToken, err := ranger.Range(storage.RangeOptions{
+			Start:     req.GetKey(),
+			End:       req.GetEndKey(),
+			Limit:     int(req.GetLimit()),
+			Reverse:   req.GetReverse(),
+			PageToken: req.GetPageToken(),
+		})
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		result := &godis_proto.KeyValueList{NextPageToken: nextPageToken}
+		for _, item := range items {
+			result.Items = append(result.Items, &godis_proto.KeyValue{Key: item.Key, Value: item.Value})
+		}
+		return &godis_proto.Response{
+			ResponseValue: &godis_proto.Response_KeyValues{KeyValues: result},
+		}
+
+	case godis_proto.Operation_Publish:
+		receivers := s.pubSub.publish(req.GetKey(), req.GetPayload())
+		return getCountResponse(int64(receivers))
+
+	case godis_proto.Operation_Subscribe, godis_proto.Operation_PSubscribe, godis_proto.Operation_SubscribeKeyspace:
+		if c.subscriber == nil {
+			c.subscriber = newSubscriber(subscriberBufferSize)
+			go s.pushMessages(c)
+		}
+		var err error
+		switch req.Operation {
+		case godis_proto.Operation_Subscribe:
+			s.pubSub.subscribe(c.subscriber, req.GetChannels()...)
+		case godis_proto.Operation_PSubscribe:
+			err = s.pubSub.psubscribe(c.subscriber, req.GetChannels()...)
+		case godis_proto.Operation_SubscribeKeyspace:
+			err = s.pubSub.subscribeKeyspace(c.subscriber, db.index, req.GetKey(), req.GetEvents())
+		}
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		return getCountResponse(int64(s.pubSub.count(c.subscriber)))
+
+	case godis_proto.Operation_Unsubscribe, godis_proto.Operation_PUnsubscribe, godis_proto.Operation_UnsubscribeKeyspace:
+		if c.subscriber == nil {
+			return getCountResponse(0)
+		}
+		switch req.Operation {
+		case godis_proto.Operation_Unsubscribe:
+			s.pubSub.unsubscribe(c.subscriber, req.GetChannels()...)
+		case godis_proto.Operation_PUnsubscribe:
+			s.pubSub.punsubscribe(c.subscriber, req.GetChannels()...)
+		case godis_proto.Operation_UnsubscribeKeyspace:
+			s.pubSub.unsubscribeKeyspace(c.subscriber, db.index, req.GetChannels()...)
 		}
+		return getCountResponse(int64(s.pubSub.count(c.subscriber)))
+
+	case godis_proto.Operation_LPush, godis_proto.Operation_RPush:
+		return s.push(db, req, req.Operation == godis_proto.Operation_LPush)
+
+	case godis_proto.Operation_Expire:
+		return s.expire(db, req)
+
+	case godis_proto.Operation_HSet:
+		return s.hset(db, req)
+
+	case godis_proto.Operation_HDel:
+		return s.hdel(db, req)
+
+	case godis_proto.Operation_Incr:
+		return s.incr(db, req)
+
+	case godis_proto.Operation_LPop, godis_proto.Operation_RPop:
+		element, err := s.pop(db, req.GetKey(), req.Operation == godis_proto.Operation_LPop)
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		return &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{
+			Value: &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: element}},
+		}}
+
+	case godis_proto.Operation_BLPop, godis_proto.Operation_BRPop:
+		return s.blockingPop(db, c, req, req.Operation == godis_proto.Operation_BLPop)
+
+	case godis_proto.Operation_XAdd:
+		return s.streamAdd(db, req)
+
+	case godis_proto.Operation_XRange:
+		return s.streamRange(db, req)
+
+	case godis_proto.Operation_XLen:
+		return s.streamLen(db, req)
+
+	case godis_proto.Operation_XTrim:
+		return s.streamTrim(db, req)
+
+	case godis_proto.Operation_XRead:
+		return s.streamRead(db, c, req)
+
+	case godis_proto.Operation_XGroupCreate:
+		return s.streamGroupCreate(db, req)
+
+	case godis_proto.Operation_XReadGroup:
+		return s.streamReadGroup(db, c, req)
+
+	case godis_proto.Operation_XAck:
+		return s.streamAck(db, req)
+
+	case godis_proto.Operation_XPending:
+		return s.streamPending(db, req)
+
+	case godis_proto.Operation_QEnqueue:
+		return s.queueEnqueue(db, req)
+
+	case godis_proto.Operation_QDequeue:
+		return s.queueDequeue(db, req)
+
+	case godis_proto.Operation_QAck:
+		return s.queueAck(db, req)
+
+	case godis_proto.Operation_QNack:
+		return s.queueNack(db, req)
+
+	case godis_proto.Operation_QStats:
+		return s.queueStats(db, req)
 
+	case godis_proto.Operation_QDead:
+		return s.queueDead(db, req)
+
+	case godis_proto.Operation_LockAcquire:
+		return s.lockAcquire(db, req)
+
+	case godis_proto.Operation_LockRenew:
+		return s.lockRenew(db, req)
+
+	case godis_proto.Operation_LockRelease:
+		return s.lockRelease(db, req)
+
+	case godis_proto.Operation_RateLimit:
+		return s.rateLimit(db, req)
+
+	case godis_proto.Operation_Eval:
+		return s.eval(db, req)
+
+	case godis_proto.Operation_EvalSHA:
+		return s.evalSHA(db, req)
+
+	case godis_proto.Operation_ScriptLoad:
+		return s.scriptLoad(req)
+
+	case godis_proto.Operation_Select:
+		// database is already validated, request without database selects database 0
+		c.db = int(req.GetDatabase().GetIndex())
+		return getCountResponse(int64(c.db))
+
+	case godis_proto.Operation_FlushDB:
+		s.flush(db)
+		return getCountResponse(0)
+
+	case godis_proto.Operation_DBSize:
+		return getCountResponse(int64(db.size()))
+
+	case godis_proto.Operation_Stats:
+		return s.stats()
+
+	case godis_proto.Operation_Snapshot:
+		info, err := s.snapshot()
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		return &godis_proto.Response{ResponseValue: &godis_proto.Response_Snapshot{Snapshot: info}}
+
+	case godis_proto.Operation_Backup:
+		return s.handleBackup(c, req)
+
+	case godis_proto.Operation_Replicate:
+		return s.handleReplicate(c, req)
+
+	default:
+		return getErrorResponse("not implemented")
+	}
+}
+
+// update replaces value of key with one returned by fn, writes new value to wal and notifies keyspace subscribers.
+// Value is written under lock of key, so records of concurrent updates are written in the order they are applied.
+// Nil value means that key was deleted. Nothing is written if fn returns error
+func (s *Server) update(db *database, key string, fn storage.UpdateFunc) (*godis_proto.Value, error) {
+	v, err := db.storage.Update(key, func(old *godis_proto.Value) (*godis_proto.Value, error) {
+		v, err := fn(old)
+		if err == nil {
+			s.writeWAL(s.valueRecord(db, key, v))
+		}
+		return v, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyUpdate(db, key, v)
+	return v, nil
+}
+
+// valueRecord returns wal record which sets value of key, nil value means that key was deleted
+func (s *Server) valueRecord(db *database, key string, v *godis_proto.Value) *wal.Record {
+	if v == nil {
+		return &wal.Record{Cmd: wal.Delete, DB: uint32(db.index), Key: []byte(key)}
+	}
+	return &wal.Record{Cmd: wal.Write, DB: uint32(db.index), Key: []byte(key), Value: s.marshal(v)}
+}
+
+// notifyUpdate notifies keyspace subscribers about new value of key
+func (s *Server) notifyUpdate(db *database, key string, v *godis_proto.Value) {
+	event := godis_proto.EventType_Written
+	if v == nil {
+		event = godis_proto.EventType_Removed
+	}
+	s.pubSub.notify(db.index, key, event)
+}
+
+// pushMessages writes messages received by connection subscriber until it's closed
+func (s *Server) pushMessages(c *connection) {
+	sub := c.subscriber
+	for {
+		select {
+		case msg := <-sub.messages:
+			err := c.write(&godis_proto.Response{ResponseValue: &godis_proto.Response_Message{Message: msg}})
+			if err != nil {
+				s.connections.writeFailed(err)
+				s.log.Error("can't push message", zap.Error(err))
+				c.Close()
+				return
+			}
+		case <-sub.overflow:
+			s.log.Warn("subscriber is too slow, closing connection", zap.String("addr", c.RemoteAddr().String()))
+			c.Close()
+			return
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+func getCountResponse(count int64) *godis_proto.Response {
+	return &godis_proto.Response{
+		ResponseValue: &godis_proto.Response_Count{Count: count},
 	}
 }
 
 func getErrorResponse(err string) *godis_proto.Response {
+	return getCodeErrorResponse(godis_proto.ErrorCode_UnknownError, err)
+}
+
+func getCodeErrorResponse(code godis_proto.ErrorCode, err string) *godis_proto.Response {
 	return &godis_proto.Response{
 		ResponseValue: &godis_proto.Response_Error{
-			Error: &godis_proto.Error{Message: err},
+			Error: &godis_proto.Error{Message: err, Code: code},
 		},
 	}
 }
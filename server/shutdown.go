@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrServerClosed is returned by Run after Shutdown
+var ErrServerClosed = errors.New("server closed")
+
+const (
+	// shutdownPollInterval - how often Shutdown checks whether all connections are closed
+	shutdownPollInterval = 10 * time.Millisecond
+	// shutdownHandlersTimeout - how long Shutdown waits for handlers of closed connections to exit
+	shutdownHandlersTimeout = 5 * time.Second
+)
+
+// Shutdown stops server gracefully: it stops accepting connections, closes idle connections,
+// finishes blocked reads, waits until in-flight requests are finished, writes snapshot and closes WAL. If ctx is done before requests are finished,
+// remaining connections are closed and their handlers are awaited for at most shutdownHandlersTimeout, WAL is closed anyway and ctx error is returned
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.closing = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	close(s.stop)
+	s.mu.Unlock()
+
+	err := s.waitConnections(ctx)
+	if err != nil {
+		s.log.Warn("shutdown timeout, closing active connections", zap.Error(err))
+		s.mu.Lock()
+		for c := range s.conns {
+			c.Close()
+		}
+		s.mu.Unlock()
+		// handlers can write to WAL until they finish requests, so it's closed after they exit
+		handlersCtx, cancel := context.WithTimeout(context.Background(), shutdownHandlersTimeout)
+		if handlersErr := s.waitConnections(handlersCtx); handlersErr != nil {
+			s.log.Error("connection handlers didn't exit", zap.Error(handlersErr))
+		}
+		cancel()
+	}
+
+	if s.wal == nil {
+		return err
+	}
+	if s.snapshotsEnabled() && s.loadErr == nil {
+		if _, snapshotErr := s.snapshot(); snapshotErr != nil {
+			s.log.Error("can't write snapshot", zap.Error(snapshotErr))
+		}
+	}
+	if walErr := s.wal.Close(); walErr != nil {
+		s.log.Error("can't close wal", zap.Error(walErr))
+		if err == nil {
+			err = walErr
+		}
+	}
+	return err
+}
+
+// waitConnections closes idle connections until all connections are closed
+func (s *Server) waitConnections(ctx context.Context) error {
+	t := time.NewTicker(shutdownPollInterval)
+	defer t.Stop()
+	for {
+		s.mu.Lock()
+		for c, active := range s.conns {
+			if !active {
+				c.Close()
+			}
+		}
+		left := len(s.conns)
+		s.mu.Unlock()
+		if left == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// trackConnection registers connection as idle, it returns false if server is shutting down
+func (s *Server) trackConnection(c *connection) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return false
+	}
+	s.conns[c] = false
+	return true
+}
+
+func (s *Server) untrackConnection(c *connection) {
+	s.mu.Lock()
+	delete(s.conns, c)
+	s.mu.Unlock()
+}
+
+// setActive marks connection which is handling request, it returns false if server is shutting down
+// and request must not be started
+func (s *Server) setActive(c *connection, active bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return false
+	}
+	s.conns[c] = active
+	return true
+}
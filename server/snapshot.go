@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/snapshot"
+	"github.com/minaevmike/godis/wal"
+	"go.uber.org/zap"
+)
+
+var errSnapshotsDisabled = errors.New("snapshots are disabled")
+
+// snapshotState serializes snapshots and keeps the last one
+type snapshotState struct {
+	// mu is held while snapshot is written
+	mu sync.Mutex
+	// lastMu guards lastInfo, so stats don't wait for running snapshot
+	lastMu   sync.Mutex
+	lastInfo *godis_proto.SnapshotInfo
+}
+
+func (st *snapshotState) setLast(info *godis_proto.SnapshotInfo) {
+	st.lastMu.Lock()
+	st.lastInfo = info
+	st.lastMu.Unlock()
+}
+
+func (st *snapshotState) last() *godis_proto.SnapshotInfo {
+	st.lastMu.Lock()
+	defer st.lastMu.Unlock()
+	return st.lastInfo
+}
+
+func (s *Server) snapshotsEnabled() bool {
+	return s.snapshotFile != "" && s.durability != wal.ModeNone
+}
+
+// load loads the latest snapshot and replays wal records written after it
+func (s *Server) load() error {
+	walOptions := s.walOptions
+	if walOptions.Dump == nil {
+		walOptions.Dump = s.dumpWAL
+	}
+	if walOptions.BeginDump == nil {
+		walOptions.BeginDump = s.beginDump
+	}
+	if walOptions.Cipher == nil {
+		walOptions.Cipher = s.cipher
+	}
+	if s.snapshotsEnabled() {
+		keys := int64(0)
+		created := time.Now()
+		info, err := snapshot.ReadFile(s.snapshotFile, s.cipher, func(record *wal.Record) {
+			if record.Cmd == wal.Write {
+				keys++
+			}
+			s.replay(record)
+		})
+		switch {
+		case err == nil:
+			walOptions.ReplayFrom = info.Position
+			walOptions.StartLSN = info.LSN
+			s.snapshots.setLast(snapshotInfo(info, keys, created))
+			s.log.Info("snapshot loaded", zap.String("file", s.snapshotFile), zap.Int64("keys", keys),
+				zap.Uint64("wal_position", info.Position), zap.Duration("duration", time.Since(created)))
+		case !os.IsNotExist(err):
+			return fmt.Errorf("can't load snapshot: %v", err)
+		}
+	}
+
+	w, err := wal.New(s.walFile, s.durability, walOptions, s.log)
+	if err != nil {
+		return fmt.Errorf("can't open wal: %v", err)
+	}
+	if err := s.replayWAL(w); err != nil {
+		w.Close()
+		return fmt.Errorf("can't replay wal: %v", err)
+	}
+	s.wal = w
+	return nil
+}
+
+// replayWAL applies records of wal to databases
+func (s *Server) replayWAL(w wal.WAL) error {
+	it, err := w.Replay()
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for {
+		record, err := it.Next(context.Background())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.replay(record)
+	}
+}
+
+// snapshot writes all databases to snapshot file and truncates wal segments included into it.
+// Wal is rotated before dump, so dump contains effects of all records of older segments. Storages are
+// dumped while writes go on, so dump can contain effects of records of newer segments, they are replayed
+// after snapshot on restart and lead to the same state
+func (s *Server) snapshot() (*godis_proto.SnapshotInfo, error) {
+	if !s.snapshotsEnabled() {
+		return nil, errSnapshotsDisabled
+	}
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+
+	end, err := s.beginDump()
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+
+	created := time.Now()
+	position := uint64(0)
+	checkpointer, ok := s.wal.(wal.Checkpointer)
+	if ok {
+		if position, err = checkpointer.Checkpoint(); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := int64(0)
+	info, err := snapshot.WriteFile(s.snapshotFile, position, s.cipher, func(write func(record *wal.Record) error) (uint64, error) {
+		err := s.dumpWAL(func(record *wal.Record) error {
+			if record.Cmd == wal.Write {
+				keys++
+			}
+			return write(record)
+		})
+		return s.lastLSN(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if err := checkpointer.Truncate(position); err != nil {
+			s.log.Error("can't truncate wal after snapshot", zap.Error(err))
+		}
+	}
+
+	result := snapshotInfo(info, keys, created)
+	s.snapshots.setLast(result)
+	s.log.Info("snapshot written", zap.String("file", s.snapshotFile), zap.Int64("keys", keys),
+		zap.Int64("size", info.Size), zap.Duration("duration", time.Since(created)))
+	return result, nil
+}
+
+func snapshotInfo(info snapshot.Info, keys int64, created time.Time) *godis_proto.SnapshotInfo {
+	return &godis_proto.SnapshotInfo{
+		WalPosition: info.Position,
+		Keys:        keys,
+		Size:        info.Size,
+		Created:     created.UnixNano(),
+		Lsn:         info.LSN,
+	}
+}
+
+// snapshotLoop writes snapshots on schedule
+func (s *Server) snapshotLoop(stop chan struct{}) {
+	if !s.snapshotsEnabled() || s.snapshotInterval <= 0 {
+		return
+	}
+	t := time.NewTicker(s.snapshotInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			if _, err := s.snapshot(); err != nil {
+				s.log.Error("can't write snapshot", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package server
+
+import "github.com/minaevmike/godis/godis_proto"
+
+// stats returns usage of databases and clients
+func (s *Server) stats() *godis_proto.Response {
+	stats := &godis_proto.ServerStats{}
+	for _, db := range s.databases {
+		stats.Databases = append(stats.Databases, db.stats())
+	}
+	stats.Connections = s.connections.stats()
+	stats.LastSnapshot = s.snapshots.last()
+	stats.Replication = s.replicationStats()
+	if s.clientQuotas != nil {
+		stats.Clients = s.clientQuotas.stats()
+	}
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Stats{Stats: stats}}
+}
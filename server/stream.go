@@ -0,0 +1,682 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/wal"
+)
+
+var (
+	errStreamIDTooSmall = errors.New("entry id must be greater than the last id in stream")
+	errGroupDoesntExist = errors.New("consumer group doesn't exist")
+	errGroupExists      = errors.New("consumer group already exists")
+	errBadStreamID      = errors.New("bad stream entry id")
+	errNoStream         = errors.New("stream doesn't exist")
+)
+
+const (
+	// streamIDAuto - id is generated by server
+	streamIDAuto = "*"
+	// streamIDLast - the last id in stream at the moment of request
+	streamIDLast = "$"
+	// streamIDNew - entries which were never delivered to consumer group
+	streamIDNew = ">"
+	streamIDMin = "-"
+	streamIDMax = "+"
+)
+
+// streamID is stream entry id: unix milliseconds and sequence number within millisecond
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+func parseStreamID(id string) (streamID, error) {
+	switch id {
+	case streamIDMin, "":
+		return streamID{}, nil
+	case streamIDMax:
+		return streamID{ms: math.MaxUint64, seq: math.MaxUint64}, nil
+	}
+	parts := strings.SplitN(id, "-", 2)
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return streamID{}, errBadStreamID
+	}
+	var seq uint64
+	if len(parts) == 2 {
+		seq, err = strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return streamID{}, errBadStreamID
+		}
+	}
+	return streamID{ms: ms, seq: seq}, nil
+}
+
+func mustParseStreamID(id string) streamID {
+	sid, _ := parseStreamID(id)
+	return sid
+}
+
+func (id streamID) String() string {
+	return fmt.Sprintf("%d-%d", id.ms, id.seq)
+}
+
+func (id streamID) less(other streamID) bool {
+	return id.ms < other.ms || (id.ms == other.ms && id.seq < other.seq)
+}
+
+// searchEntries returns index of the first entry with id greater than given
+func searchEntries(entries []*godis_proto.StreamEntry, id streamID) int {
+	return sort.Search(len(entries), func(i int) bool {
+		return id.less(mustParseStreamID(entries[i].GetId()))
+	})
+}
+
+// searchFromEntries returns index of the first entry with id greater or equal to given
+func searchFromEntries(entries []*godis_proto.StreamEntry, id streamID) int {
+	return sort.Search(len(entries), func(i int) bool {
+		return !mustParseStreamID(entries[i].GetId()).less(id)
+	})
+}
+
+// getStream returns stream stored in value, nil value is treated as empty stream
+func getStream(v *godis_proto.Value) (*godis_proto.Stream, error) {
+	if v == nil {
+		return &godis_proto.Stream{}, nil
+	}
+	t, ok := v.GetValue().(*godis_proto.Value_Stream)
+	if !ok {
+		return nil, badKeyType(v)
+	}
+	return t.Stream, nil
+}
+
+func newStreamValue(stream *godis_proto.Stream, ttl int64) *godis_proto.Value {
+	return &godis_proto.Value{Value: &godis_proto.Value_Stream{Stream: stream}, Ttl: ttl}
+}
+
+// copyStream makes shallow copy of stream, entries are never modified, so they can be shared between copies
+func copyStream(stream *godis_proto.Stream) *godis_proto.Stream {
+	return &godis_proto.Stream{
+		Entries: stream.GetEntries(),
+		LastId:  stream.GetLastId(),
+		Groups:  stream.GetGroups(),
+	}
+}
+
+// trimStream removes the oldest entries, so stream contains at most maxLen entries
+func trimStream(stream *godis_proto.Stream, maxLen int) int {
+	trimmed := len(stream.Entries) - maxLen
+	if trimmed <= 0 {
+		return 0
+	}
+	stream.Entries = stream.Entries[trimmed:]
+	return trimmed
+}
+
+// trimEntries returns copy of stream value with at most maxLen the newest entries and amount of removed entries
+func trimEntries(old *godis_proto.Value, maxLen int) (*godis_proto.Value, int, error) {
+	if old == nil {
+		return nil, 0, errNoStream
+	}
+	stream, err := getStream(old)
+	if err != nil {
+		return nil, 0, err
+	}
+	stream = copyStream(stream)
+	trimmed := trimStream(stream, maxLen)
+	return newStreamValue(stream, old.GetTtl()), trimmed, nil
+}
+
+// appendEntry returns copy of stream value with entry appended, ttl is used for new stream
+func appendEntry(old *godis_proto.Value, entry *godis_proto.StreamEntry, ttl int64) (*godis_proto.Value, error) {
+	stream, err := getStream(old)
+	if err != nil {
+		return nil, err
+	}
+	if old != nil {
+		ttl = old.GetTtl()
+	}
+	if !mustParseStreamID(stream.GetLastId()).less(mustParseStreamID(entry.GetId())) {
+		return nil, errStreamIDTooSmall
+	}
+	stream = copyStream(stream)
+	// entries of old value are never appended again, so backing array can be shared
+	stream.Entries = append(stream.Entries, entry)
+	stream.LastId = entry.GetId()
+	return newStreamValue(stream, ttl), nil
+}
+
+func (s *Server) streamAdd(db *database, req *godis_proto.Request) *godis_proto.Response {
+	fields := req.GetValue().GetStringMap().GetStringMap()
+	if len(fields) == 0 {
+		return getErrorResponse("entry must contain fields")
+	}
+	id := streamIDAuto
+	if len(req.GetIds()) > 0 {
+		id = req.GetIds()[0]
+	}
+
+	var entryID streamID
+	_, err := s.mutateChanges(db, req.GetKey(), func(old *godis_proto.Value) (*godis_proto.Value, []change, error) {
+		stream, err := getStream(old)
+		if err != nil {
+			return nil, nil, err
+		}
+		if id == streamIDAuto {
+			last := mustParseStreamID(stream.GetLastId())
+			now := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+			if now > last.ms {
+				entryID = streamID{ms: now}
+			} else {
+				entryID = streamID{ms: last.ms, seq: last.seq + 1}
+			}
+		} else if entryID, err = parseStreamID(id); err != nil {
+			return nil, nil, err
+		}
+
+		entry := &godis_proto.StreamEntry{Id: entryID.String(), Fields: fields}
+		v, err := appendEntry(old, entry, req.GetValue().GetTtl())
+		if err != nil {
+			return nil, nil, err
+		}
+		changes := []change{{cmd: wal.StreamAdd, delta: newStreamValue(&godis_proto.Stream{Entries: []*godis_proto.StreamEntry{entry}}, v.GetTtl())}}
+		if maxLen := int(req.GetMaxLen()); maxLen > 0 {
+			var trimmed int
+			if v, trimmed, err = trimEntries(v, maxLen); err != nil {
+				return nil, nil, err
+			}
+			if trimmed > 0 {
+				changes = append(changes, change{cmd: wal.StreamTrim, delta: newStreamMaxLen(maxLen)})
+			}
+		}
+		return v, changes, nil
+	})
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	db.blocking.signal(req.GetKey())
+	return getStringResponse(entryID.String())
+}
+
+func newStreamMaxLen(maxLen int) *godis_proto.Value {
+	return &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: strconv.Itoa(maxLen)}}
+}
+
+func (s *Server) streamRange(db *database, req *godis_proto.Request) *godis_proto.Response {
+	v, err := db.storage.Get(req.GetKey())
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	stream, err := getStream(v)
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+
+	start, end := streamIDMin, streamIDMax
+	if ids := req.GetIds(); len(ids) > 0 {
+		start = ids[0]
+		if len(ids) > 1 {
+			end = ids[1]
+		}
+	}
+	startID, err := parseStreamID(start)
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	endID, err := parseStreamID(end)
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+
+	entries := stream.GetEntries()
+	from := searchFromEntries(entries, startID)
+	to := searchEntries(entries, endID)
+	if from > to {
+		from = to
+	}
+	entries = entries[from:to]
+
+	limit := int(req.GetLimit())
+	var result []*godis_proto.StreamEntry
+	if req.GetReverse() {
+		for i := len(entries) - 1; i >= 0 && (limit == 0 || len(result) < limit); i-- {
+			result = append(result, entries[i])
+		}
+	} else {
+		if limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+		result = entries
+	}
+	return getStreamsResponse(&godis_proto.StreamRead{Key: req.GetKey(), Entries: result})
+}
+
+func (s *Server) streamLen(db *database, req *godis_proto.Request) *godis_proto.Response {
+	v, err := db.storage.Get(req.GetKey())
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	stream, err := getStream(v)
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getCountResponse(int64(len(stream.GetEntries())))
+}
+
+func (s *Server) streamTrim(db *database, req *godis_proto.Request) *godis_proto.Response {
+	maxLen := int(req.GetMaxLen())
+	trimmed := 0
+	_, err := s.mutateChanges(db, req.GetKey(), func(old *godis_proto.Value) (*godis_proto.Value, []change, error) {
+		v, n, err := trimEntries(old, maxLen)
+		if err != nil || n == 0 {
+			return nil, nil, err
+		}
+		trimmed = n
+		return v, []change{{cmd: wal.StreamTrim, delta: newStreamMaxLen(maxLen)}}, nil
+	})
+	if err != nil && err != errNotChanged {
+		return getErrorResponse(err.Error())
+	}
+	return getCountResponse(int64(trimmed))
+}
+
+// streamRead returns entries added after given ids, if block is set and there are no such entries
+// it waits until they are added
+func (s *Server) streamRead(db *database, c *connection, req *godis_proto.Request) *godis_proto.Response {
+	keys, ids := req.GetKeys(), req.GetIds()
+	if len(keys) == 0 || len(keys) != len(ids) {
+		return getErrorResponse("every key must have id")
+	}
+
+	after := make(map[string]streamID, len(keys))
+	for i, key := range keys {
+		if ids[i] == streamIDLast {
+			after[key] = streamID{}
+			if v, err := db.storage.Get(key); err == nil {
+				stream, err := getStream(v)
+				if err != nil {
+					return getErrorResponse(err.Error())
+				}
+				after[key] = mustParseStreamID(stream.GetLastId())
+			}
+			continue
+		}
+		id, err := parseStreamID(ids[i])
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		after[key] = id
+	}
+
+	read := func(key string) (*godis_proto.StreamRead, error) {
+		v, err := db.storage.Get(key)
+		if err != nil {
+			return nil, nil
+		}
+		stream, err := getStream(v)
+		if err != nil {
+			return nil, err
+		}
+		entries := stream.GetEntries()
+		entries = entries[searchEntries(entries, after[key]):]
+		if len(entries) == 0 {
+			return nil, nil
+		}
+		if limit := int(req.GetLimit()); limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+		return &godis_proto.StreamRead{Key: key, Entries: entries}, nil
+	}
+
+	var result []*godis_proto.StreamRead
+	for _, key := range keys {
+		r, err := read(key)
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		if r != nil {
+			result = append(result, r)
+		}
+	}
+	if len(result) > 0 || !req.GetBlock() {
+		return getStreamsResponse(result...)
+	}
+
+	return s.waitStream(db, c, req, func(key string) (*godis_proto.Response, bool) {
+		r, err := read(key)
+		if err != nil {
+			return getErrorResponse(err.Error()), true
+		}
+		if r == nil {
+			return nil, false
+		}
+		return getStreamsResponse(r), true
+	})
+}
+
+func (s *Server) waitStream(db *database, c *connection, req *godis_proto.Request, try tryFunc) *godis_proto.Response {
+	cancel, stop := s.watchCancel(c)
+	resp := db.blocking.wait(req.GetKeys(), time.Duration(req.GetTimeout()), cancel, try)
+	stop()
+	if resp == nil {
+		// timeout
+		return getStreamsResponse()
+	}
+	return resp
+}
+
+func findGroup(stream *godis_proto.Stream, name string) int {
+	for i, group := range stream.GetGroups() {
+		if group.GetName() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// addGroup returns copy of stream value with consumer group added
+func addGroup(old *godis_proto.Value, group *godis_proto.StreamConsumerGroup) (*godis_proto.Value, error) {
+	if old == nil {
+		return nil, errNoStream
+	}
+	stream, err := getStream(old)
+	if err != nil {
+		return nil, err
+	}
+	if findGroup(stream, group.GetName()) >= 0 {
+		return nil, errGroupExists
+	}
+	stream = copyStream(stream)
+	groups := make([]*godis_proto.StreamConsumerGroup, 0, len(stream.Groups)+1)
+	groups = append(groups, stream.Groups...)
+	stream.Groups = append(groups, group)
+	return newStreamValue(stream, old.GetTtl()), nil
+}
+
+func (s *Server) streamGroupCreate(db *database, req *godis_proto.Request) *godis_proto.Response {
+	if req.GetGroup() == "" {
+		return getErrorResponse("group name must be set")
+	}
+	id := streamIDLast
+	if len(req.GetIds()) > 0 {
+		id = req.GetIds()[0]
+	}
+
+	_, err := s.mutateChanges(db, req.GetKey(), func(old *godis_proto.Value) (*godis_proto.Value, []change, error) {
+		stream, err := getStream(old)
+		if err != nil {
+			return nil, nil, err
+		}
+		lastDelivered := stream.GetLastId()
+		if id != streamIDLast {
+			sid, err := parseStreamID(id)
+			if err != nil {
+				return nil, nil, err
+			}
+			lastDelivered = sid.String()
+		}
+		group := &godis_proto.StreamConsumerGroup{Name: req.GetGroup(), LastDeliveredId: lastDelivered}
+		v, err := addGroup(old, group)
+		if err != nil {
+			return nil, nil, err
+		}
+		return v, []change{{cmd: wal.StreamGroupCreate, delta: newGroupDelta(group)}}, nil
+	})
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return &godis_proto.Response{}
+}
+
+func newGroupDelta(group *godis_proto.StreamConsumerGroup) *godis_proto.Value {
+	return newStreamValue(&godis_proto.Stream{Groups: []*godis_proto.StreamConsumerGroup{group}}, 0)
+}
+
+// applyGroupDelta returns copy of stream value with change of consumer group applied. With StreamDeliver
+// pending entries of delta are added to group and its last delivered id is moved, with StreamAck they are removed
+func applyGroupDelta(old *godis_proto.Value, cmd wal.Command, delta *godis_proto.StreamConsumerGroup) (*godis_proto.Value, error) {
+	if old == nil {
+		return nil, errNoStream
+	}
+	stream, err := getStream(old)
+	if err != nil {
+		return nil, err
+	}
+	i := findGroup(stream, delta.GetName())
+	if i < 0 {
+		return nil, errGroupDoesntExist
+	}
+	group := &godis_proto.StreamConsumerGroup{
+		Name:            stream.Groups[i].GetName(),
+		LastDeliveredId: stream.Groups[i].GetLastDeliveredId(),
+	}
+	switch cmd {
+	case wal.StreamDeliver:
+		group.Pending = make([]*godis_proto.StreamPendingEntry, 0, len(stream.Groups[i].GetPending())+len(delta.GetPending()))
+		group.Pending = append(group.Pending, stream.Groups[i].GetPending()...)
+		group.Pending = append(group.Pending, delta.GetPending()...)
+		group.LastDeliveredId = delta.GetLastDeliveredId()
+	case wal.StreamAck:
+		acked := make(map[string]struct{}, len(delta.GetPending()))
+		for _, p := range delta.GetPending() {
+			acked[p.GetId()] = struct{}{}
+		}
+		group.Pending = make([]*godis_proto.StreamPendingEntry, 0, len(stream.Groups[i].GetPending()))
+		for _, p := range stream.Groups[i].GetPending() {
+			if _, ok := acked[p.GetId()]; !ok {
+				group.Pending = append(group.Pending, p)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown group change %d", cmd)
+	}
+
+	stream = copyStream(stream)
+	stream.Groups = append([]*godis_proto.StreamConsumerGroup(nil), stream.Groups...)
+	stream.Groups[i] = group
+	return newStreamValue(stream, old.GetTtl()), nil
+}
+
+// updateGroup atomically changes consumer group of stream by delta returned by fn, it's applied by applyGroupDelta
+// with cmd. Nil delta means that group isn't changed
+func (s *Server) updateGroup(db *database, key, name string, cmd wal.Command, fn func(stream *godis_proto.Stream, group *godis_proto.StreamConsumerGroup) (*godis_proto.StreamConsumerGroup, error)) error {
+	_, err := s.mutateChanges(db, key, func(old *godis_proto.Value) (*godis_proto.Value, []change, error) {
+		if old == nil {
+			return nil, nil, errNoStream
+		}
+		stream, err := getStream(old)
+		if err != nil {
+			return nil, nil, err
+		}
+		i := findGroup(stream, name)
+		if i < 0 {
+			return nil, nil, errGroupDoesntExist
+		}
+		delta, err := fn(stream, stream.Groups[i])
+		if err != nil || delta == nil {
+			return nil, nil, err
+		}
+		delta.Name = name
+		v, err := applyGroupDelta(old, cmd, delta)
+		if err != nil {
+			return nil, nil, err
+		}
+		return v, []change{{cmd: cmd, delta: newGroupDelta(delta)}}, nil
+	})
+	if err == errNotChanged {
+		return nil
+	}
+	return err
+}
+
+// streamReadGroup delivers entries to consumer of group. With `>` id entries which were never delivered to group
+// are returned and added to consumer pending list, otherwise pending entries of consumer after given id are returned
+func (s *Server) streamReadGroup(db *database, c *connection, req *godis_proto.Request) *godis_proto.Response {
+	keys, ids := req.GetKeys(), req.GetIds()
+	if len(keys) == 0 || len(keys) != len(ids) {
+		return getErrorResponse("every key must have id")
+	}
+	if req.GetGroup() == "" || req.GetConsumer() == "" {
+		return getErrorResponse("group and consumer must be set")
+	}
+
+	read := func(key, id string) (*godis_proto.StreamRead, error) {
+		var entries []*godis_proto.StreamEntry
+		err := s.updateGroup(db, key, req.GetGroup(), wal.StreamDeliver, func(stream *godis_proto.Stream, group *godis_proto.StreamConsumerGroup) (*godis_proto.StreamConsumerGroup, error) {
+			all := stream.GetEntries()
+			if id != streamIDNew {
+				// history of consumer pending entries
+				after, err := parseStreamID(id)
+				if err != nil {
+					return nil, err
+				}
+				for _, p := range group.GetPending() {
+					pid := mustParseStreamID(p.GetId())
+					if p.GetConsumer() != req.GetConsumer() || !after.less(pid) {
+						continue
+					}
+					// acknowledged entries could be trimmed from stream already
+					if i := searchFromEntries(all, pid); i < len(all) && all[i].GetId() == p.GetId() {
+						entries = append(entries, all[i])
+					}
+					if limit := int(req.GetLimit()); limit > 0 && len(entries) == limit {
+						break
+					}
+				}
+				return nil, nil
+			}
+
+			entries = all[searchEntries(all, mustParseStreamID(group.GetLastDeliveredId())):]
+			if limit := int(req.GetLimit()); limit > 0 && len(entries) > limit {
+				entries = entries[:limit]
+			}
+			if len(entries) == 0 {
+				return nil, nil
+			}
+			now := time.Now().UnixNano()
+			delivered := &godis_proto.StreamConsumerGroup{LastDeliveredId: entries[len(entries)-1].GetId()}
+			for _, e := range entries {
+				delivered.Pending = append(delivered.Pending, &godis_proto.StreamPendingEntry{
+					Id:            e.GetId(),
+					Consumer:      req.GetConsumer(),
+					DeliveryTime:  now,
+					DeliveryCount: 1,
+				})
+			}
+			return delivered, nil
+		})
+		if err != nil || len(entries) == 0 {
+			return nil, err
+		}
+		return &godis_proto.StreamRead{Key: key, Entries: entries}, nil
+	}
+
+	var result []*godis_proto.StreamRead
+	for i, key := range keys {
+		r, err := read(key, ids[i])
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		if r != nil {
+			result = append(result, r)
+		}
+	}
+	if len(result) > 0 || !req.GetBlock() {
+		return getStreamsResponse(result...)
+	}
+
+	idByKey := make(map[string]string, len(keys))
+	for i, key := range keys {
+		idByKey[key] = ids[i]
+	}
+	return s.waitStream(db, c, req, func(key string) (*godis_proto.Response, bool) {
+		if idByKey[key] != streamIDNew {
+			// pending entries history can't be changed by other connections
+			return nil, false
+		}
+		r, err := read(key, streamIDNew)
+		if err != nil {
+			return getErrorResponse(err.Error()), true
+		}
+		if r == nil {
+			return nil, false
+		}
+		return getStreamsResponse(r), true
+	})
+}
+
+// streamAck removes entries from group pending list
+func (s *Server) streamAck(db *database, req *godis_proto.Request) *godis_proto.Response {
+	acked := make(map[string]struct{}, len(req.GetIds()))
+	for _, id := range req.GetIds() {
+		sid, err := parseStreamID(id)
+		if err != nil {
+			return getErrorResponse(err.Error())
+		}
+		acked[sid.String()] = struct{}{}
+	}
+
+	count := 0
+	err := s.updateGroup(db, req.GetKey(), req.GetGroup(), wal.StreamAck, func(_ *godis_proto.Stream, group *godis_proto.StreamConsumerGroup) (*godis_proto.StreamConsumerGroup, error) {
+		found := &godis_proto.StreamConsumerGroup{}
+		for _, p := range group.GetPending() {
+			if _, ok := acked[p.GetId()]; ok {
+				found.Pending = append(found.Pending, &godis_proto.StreamPendingEntry{Id: p.GetId()})
+			}
+		}
+		count = len(found.Pending)
+		if count == 0 {
+			return nil, nil
+		}
+		return found, nil
+	})
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	return getCountResponse(int64(count))
+}
+
+// streamPending returns pending entries of group, if consumer is set only its entries are returned
+func (s *Server) streamPending(db *database, req *godis_proto.Request) *godis_proto.Response {
+	v, err := db.storage.Get(req.GetKey())
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	stream, err := getStream(v)
+	if err != nil {
+		return getErrorResponse(err.Error())
+	}
+	i := findGroup(stream, req.GetGroup())
+	if i < 0 {
+		return getErrorResponse(errGroupDoesntExist.Error())
+	}
+
+	result := &godis_proto.StreamPendingList{}
+	for _, p := range stream.Groups[i].GetPending() {
+		if req.GetConsumer() == "" || p.GetConsumer() == req.GetConsumer() {
+			result.Entries = append(result.Entries, p)
+		}
+	}
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Pending{Pending: result}}
+}
+
+func getStreamsResponse(streams ...*godis_proto.StreamRead) *godis_proto.Response {
+	return &godis_proto.Response{
+		ResponseValue: &godis_proto.Response_Streams{Streams: &godis_proto.StreamReadList{Streams: streams}},
+	}
+}
+
+func getStringResponse(val string) *godis_proto.Response {
+	return &godis_proto.Response{ResponseValue: &godis_proto.Response_Value{
+		Value: &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: val}},
+	}}
+}
@@ -0,0 +1,309 @@
+// Package snapshot writes and reads point in time copies of dataset.
+//
+// Snapshot starts with header: 4 byte magic, 4 byte format version and 8 byte wal position, records follow it
+// in wal format and trailer ends it: -1 as 8 byte marker, 8 byte amount of records, 8 byte LSN, 8 byte time
+// of the end of dump and CRC32 of all previous bytes. Snapshots of version 1 have no LSN and time.
+// Records are encrypted like wal records if cipher is given, checksums cover encrypted records, so snapshot
+// is verified without key
+package snapshot
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minaevmike/godis/wal"
+)
+
+const (
+	magic   = "GSNP"
+	version = uint32(2)
+	// trailerMarker is written instead of key length of record
+	trailerMarker = -1
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorrupted is returned when snapshot is incomplete or damaged
+var ErrCorrupted = errors.New("snapshot is corrupted")
+
+// DumpFunc writes dataset as records and returns LSN of the last wal record which effects are in dataset
+type DumpFunc func(write func(record *wal.Record) error) (uint64, error)
+
+// Info describes snapshot
+type Info struct {
+	// Position - sequence number of the first wal segment which isn't included into snapshot
+	Position uint64
+	// LSN - LSN of the last wal record included into snapshot, dump is made while writes go on,
+	// so records of segments after position can be included as well up to this LSN
+	LSN uint64
+	// Time - time of the end of dump
+	Time time.Time
+	// Records - amount of records in snapshot
+	Records int64
+	// Size - size of snapshot in bytes
+	Size int64
+}
+
+// countingWriter counts written bytes and computes their checksum
+type countingWriter struct {
+	w    io.Writer
+	hash hash.Hash32
+	n    int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.hash.Write(p[:n])
+	cw.n += int64(n)
+	return n, err
+}
+
+// Write writes snapshot of records returned by dump to w, records are encrypted with c if it isn't nil
+func Write(w io.Writer, position uint64, c wal.Cipher, dump DumpFunc) (Info, error) {
+	info := Info{Position: position}
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw, hash: crc32.New(crcTable)}
+
+	header := make([]byte, len(magic)+12)
+	copy(header, magic)
+	binary.BigEndian.PutUint32(header[len(magic):], version)
+	binary.BigEndian.PutUint64(header[len(magic)+4:], position)
+	if _, err := cw.Write(header); err != nil {
+		return info, err
+	}
+
+	lsn, err := dump(func(record *wal.Record) error {
+		record, err := record.Encrypt(c)
+		if err != nil {
+			return err
+		}
+		info.Records++
+		_, err = record.WriteTo(cw)
+		return err
+	})
+	if err != nil {
+		return info, err
+	}
+	info.LSN = lsn
+	info.Time = time.Now().Round(0)
+
+	trailer := make([]byte, 32)
+	marker := int64(trailerMarker)
+	binary.BigEndian.PutUint64(trailer, uint64(marker))
+	binary.BigEndian.PutUint64(trailer[8:], uint64(info.Records))
+	binary.BigEndian.PutUint64(trailer[16:], info.LSN)
+	binary.BigEndian.PutUint64(trailer[24:], uint64(info.Time.UnixNano()))
+	if _, err := cw.Write(trailer); err != nil {
+		return info, err
+	}
+	sum := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum, cw.hash.Sum32())
+	if _, err := bw.Write(sum); err != nil {
+		return info, err
+	}
+	info.Size = cw.n + int64(len(sum))
+	return info, bw.Flush()
+}
+
+// Read reads snapshot from r and applies its records with cb. Records are applied while they are read,
+// so cb can get part of records of snapshot which turns out to be corrupted. Encrypted records are decrypted
+// with c, error is returned if it's nil or its keys don't match
+func Read(r io.Reader, c wal.Cipher, cb func(record *wal.Record)) (Info, error) {
+	return read(r, func(record *wal.Record) error {
+		record, err := record.Decrypt(c)
+		if err != nil {
+			return err
+		}
+		cb(record)
+		return nil
+	})
+}
+
+// read reads snapshot from r and passes its records to cb as they are stored
+func read(r io.Reader, cb func(record *wal.Record) error) (Info, error) {
+	var info Info
+	br := bufio.NewReader(r)
+	h := crc32.New(crcTable)
+	tee := io.TeeReader(br, h)
+
+	header := make([]byte, len(magic)+12)
+	if _, err := io.ReadFull(tee, header); err != nil {
+		return info, corrupted(err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return info, fmt.Errorf("%v: bad magic", ErrCorrupted)
+	}
+	v := binary.BigEndian.Uint32(header[len(magic):])
+	if v != 1 && v != version {
+		return info, fmt.Errorf("unsupported snapshot version %d", v)
+	}
+	info.Position = binary.BigEndian.Uint64(header[len(magic)+4:])
+	info.Size = int64(len(header))
+
+	for {
+		next, err := br.Peek(8)
+		if err != nil {
+			return info, corrupted(err)
+		}
+		if int64(binary.BigEndian.Uint64(next)) == trailerMarker {
+			break
+		}
+		record := &wal.Record{}
+		n, err := record.ReadFrom(tee)
+		info.Size += n
+		if err != nil {
+			return info, corrupted(err)
+		}
+		info.Records++
+		if err := cb(record); err != nil {
+			return info, err
+		}
+	}
+
+	// checksum of version 1 doesn't cover trailer
+	trailer := make([]byte, 16)
+	if v > 1 {
+		trailer = make([]byte, 32)
+	}
+	var (
+		sum uint32
+		err error
+	)
+	if v == 1 {
+		sum = h.Sum32()
+		_, err = io.ReadFull(br, trailer)
+	} else {
+		_, err = io.ReadFull(tee, trailer)
+		sum = h.Sum32()
+	}
+	if err != nil {
+		return info, corrupted(err)
+	}
+	stored := make([]byte, 4)
+	if _, err := io.ReadFull(br, stored); err != nil {
+		return info, corrupted(err)
+	}
+	info.Size += int64(len(trailer) + len(stored))
+	if count := int64(binary.BigEndian.Uint64(trailer[8:])); count != info.Records {
+		return info, fmt.Errorf("%v: %d records instead of %d", ErrCorrupted, info.Records, count)
+	}
+	if binary.BigEndian.Uint32(stored) != sum {
+		return info, fmt.Errorf("%v: checksum mismatch", ErrCorrupted)
+	}
+	if v > 1 {
+		info.LSN = binary.BigEndian.Uint64(trailer[16:])
+		info.Time = time.Unix(0, int64(binary.BigEndian.Uint64(trailer[24:])))
+	}
+	return info, nil
+}
+
+func corrupted(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%v: %v", ErrCorrupted, err)
+}
+
+// WriteFile writes snapshot to file atomically: it's written to temporary file which replaces file
+// after it's synced, so file always contains complete snapshot
+func WriteFile(file string, position uint64, c wal.Cipher, dump DumpFunc) (Info, error) {
+	tmp := file + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return Info{}, err
+	}
+	info, err := Write(f, position, c, dump)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, file)
+	}
+	if err == nil {
+		err = syncDir(file)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return info, err
+	}
+	return info, nil
+}
+
+// ReadFile reads snapshot from file, see Read
+func ReadFile(file string, c wal.Cipher, cb func(record *wal.Record)) (Info, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+	return Read(f, c, cb)
+}
+
+func syncDir(file string) error {
+	dir, err := os.Open(filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// ChecksumFile returns path of file with sha256 checksum of file
+func ChecksumFile(file string) string {
+	return file + ".sha256"
+}
+
+// WriteChecksumFile writes sha256 checksum of file in sha256sum format
+func WriteChecksumFile(file, sum string) error {
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(file))
+	return ioutil.WriteFile(ChecksumFile(file), []byte(line), 0644)
+}
+
+// Verify checks snapshot records and, if checksum file exists, sha256 of the whole file
+func Verify(file string) (Info, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	info, err := read(io.TeeReader(f, h), func(*wal.Record) error { return nil })
+	if err != nil {
+		return info, err
+	}
+
+	data, err := ioutil.ReadFile(ChecksumFile(file))
+	if os.IsNotExist(err) {
+		return info, nil
+	}
+	if err != nil {
+		return info, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return info, fmt.Errorf("checksum file %s is empty", ChecksumFile(file))
+	}
+	// snapshot is read by buffered reader, so rest of file is hashed as well
+	if _, err := io.Copy(h, f); err != nil {
+		return info, err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != fields[0] {
+		return info, fmt.Errorf("%v: sha256 %s doesn't match %s", ErrCorrupted, sum, fields[0])
+	}
+	return info, nil
+}
@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/minaevmike/godis/wal"
+)
+
+func dumpKeys(keys ...string) DumpFunc {
+	return func(write func(record *wal.Record) error) (uint64, error) {
+		for _, key := range keys {
+			if err := write(&wal.Record{Cmd: wal.Write, DB: 1, Key: []byte(key), Value: []byte("value")}); err != nil {
+				return 0, err
+			}
+		}
+		return uint64(len(keys)), nil
+	}
+}
+
+func TestSnapshot_WriteRead(t *testing.T) {
+	b := &bytes.Buffer{}
+	info, err := Write(b, 42, nil, dumpKeys("a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Records != 2 || info.LSN != 2 || info.Size != int64(b.Len()) {
+		t.Fatalf("unexpected info %+v, size %d", info, b.Len())
+	}
+
+	var keys []string
+	read, err := Read(bytes.NewReader(b.Bytes()), nil, func(record *wal.Record) {
+		keys = append(keys, string(record.Key))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != info || len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("unexpected snapshot %+v with keys %v", read, keys)
+	}
+}
+
+func TestSnapshot_Corrupted(t *testing.T) {
+	b := &bytes.Buffer{}
+	if _, err := Write(b, 1, nil, dumpKeys("a", "b")); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()
+
+	truncated := data[:len(data)-10]
+	if _, err := Read(bytes.NewReader(truncated), nil, func(*wal.Record) {}); err == nil {
+		t.Fatal("truncated snapshot was read")
+	}
+
+	damaged := append([]byte{}, data...)
+	damaged[len(damaged)-1] ^= 0xff
+	if _, err := Read(bytes.NewReader(damaged), nil, func(*wal.Record) {}); err == nil {
+		t.Fatal("damaged snapshot was read")
+	}
+}
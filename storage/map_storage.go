@@ -14,15 +14,47 @@ var (
 	ErrKeyExpired      = errors.New("key ttl expired")
 )
 
+const (
+	// expireSampleSize - amount of keys checked by one expire cycle
+	expireSampleSize = 20
+	// expireMaxCycles - max amount of expire cycles in one DeleteExpired call,
+	// next cycle is started only when more than quarter of sampled keys were expired
+	expireMaxCycles = 16
+)
+
 func NewMapStorage() Storage {
+	return newMapStorage()
+}
+
+func newMapStorage() *mapStorage {
 	return &mapStorage{
 		m: make(map[string]*godis_proto.Value),
 	}
 }
 
 type mapStorage struct {
-	m  map[string]*godis_proto.Value
-	mu sync.RWMutex
+	m        map[string]*godis_proto.Value
+	mu       sync.RWMutex
+	onExpire ExpireFunc
+	// size - total size of keys and values, guarded by mu
+	size int64
+}
+
+// set must be called under exclusive lock
+func (ms *mapStorage) set(key string, value *godis_proto.Value) {
+	if old, ok := ms.m[key]; ok {
+		ms.size -= entrySize(key, old)
+	}
+	ms.m[key] = value
+	ms.size += entrySize(key, value)
+}
+
+// delete must be called under exclusive lock
+func (ms *mapStorage) delete(key string) {
+	if old, ok := ms.m[key]; ok {
+		ms.size -= entrySize(key, old)
+		delete(ms.m, key)
+	}
 }
 
 func (ms *mapStorage) Get(key string) (*godis_proto.Value, error) {
@@ -34,7 +66,7 @@ func (ms *mapStorage) Get(key string) (*godis_proto.Value, error) {
 	}
 	if time.Now().UnixNano() > v.Ttl {
 		//key expired
-		ms.Delete(key)
+		ms.deleteExpired([]string{key})
 		return nil, ErrKeyExpired
 	}
 	return v, nil
@@ -42,18 +74,44 @@ func (ms *mapStorage) Get(key string) (*godis_proto.Value, error) {
 
 func (ms *mapStorage) Set(key string, value *godis_proto.Value) error {
 	ms.mu.Lock()
-	ms.m[key] = value
+	ms.set(key, value)
 	ms.mu.Unlock()
 	return nil
 }
 
 func (ms *mapStorage) Delete(key string) error {
 	ms.mu.Lock()
-	delete(ms.m, key)
+	ms.delete(key)
 	ms.mu.Unlock()
 	return nil
 }
 
+func (ms *mapStorage) Update(key string, fn UpdateFunc) (*godis_proto.Value, error) {
+	ms.mu.Lock()
+	old, ok := ms.m[key]
+	expired := ok && time.Now().UnixNano() > old.Ttl
+	if expired {
+		old = nil
+	}
+	v, err := fn(old)
+	if err != nil {
+		ms.mu.Unlock()
+		return nil, err
+	}
+	if v == nil {
+		ms.delete(key)
+	} else {
+		ms.set(key, v)
+	}
+	onExpire := ms.onExpire
+	ms.mu.Unlock()
+
+	if expired && onExpire != nil {
+		onExpire(key)
+	}
+	return v, nil
+}
+
 func (ms *mapStorage) ForEach(fn ForEachFunc) {
 	ms.mu.RLock()
 	now := time.Now().UnixNano()
@@ -70,13 +128,144 @@ func (ms *mapStorage) ForEach(fn ForEachFunc) {
 	ms.mu.RUnlock()
 
 	if len(keysToDelete) > 0 {
-		go func() {
-			ms.mu.Lock()
-			for _, key := range keysToDelete {
-				delete(ms.m, key)
+		go ms.deleteExpired(keysToDelete)
+	}
+
+}
+
+func (ms *mapStorage) Len() int {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return len(ms.m)
+}
+
+func (ms *mapStorage) Size() int64 {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.size
+}
+
+func (ms *mapStorage) OnExpire(fn ExpireFunc) {
+	ms.mu.Lock()
+	ms.onExpire = fn
+	ms.mu.Unlock()
+}
+
+// deleteExpired deletes given keys if they are still expired,
+// key could be overwritten since it was found expired
+func (ms *mapStorage) deleteExpired(keys []string) {
+	var deleted []string
+	ms.mu.Lock()
+	now := time.Now().UnixNano()
+	for _, key := range keys {
+		v, ok := ms.m[key]
+		if ok && now > v.Ttl {
+			ms.delete(key)
+			deleted = append(deleted, key)
+		}
+	}
+	onExpire := ms.onExpire
+	ms.mu.Unlock()
+
+	if onExpire != nil {
+		for _, key := range deleted {
+			onExpire(key)
+		}
+	}
+}
+
+func (ms *mapStorage) DeleteExpired() int {
+	total := 0
+	for i := 0; i < expireMaxCycles; i++ {
+		var expired []string
+		checked := 0
+		ms.mu.RLock()
+		now := time.Now().UnixNano()
+		// map iteration order is random, so every cycle checks random keys
+		for k, v := range ms.m {
+			if now > v.Ttl {
+				expired = append(expired, k)
+			}
+			checked++
+			if checked == expireSampleSize {
+				break
 			}
-			ms.mu.Unlock()
-		}()
+		}
+		ms.mu.RUnlock()
+
+		if len(expired) > 0 {
+			ms.deleteExpired(expired)
+		}
+		total += len(expired)
+		if len(expired)*4 <= checked {
+			break
+		}
 	}
+	return total
+}
 
+func (ms *mapStorage) Atomically(fn AtomicFunc) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return fn(mapView{ms})
+}
+
+// mapView accesses map storage without locking, it's used under exclusive lock
+type mapView struct {
+	ms *mapStorage
+}
+
+func (v mapView) Get(key string) (*godis_proto.Value, error) {
+	value, ok := v.ms.m[key]
+	if !ok {
+		return nil, ErrKeyDoesntExists
+	}
+	if time.Now().UnixNano() > value.Ttl {
+		return nil, ErrKeyExpired
+	}
+	return value, nil
+}
+
+func (v mapView) Set(key string, value *godis_proto.Value) error {
+	v.ms.set(key, value)
+	return nil
+}
+
+func (v mapView) Delete(key string) error {
+	v.ms.delete(key)
+	return nil
+}
+
+func (v mapView) Update(key string, fn UpdateFunc) (*godis_proto.Value, error) {
+	old, err := v.Get(key)
+	if err != nil {
+		old = nil
+	}
+	value, err := fn(old)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		v.ms.delete(key)
+	} else {
+		v.ms.set(key, value)
+	}
+	return value, nil
+}
+
+func (v mapView) Len() int {
+	return len(v.ms.m)
+}
+
+func (v mapView) Size() int64 {
+	return v.ms.size
+}
+
+func (v mapView) ForEach(fn ForEachFunc) {
+	now := time.Now().UnixNano()
+	for key, value := range v.ms.m {
+		if now <= value.Ttl {
+			fn(key, value)
+		}
+	}
 }
@@ -22,6 +22,7 @@ import (
 	"math/rand"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/minaevmike/godis/godis_proto"
 )
@@ -134,3 +135,88 @@ func benchForEach(storage Storage, b *testing.B) {
 		})
 	}
 }
+
+func TestStorage_Atomically(t *testing.T) {
+	storages := map[string]Storage{
+		"map":     NewMapStorage(),
+		"sharded": NewShardMapStorage(8),
+		"ordered": NewOrderedStorage(),
+	}
+	for name, st := range storages {
+		ttl := time.Now().Add(time.Hour).UnixNano()
+		st.Set("a", &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: "1"}, Ttl: ttl})
+		expired := &godis_proto.Value{Ttl: time.Now().Add(-time.Hour).UnixNano()}
+		st.Set("expired", expired)
+
+		done := make(chan struct{})
+		err := st.(Atomic).Atomically(func(view Storage) error {
+			go func() {
+				// blocked until fn returns
+				st.Set("a", &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: "3"}, Ttl: ttl})
+				close(done)
+			}()
+			time.Sleep(10 * time.Millisecond)
+
+			v, err := view.Get("a")
+			if err != nil || v.GetStringVal() != "1" {
+				t.Fatalf("%s: unexpected value %v, %v", name, v, err)
+			}
+			if _, err = view.Get("expired"); err != ErrKeyExpired {
+				t.Fatalf("%s: expected expired key, got %v", name, err)
+			}
+			a := &godis_proto.Value{Value: &godis_proto.Value_StringVal{StringVal: "2"}, Ttl: ttl}
+			view.Set("a", a)
+			view.Delete("b")
+			count := 0
+			view.ForEach(func(key string, _ *godis_proto.Value) {
+				count++
+			})
+			if count != 1 {
+				t.Fatalf("%s: expected 1 key, got %d", name, count)
+			}
+			// view is used under lock, so it must not lock storage again
+			if n := view.(Counter).Len(); n != 2 {
+				t.Fatalf("%s: expected 2 keys with expired one, got %d", name, n)
+			}
+			if size := view.(Sizer).Size(); size != entrySize("a", a)+entrySize("expired", expired) {
+				t.Fatalf("%s: unexpected size %d", name, size)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-done
+		v, _ := st.Get("a")
+		if v.GetStringVal() != "3" {
+			t.Fatalf("%s: write wasn't applied after atomic function, got %v", name, v)
+		}
+	}
+}
+
+func TestStorage_LenSize(t *testing.T) {
+	storages := map[string]Storage{
+		"map":     NewMapStorage(),
+		"sharded": NewShardMapStorage(8),
+		"ordered": NewOrderedStorage(),
+	}
+	for name, st := range storages {
+		ttl := time.Now().Add(time.Hour).UnixNano()
+		for _, key := range []string{"a", "b", "c", "a"} {
+			st.Set(key, &godis_proto.Value{Ttl: ttl})
+		}
+		st.Delete("b")
+		st.Delete("missing")
+		if n := st.(Counter).Len(); n != 2 {
+			t.Fatalf("%s: expected 2 keys, got %d", name, n)
+		}
+		if size := st.(Sizer).Size(); size != 2*entrySize("a", &godis_proto.Value{Ttl: ttl}) {
+			t.Fatalf("%s: unexpected size %d", name, size)
+		}
+		st.Delete("a")
+		st.Delete("c")
+		if size := st.(Sizer).Size(); size != 0 {
+			t.Fatalf("%s: expected zero size, got %d", name, size)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+var ErrBadPageToken = errors.New("bad page token")
+
+const (
+	skipListMaxLevel = 32
+	skipListP        = 0.25
+)
+
+type skipListNode struct {
+	key   string
+	value *godis_proto.Value
+	prev  *skipListNode
+	next  []*skipListNode
+}
+
+// orderedStorage keeps keys in skip list, so they can be iterated in lexicographical order
+type orderedStorage struct {
+	head   *skipListNode
+	tail   *skipListNode
+	level  int
+	length int
+	// size - total size of keys and values
+	size     int64
+	rnd      *rand.Rand
+	mu       sync.RWMutex
+	onExpire ExpireFunc
+	// expireCursor - key from which next DeleteExpired call continues scan, guarded by mu
+	expireCursor string
+}
+
+func NewOrderedStorage() Storage {
+	return &orderedStorage{
+		head:  &skipListNode{next: make([]*skipListNode, skipListMaxLevel)},
+		level: 1,
+		rnd:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (st *orderedStorage) randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && st.rnd.Float64() < skipListP {
+		level++
+	}
+	return level
+}
+
+// findGreaterOrEqual returns first node with key >= given key, update is filled with
+// last nodes on every level which keys are less than given key
+func (st *orderedStorage) findGreaterOrEqual(key string, update []*skipListNode) *skipListNode {
+	x := st.head
+	for i := st.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && x.next[i].key < key {
+			x = x.next[i]
+		}
+		if update != nil {
+			update[i] = x
+		}
+	}
+	return x.next[0]
+}
+
+// findLess returns last node with key < given key or nil
+func (st *orderedStorage) findLess(key string) *skipListNode {
+	x := st.findGreaterOrEqual(key, nil)
+	if x == nil {
+		return st.tail
+	}
+	return x.prev
+}
+
+func (st *orderedStorage) Get(key string) (*godis_proto.Value, error) {
+	st.mu.RLock()
+	x := st.findGreaterOrEqual(key, nil)
+	if x == nil || x.key != key {
+		st.mu.RUnlock()
+		return nil, ErrKeyDoesntExists
+	}
+	v := x.value
+	st.mu.RUnlock()
+	if time.Now().UnixNano() > v.Ttl {
+		//key expired
+		st.deleteExpired([]string{key})
+		return nil, ErrKeyExpired
+	}
+	return v, nil
+}
+
+func (st *orderedStorage) Set(key string, value *godis_proto.Value) error {
+	st.mu.Lock()
+	st.set(key, value)
+	st.mu.Unlock()
+	return nil
+}
+
+// set must be called under exclusive lock
+func (st *orderedStorage) set(key string, value *godis_proto.Value) {
+	update := make([]*skipListNode, skipListMaxLevel)
+	x := st.findGreaterOrEqual(key, update)
+	if x != nil && x.key == key {
+		st.size += entrySize(key, value) - entrySize(key, x.value)
+		x.value = value
+		return
+	}
+
+	level := st.randomLevel()
+	if level > st.level {
+		for i := st.level; i < level; i++ {
+			update[i] = st.head
+		}
+		st.level = level
+	}
+
+	st.length++
+	st.size += entrySize(key, value)
+	x = &skipListNode{key: key, value: value, next: make([]*skipListNode, level)}
+	for i := 0; i < level; i++ {
+		x.next[i] = update[i].next[i]
+		update[i].next[i] = x
+	}
+	if update[0] != st.head {
+		x.prev = update[0]
+	}
+	if x.next[0] != nil {
+		x.next[0].prev = x
+	} else {
+		st.tail = x
+	}
+}
+
+func (st *orderedStorage) Update(key string, fn UpdateFunc) (*godis_proto.Value, error) {
+	st.mu.Lock()
+	var old *godis_proto.Value
+	expired := false
+	x := st.findGreaterOrEqual(key, nil)
+	if x != nil && x.key == key {
+		old = x.value
+		if time.Now().UnixNano() > old.Ttl {
+			old = nil
+			expired = true
+		}
+	}
+	v, err := fn(old)
+	if err != nil {
+		st.mu.Unlock()
+		return nil, err
+	}
+	if v == nil {
+		st.delete(key)
+	} else {
+		st.set(key, v)
+	}
+	onExpire := st.onExpire
+	st.mu.Unlock()
+
+	if expired && onExpire != nil {
+		onExpire(key)
+	}
+	return v, nil
+}
+
+func (st *orderedStorage) Delete(key string) error {
+	st.mu.Lock()
+	st.delete(key)
+	st.mu.Unlock()
+	return nil
+}
+
+// delete must be called under exclusive lock
+func (st *orderedStorage) delete(key string) {
+	update := make([]*skipListNode, skipListMaxLevel)
+	x := st.findGreaterOrEqual(key, update)
+	if x == nil || x.key != key {
+		return
+	}
+	st.length--
+	st.size -= entrySize(key, x.value)
+	for i := 0; i < st.level; i++ {
+		if update[i].next[i] != x {
+			break
+		}
+		update[i].next[i] = x.next[i]
+	}
+	if x.next[0] != nil {
+		x.next[0].prev = x.prev
+	} else {
+		st.tail = x.prev
+	}
+	for st.level > 1 && st.head.next[st.level-1] == nil {
+		st.level--
+	}
+}
+
+// deleteExpired deletes given keys if they are still expired,
+// key could be overwritten since it was found expired
+func (st *orderedStorage) deleteExpired(keys []string) {
+	var deleted []string
+	st.mu.Lock()
+	now := time.Now().UnixNano()
+	for _, key := range keys {
+		x := st.findGreaterOrEqual(key, nil)
+		if x != nil && x.key == key && now > x.value.Ttl {
+			st.delete(key)
+			deleted = append(deleted, key)
+		}
+	}
+	onExpire := st.onExpire
+	st.mu.Unlock()
+
+	if onExpire != nil {
+		for _, key := range deleted {
+			onExpire(key)
+		}
+	}
+}
+
+func (st *orderedStorage) Len() int {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.length
+}
+
+func (st *orderedStorage) Size() int64 {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.size
+}
+
+func (st *orderedStorage) OnExpire(fn ExpireFunc) {
+	st.mu.Lock()
+	st.onExpire = fn
+	st.mu.Unlock()
+}
+
+// DeleteExpired scans part of keys starting from the place where previous call stopped
+func (st *orderedStorage) DeleteExpired() int {
+	var expired []string
+	st.mu.Lock()
+	now := time.Now().UnixNano()
+	x := st.findGreaterOrEqual(st.expireCursor, nil)
+	for i := 0; x != nil && i < expireSampleSize*expireMaxCycles; i++ {
+		if now > x.value.Ttl {
+			expired = append(expired, x.key)
+		}
+		x = x.next[0]
+	}
+	st.expireCursor = ""
+	if x != nil {
+		st.expireCursor = x.key
+	}
+	st.mu.Unlock()
+
+	if len(expired) > 0 {
+		st.deleteExpired(expired)
+	}
+	return len(expired)
+}
+
+func (st *orderedStorage) ForEach(fn ForEachFunc) {
+	st.mu.RLock()
+	now := time.Now().UnixNano()
+	var keysToDelete []string
+	for x := st.head.next[0]; x != nil; x = x.next[0] {
+		if now > x.value.Ttl {
+			keysToDelete = append(keysToDelete, x.key)
+		} else {
+			fn(x.key, x.value)
+		}
+	}
+	st.mu.RUnlock()
+
+	if len(keysToDelete) > 0 {
+		go st.deleteExpired(keysToDelete)
+	}
+}
+
+func (st *orderedStorage) Range(opts RangeOptions) ([]KeyValue, string, error) {
+	start, end := opts.Start, opts.End
+	afterStart := false
+	if opts.PageToken != "" {
+		last, err := base64.RawURLEncoding.DecodeString(opts.PageToken)
+		if err != nil {
+			return nil, "", ErrBadPageToken
+		}
+		if opts.Reverse {
+			if end == "" || string(last) < end {
+				end = string(last)
+			}
+		} else if string(last) >= start {
+			start = string(last)
+			afterStart = true
+		}
+	}
+
+	inRange := func(x *skipListNode) bool {
+		if x == nil {
+			return false
+		}
+		if x.key < start || (afterStart && x.key == start) {
+			return false
+		}
+		return end == "" || x.key < end
+	}
+
+	st.mu.RLock()
+	var x *skipListNode
+	if opts.Reverse {
+		if end == "" {
+			x = st.tail
+		} else {
+			x = st.findLess(end)
+		}
+	} else {
+		x = st.findGreaterOrEqual(start, nil)
+		if afterStart && x != nil && x.key == start {
+			x = x.next[0]
+		}
+	}
+
+	now := time.Now().UnixNano()
+	var (
+		result        []KeyValue
+		keysToDelete  []string
+		nextPageToken string
+	)
+	for ; inRange(x); x = st.step(x, opts.Reverse) {
+		if now > x.value.Ttl {
+			keysToDelete = append(keysToDelete, x.key)
+			continue
+		}
+		if opts.Limit > 0 && len(result) == opts.Limit {
+			nextPageToken = base64.RawURLEncoding.EncodeToString([]byte(result[len(result)-1].Key))
+			break
+		}
+		result = append(result, KeyValue{Key: x.key, Value: x.value})
+	}
+	st.mu.RUnlock()
+
+	if len(keysToDelete) > 0 {
+		go st.deleteExpired(keysToDelete)
+	}
+
+	return result, nextPageToken, nil
+}
+
+func (st *orderedStorage) step(x *skipListNode, reverse bool) *skipListNode {
+	if reverse {
+		return x.prev
+	}
+	return x.next[0]
+}
+
+func (st *orderedStorage) Atomically(fn AtomicFunc) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return fn(orderedView{st})
+}
+
+// orderedView accesses ordered storage without locking, it's used under exclusive lock
+type orderedView struct {
+	st *orderedStorage
+}
+
+func (v orderedView) Get(key string) (*godis_proto.Value, error) {
+	x := v.st.findGreaterOrEqual(key, nil)
+	if x == nil || x.key != key {
+		return nil, ErrKeyDoesntExists
+	}
+	if time.Now().UnixNano() > x.value.Ttl {
+		return nil, ErrKeyExpired
+	}
+	return x.value, nil
+}
+
+func (v orderedView) Set(key string, value *godis_proto.Value) error {
+	v.st.set(key, value)
+	return nil
+}
+
+func (v orderedView) Delete(key string) error {
+	v.st.delete(key)
+	return nil
+}
+
+func (v orderedView) Update(key string, fn UpdateFunc) (*godis_proto.Value, error) {
+	old, err := v.Get(key)
+	if err != nil {
+		old = nil
+	}
+	value, err := fn(old)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		v.st.delete(key)
+	} else {
+		v.st.set(key, value)
+	}
+	return value, nil
+}
+
+func (v orderedView) Len() int {
+	return v.st.length
+}
+
+func (v orderedView) Size() int64 {
+	return v.st.size
+}
+
+func (v orderedView) ForEach(fn ForEachFunc) {
+	now := time.Now().UnixNano()
+	for x := v.st.head.next[0]; x != nil; x = x.next[0] {
+		if now <= x.value.Ttl {
+			fn(x.key, x.value)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/godis_proto"
+)
+
+func rangeKeys(t *testing.T, st Storage, opts RangeOptions) ([]string, string) {
+	items, token, err := st.(Ranger).Range(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	return keys, token
+}
+
+func equalKeys(t *testing.T, got, exp []string) {
+	if len(got) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	for i := range got {
+		if got[i] != exp[i] {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+}
+
+func TestOrderedStorage_Range(t *testing.T) {
+	st := NewOrderedStorage()
+	var all []string
+	for i := 0; i < 1000; i++ {
+		key := randSeq(8)
+		all = append(all, key)
+		st.Set(key, &godis_proto.Value{Ttl: time.Now().Add(time.Hour).UnixNano()})
+	}
+	sort.Strings(all)
+	all = all[:0:0]
+	st.ForEach(func(key string, _ *godis_proto.Value) {
+		all = append(all, key)
+	})
+	if !sort.StringsAreSorted(all) {
+		t.Fatal("ForEach must iterate keys in order")
+	}
+
+	keys, token := rangeKeys(t, st, RangeOptions{})
+	equalKeys(t, keys, all)
+	if token != "" {
+		t.Fatal("unexpected page token")
+	}
+
+	// paginate forward
+	var paged []string
+	for {
+		keys, token = rangeKeys(t, st, RangeOptions{Start: all[100], End: all[900], Limit: 33, PageToken: token})
+		paged = append(paged, keys...)
+		if token == "" {
+			break
+		}
+	}
+	equalKeys(t, paged, all[100:900])
+
+	// paginate backward
+	paged = paged[:0]
+	for {
+		keys, token = rangeKeys(t, st, RangeOptions{Start: all[100], End: all[900], Limit: 33, Reverse: true, PageToken: token})
+		paged = append(paged, keys...)
+		if token == "" {
+			break
+		}
+	}
+	for i, j := 0, len(paged)-1; i < j; i, j = i+1, j-1 {
+		paged[i], paged[j] = paged[j], paged[i]
+	}
+	equalKeys(t, paged, all[100:900])
+
+	for _, key := range all[:500] {
+		st.Delete(key)
+	}
+	keys, _ = rangeKeys(t, st, RangeOptions{})
+	equalKeys(t, keys, all[500:])
+	keys, _ = rangeKeys(t, st, RangeOptions{Reverse: true, Limit: 1})
+	equalKeys(t, keys, all[len(all)-1:])
+}
+
+func TestOrderedStorage_RangeSkipsExpired(t *testing.T) {
+	st := NewOrderedStorage()
+	st.Set("a", &godis_proto.Value{Ttl: time.Now().Add(time.Hour).UnixNano()})
+	st.Set("b", &godis_proto.Value{})
+	st.Set("c", &godis_proto.Value{Ttl: time.Now().Add(time.Hour).UnixNano()})
+
+	keys, _ := rangeKeys(t, st, RangeOptions{})
+	equalKeys(t, keys, []string{"a", "c"})
+
+	if _, err := st.Get("b"); err != ErrKeyExpired && err != ErrKeyDoesntExists {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
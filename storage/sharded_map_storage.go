@@ -16,9 +16,7 @@ func NewShardMapStorage(shards int) Storage {
 	out := &shardMapStorage{}
 
 	for i := 0; i < shards; i++ {
-		out.shards = append(out.shards, &mapStorage{
-			m: make(map[string]*godis_proto.Value),
-		})
+		out.shards = append(out.shards, newMapStorage())
 	}
 	out.shardsCount = uint64(shards)
 	return out
@@ -40,6 +38,10 @@ func (s *shardMapStorage) Delete(key string) error {
 	return s.getShard(key).Delete(key)
 }
 
+func (s *shardMapStorage) Update(key string, fn UpdateFunc) (*godis_proto.Value, error) {
+	return s.getShard(key).Update(key, fn)
+}
+
 func (s *shardMapStorage) ForEach(fn ForEachFunc) {
 	wg := &sync.WaitGroup{}
 	for _, shard := range s.shards {
@@ -52,3 +54,95 @@ func (s *shardMapStorage) ForEach(fn ForEachFunc) {
 
 	wg.Wait()
 }
+
+func (s *shardMapStorage) Len() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Len()
+	}
+	return total
+}
+
+func (s *shardMapStorage) Size() int64 {
+	var total int64
+	for _, shard := range s.shards {
+		total += shard.Size()
+	}
+	return total
+}
+
+func (s *shardMapStorage) OnExpire(fn ExpireFunc) {
+	for _, shard := range s.shards {
+		shard.OnExpire(fn)
+	}
+}
+
+func (s *shardMapStorage) DeleteExpired() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.DeleteExpired()
+	}
+	return total
+}
+
+// Atomically locks all shards in the same order, so concurrent calls can't deadlock
+func (s *shardMapStorage) Atomically(fn AtomicFunc) error {
+	view := &shardMapView{s: s, shards: make([]mapView, len(s.shards))}
+	for i, shard := range s.shards {
+		shard.mu.Lock()
+		view.shards[i] = mapView{shard}
+	}
+	defer func() {
+		for _, shard := range s.shards {
+			shard.mu.Unlock()
+		}
+	}()
+	return fn(view)
+}
+
+type shardMapView struct {
+	s      *shardMapStorage
+	shards []mapView
+}
+
+func (v *shardMapView) getShard(key string) mapView {
+	return v.shards[xxhash.Sum64String(key)%v.s.shardsCount]
+}
+
+func (v *shardMapView) Get(key string) (*godis_proto.Value, error) {
+	return v.getShard(key).Get(key)
+}
+
+func (v *shardMapView) Set(key string, value *godis_proto.Value) error {
+	return v.getShard(key).Set(key, value)
+}
+
+func (v *shardMapView) Delete(key string) error {
+	return v.getShard(key).Delete(key)
+}
+
+func (v *shardMapView) Update(key string, fn UpdateFunc) (*godis_proto.Value, error) {
+	return v.getShard(key).Update(key, fn)
+}
+
+func (v *shardMapView) Len() int {
+	total := 0
+	for _, shard := range v.shards {
+		total += shard.Len()
+	}
+	return total
+}
+
+func (v *shardMapView) Size() int64 {
+	var total int64
+	for _, shard := range v.shards {
+		total += shard.Size()
+	}
+	return total
+}
+
+func (v *shardMapView) ForEach(fn ForEachFunc) {
+	for _, shard := range v.shards {
+		shard.ForEach(fn)
+	}
+}
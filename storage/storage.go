@@ -1,9 +1,17 @@
 package storage
 
-import "github.com/minaevmike/godis/godis_proto"
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/minaevmike/godis/godis_proto"
+)
 
 type ForEachFunc func(key string, value *godis_proto.Value)
 
+// UpdateFunc receives current value of key, nil if key doesn't exist or expired, and returns new value.
+// Returned nil value deletes key. Current value must not be modified, modified copy must be returned instead.
+// If error is returned storage is not changed
+type UpdateFunc func(value *godis_proto.Value) (*godis_proto.Value, error)
+
 // Storage interface describes low level storage api
 type Storage interface {
 	// Get - gets value from storage by key
@@ -14,4 +22,77 @@ type Storage interface {
 	Delete(key string) error
 	// ForEach - executes given function with data in storage. fn can be called in separate goroutines
 	ForEach(fn ForEachFunc)
+	// Update - atomically replaces value of key with value returned by fn and returns it.
+	// fn is called under lock, so it must be fast
+	Update(key string, fn UpdateFunc) (*godis_proto.Value, error)
+}
+
+// RangeOptions describes bounds of ordered iteration over keys
+type RangeOptions struct {
+	// Start - first key of the range (inclusive), empty means from the first key
+	Start string
+	// End - last key of the range (exclusive), empty means up to the last key
+	End string
+	// Limit - max amount of returned items, zero means no limit
+	Limit int
+	// Reverse - iterate from the end of the range to its start
+	Reverse bool
+	// PageToken - token returned by previous call, continues iteration after the last returned key
+	PageToken string
+}
+
+type KeyValue struct {
+	Key   string
+	Value *godis_proto.Value
+}
+
+// Ranger is implemented by storages which keep keys in order
+type Ranger interface {
+	// Range - returns items in given range ordered by key and token for the next page.
+	// Token is empty when there are no more items
+	Range(opts RangeOptions) ([]KeyValue, string, error)
+}
+
+// ExpireFunc is called with key which was deleted from storage because its ttl expired
+type ExpireFunc func(key string)
+
+// ExpireNotifier is implemented by storages which can report expired keys
+type ExpireNotifier interface {
+	// OnExpire - sets function which is called after expired key is deleted, it must not block
+	OnExpire(fn ExpireFunc)
+}
+
+// Expirer is implemented by storages which can actively delete expired keys
+type Expirer interface {
+	// DeleteExpired - deletes expired keys, returns amount of deleted keys.
+	// Storage may check only part of keys, so it should be called periodically
+	DeleteExpired() int
+}
+
+// Counter is implemented by storages which know amount of their keys
+type Counter interface {
+	// Len - returns amount of keys, expired keys which weren't deleted yet are counted too
+	Len() int
+}
+
+// Sizer is implemented by storages which account size of stored data
+type Sizer interface {
+	// Size - returns total size in bytes of keys and encoded values, expired keys which weren't deleted yet are counted too
+	Size() int64
+}
+
+// entrySize - size of key with value accounted by Sizer
+func entrySize(key string, value *godis_proto.Value) int64 {
+	return int64(len(key) + proto.Size(value))
+}
+
+// AtomicFunc receives view of storage which operations are applied without locking,
+// view must not be used after function returns
+type AtomicFunc func(view Storage) error
+
+// Atomic is implemented by storages which can apply several operations atomically
+type Atomic interface {
+	// Atomically - calls fn while all other operations on storage are blocked, so fn must be fast.
+	// Expired keys aren't deleted by view, they are just treated as missing
+	Atomically(fn AtomicFunc) error
 }
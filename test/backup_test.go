@@ -0,0 +1,178 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+// restoreServer restores backup into files of new address and starts server on it
+func restoreServer(t *testing.T, backup string, opts ...server.Option) (*server.Server, string) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	err := server.Restore(backup, filepath.Join(walDir, addr+".snapshot"), filepath.Join(walDir, addr+".wal"))
+	assert.Nil(t, err)
+	return startServer(addr, opts...), addr
+}
+
+func TestServer_Backup(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeGroupCommit)
+	s := startServer(addr, durability)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, cl.SetString(fmt.Sprintf("key%d", i), "value", time.Hour))
+	}
+	assert.Nil(t, cl.DB(3).SetString("other", "value", time.Hour))
+
+	// writes go on while backup is taken
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			cl.SetString(fmt.Sprintf("concurrent%d", i), "value", time.Hour)
+		}
+	}()
+	file := filepath.Join(walDir, addr+".backup")
+	info, err := cl.BackupToFile(file)
+	close(stop)
+	wg.Wait()
+	assert.Nil(t, err)
+	assert.True(t, info.Keys >= 101)
+	_, err = os.Stat(file + ".sha256")
+	assert.Nil(t, err)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	restored, restoredAddr := restoreServer(t, file, durability)
+	cl, err = client.Dial(restoredAddr)
+	assert.Nil(t, err)
+	for i := 0; i < 100; i++ {
+		val, err := cl.GetString(fmt.Sprintf("key%d", i))
+		assert.Nil(t, err)
+		assert.Equal(t, val, "value")
+	}
+	val, err := cl.DB(3).GetString("other")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	restored.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_BackupSlowClient(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	// backup is larger than socket buffers, so server can't send it to client which doesn't read it
+	value := strings.Repeat("v", 64<<10)
+	for i := 0; i < 256; i++ {
+		assert.Nil(t, cl.SetString(fmt.Sprintf("key%d", i), value, time.Hour))
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	assert.Nil(t, conn.(*net.TCPConn).SetReadBuffer(4096))
+	assert.Nil(t, rawProtocol.Write(conn, &godis_proto.Request{Operation: godis_proto.Operation_Backup}))
+	time.Sleep(200 * time.Millisecond)
+
+	// writes aren't blocked by backup sent to slow client
+	set := make(chan error, 1)
+	go func() {
+		// keys of all shards are written
+		for i := 0; i < 256; i++ {
+			if err := cl.SetString(fmt.Sprintf("key%d", i), "value", time.Hour); err != nil {
+				set <- err
+				return
+			}
+		}
+		set <- nil
+	}()
+	select {
+	case err = <-set:
+		assert.Nil(t, err)
+	case <-time.After(2 * time.Second):
+		t.Error("write is blocked by backup")
+	}
+	conn.Close()
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_BackupOnServer(t *testing.T) {
+	dir, err := ioutil.TempDir(walDir, "backups")
+	assert.Nil(t, err)
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithBackupDir(dir))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("key", "value", time.Hour))
+
+	_, err = cl.BackupOnServer("../escape")
+	assert.NotNil(t, err)
+	info, err := cl.BackupOnServer("daily")
+	assert.Nil(t, err)
+	assert.Equal(t, info.Path, filepath.Join(dir, "daily"))
+	assert.Equal(t, info.Keys, int64(1))
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	// restore refuses to overwrite existing data
+	err = server.Restore(info.Path, filepath.Join(walDir, addr+".snapshot"), filepath.Join(walDir, addr+".wal"))
+	assert.NotNil(t, err)
+
+	// damaged backup doesn't match its checksum
+	data, err := ioutil.ReadFile(info.Path)
+	assert.Nil(t, err)
+	damaged := filepath.Join(dir, "damaged")
+	data[len(data)/2] ^= 0xff
+	assert.Nil(t, ioutil.WriteFile(damaged, data, 0644))
+	sum, err := ioutil.ReadFile(info.Path + ".sha256")
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(damaged+".sha256", sum, 0644))
+	err = server.Restore(damaged, filepath.Join(walDir, "damaged.snapshot"), filepath.Join(walDir, "damaged.wal"))
+	assert.NotNil(t, err)
+
+	restored, restoredAddr := restoreServer(t, info.Path)
+	cl, err = client.Dial(restoredAddr)
+	assert.Nil(t, err)
+	val, err := cl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	restored.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_BackupDirDisabled(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	_, err = cl.BackupOnServer("daily")
+	assert.NotNil(t, err)
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/codec"
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wire"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+var rawProtocol = wire.NewSimpleWireProtocol(codec.NewProtoCodec())
+
+// rawRequest makes request using given connection
+func rawRequest(t *testing.T, conn net.Conn, req *godis_proto.Request) *godis_proto.Response {
+	assert.Nil(t, rawProtocol.Write(conn, req))
+	resp := &godis_proto.Response{}
+	assert.Nil(t, rawProtocol.Read(conn, resp))
+	return resp
+}
+
+// assertClosed checks that server closed connection
+func assertClosed(t *testing.T, conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var b [1]byte
+	_, err := conn.Read(b[:])
+	assert.Equal(t, err, io.EOF)
+}
+
+func TestServer_MaxConnections(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithConnectionLimits(server.ConnectionLimits{MaxConnections: 2}))
+	// wait until connection made by startServer is released
+	time.Sleep(50 * time.Millisecond)
+
+	var conns []net.Conn
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", addr)
+		assert.Nil(t, err)
+		resp := rawRequest(t, conn, &godis_proto.Request{Operation: godis_proto.Operation_DBSize})
+		assert.Nil(t, resp.GetError())
+		conns = append(conns, conn)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	resp := &godis_proto.Response{}
+	assert.Nil(t, rawProtocol.Read(conn, resp))
+	assert.Equal(t, resp.GetError().GetCode(), godis_proto.ErrorCode_TooManyConnections)
+	assertClosed(t, conn)
+	conn.Close()
+
+	conns[0].Close()
+	time.Sleep(50 * time.Millisecond)
+	conn, err = net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	resp = rawRequest(t, conn, &godis_proto.Request{Operation: godis_proto.Operation_Stats})
+	assert.Equal(t, resp.GetStats().GetConnections().GetActive(), int64(2))
+	assert.Equal(t, resp.GetStats().GetConnections().GetRejected(), int64(1))
+	conn.Close()
+	conns[1].Close()
+
+	s.Shutdown(context.Background())
+}
+
+func TestServer_MaxConnectionsPerHost(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithConnectionLimits(server.ConnectionLimits{MaxConnectionsPerHost: 1}))
+	time.Sleep(50 * time.Millisecond)
+
+	first, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	rawRequest(t, first, &godis_proto.Request{Operation: godis_proto.Operation_DBSize})
+
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	resp := &godis_proto.Response{}
+	assert.Nil(t, rawProtocol.Read(conn, resp))
+	assert.Equal(t, resp.GetError().GetCode(), godis_proto.ErrorCode_TooManyConnections)
+	conn.Close()
+
+	resp = rawRequest(t, first, &godis_proto.Request{Operation: godis_proto.Operation_Stats})
+	assert.Equal(t, resp.GetStats().GetConnections().GetRejectedPerHost(), int64(1))
+	first.Close()
+
+	s.Shutdown(context.Background())
+}
+
+func TestServer_ConnectionTimeouts(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithConnectionLimits(server.ConnectionLimits{
+		IdleTimeout: 100 * time.Millisecond,
+		ReadTimeout: 100 * time.Millisecond,
+	}))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	idle, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	rawRequest(t, idle, &godis_proto.Request{Operation: godis_proto.Operation_DBSize})
+	assertClosed(t, idle)
+	idle.Close()
+
+	// frame isn't finished during read timeout
+	partial, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	_, err = partial.Write([]byte{0, 0, 0, 10, 1})
+	assert.Nil(t, err)
+	assertClosed(t, partial)
+	partial.Close()
+
+	// subscribed connection isn't idle
+	sub, err := cl.Subscribe("news")
+	assert.Nil(t, err)
+	time.Sleep(250 * time.Millisecond)
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	resp := rawRequest(t, conn, &godis_proto.Request{Operation: godis_proto.Operation_Publish, Key: "news", Payload: []byte("hello")})
+	assert.Equal(t, resp.GetCount(), int64(1))
+	msg := <-sub.Channel()
+	assert.Equal(t, string(msg.Payload), "hello")
+
+	resp = rawRequest(t, conn, &godis_proto.Request{Operation: godis_proto.Operation_Stats})
+	stats := resp.GetStats().GetConnections()
+	assert.True(t, stats.GetIdleClosed() >= 1)
+	assert.Equal(t, stats.GetReadTimeouts(), int64(1))
+	conn.Close()
+
+	sub.Close()
+	s.Shutdown(context.Background())
+	cl.Close()
+}
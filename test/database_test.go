@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/codec"
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wire"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Databases(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithDatabases(4))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	db1 := cl.DB(1)
+
+	err = cl.SetString("key", "db0", time.Hour)
+	assert.Nil(t, err)
+	err = db1.SetString("key", "db1", time.Hour)
+	assert.Nil(t, err)
+	err = db1.SetString("other", "db1", time.Hour)
+	assert.Nil(t, err)
+
+	val, err := cl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "db0")
+	val, err = db1.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "db1")
+
+	keys, err := cl.Keys(".*")
+	assert.Nil(t, err)
+	assert.Equal(t, keys, []string{"key"})
+
+	size, err := db1.DBSize()
+	assert.Nil(t, err)
+	assert.Equal(t, size, 2)
+
+	err = db1.FlushDB()
+	assert.Nil(t, err)
+	size, err = db1.DBSize()
+	assert.Nil(t, err)
+	assert.Equal(t, size, 0)
+	size, err = cl.DBSize()
+	assert.Nil(t, err)
+	assert.Equal(t, size, 1)
+
+	_, err = cl.DB(4).DBSize()
+	assert.NotNil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_Select(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.DB(2).SetString("key", "db2", time.Hour)
+	assert.Nil(t, err)
+
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	p := wire.NewSimpleWireProtocol(codec.NewProtoCodec())
+	do := func(req *godis_proto.Request) *godis_proto.Response {
+		assert.Nil(t, p.Write(conn, req))
+		resp := &godis_proto.Response{}
+		assert.Nil(t, p.Read(conn, resp))
+		return resp
+	}
+
+	resp := do(&godis_proto.Request{Operation: godis_proto.Operation_Get, Key: "key"})
+	assert.NotNil(t, resp.GetError())
+
+	do(&godis_proto.Request{Operation: godis_proto.Operation_Select, Database: &godis_proto.Database{Index: 2}})
+	resp = do(&godis_proto.Request{Operation: godis_proto.Operation_Get, Key: "key"})
+	assert.Equal(t, resp.GetValue().GetStringVal(), "db2")
+
+	// request can select database for itself only
+	resp = do(&godis_proto.Request{Operation: godis_proto.Operation_DBSize, Database: &godis_proto.Database{Index: 0}})
+	assert.Equal(t, resp.GetCount(), int64(0))
+	resp = do(&godis_proto.Request{Operation: godis_proto.Operation_DBSize})
+	assert.Equal(t, resp.GetCount(), int64(1))
+
+	resp = do(&godis_proto.Request{Operation: godis_proto.Operation_Select, Database: &godis_proto.Database{Index: 100}})
+	assert.NotNil(t, resp.GetError())
+
+	conn.Close()
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_DatabaseKeyspaceNotifications(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	sub, err := cl.DB(3).SubscribeKeyspace("^key$", godis_proto.EventType_Written)
+	assert.Nil(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	err = cl.SetString("key", "db0", time.Hour)
+	assert.Nil(t, err)
+	err = cl.DB(3).SetString("key", "db3", time.Hour)
+	assert.Nil(t, err)
+
+	select {
+	case msg := <-sub.Channel():
+		assert.Equal(t, msg.Key, "key")
+		assert.Equal(t, msg.Database, 3)
+	case <-time.After(time.Second):
+		t.Fatal("notification wasn't received")
+	}
+
+	sub.Close()
+	s.Shutdown(context.Background())
+	cl.Close()
+}
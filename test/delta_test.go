@@ -0,0 +1,222 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_PartialUpdates(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	n, err := cl.HSet("map", time.Hour, map[string]string{"a": "1", "b": "2"})
+	assert.Nil(t, err)
+	assert.Equal(t, n, 2)
+	n, err = cl.HSet("map", time.Hour, map[string]string{"b": "3", "c": "4"})
+	assert.Nil(t, err)
+	assert.Equal(t, n, 1)
+	n, err = cl.HDel("map", "a", "missing")
+	assert.Nil(t, err)
+	assert.Equal(t, n, 1)
+	val, err := cl.GetMap("map")
+	assert.Nil(t, err)
+	assert.Equal(t, val, map[string]string{"b": "3", "c": "4"})
+	// map without fields is deleted
+	n, err = cl.HDel("map", "b", "c")
+	assert.Nil(t, err)
+	assert.Equal(t, n, 2)
+	_, err = cl.GetMap("map")
+	assert.NotNil(t, err)
+
+	sum, err := cl.Incr("counter", 5, time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, sum, int64(5))
+	sum, err = cl.Incr("counter", -7, time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, sum, int64(-2))
+	assert.Nil(t, cl.SetString("string", "value", time.Hour))
+	_, err = cl.Incr("string", 1, time.Hour)
+	assert.NotNil(t, err)
+	_, err = cl.HSet("string", time.Hour, map[string]string{"a": "1"})
+	assert.NotNil(t, err)
+
+	ok, err := cl.Expire("counter", 50*time.Millisecond)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = cl.Expire("missing", time.Hour)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+	time.Sleep(100 * time.Millisecond)
+	_, err = cl.GetString("counter")
+	assert.NotNil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_PartialUpdatesReplay(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeFsync)
+	s := startServer(addr, durability)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	fields := map[string]string{}
+	for i := 0; i < 1000; i++ {
+		fields[fmt.Sprintf("field%d", i)] = "value"
+	}
+	_, err = cl.HSet("map", time.Hour, fields)
+	assert.Nil(t, err)
+	// record contains only changed field
+	size := walSize(t, addr)
+	_, err = cl.HSet("map", time.Hour, map[string]string{"field1": "changed"})
+	assert.Nil(t, err)
+	assert.True(t, walSize(t, addr)-size < 200)
+
+	_, err = cl.HDel("map", "field2")
+	assert.Nil(t, err)
+	_, err = cl.RPush("list", time.Hour, "b", "c")
+	assert.Nil(t, err)
+	_, err = cl.LPush("list", time.Hour, "a")
+	assert.Nil(t, err)
+	_, err = cl.RPop("list")
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = cl.Incr("counter", 2, time.Hour)
+		assert.Nil(t, err)
+	}
+	_, err = cl.Expire("counter", time.Minute)
+	assert.Nil(t, err)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	s = startServer(addr, durability)
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	m, err := cl.GetMap("map")
+	assert.Nil(t, err)
+	assert.Equal(t, len(m), 999)
+	assert.Equal(t, m["field1"], "changed")
+	list, err := cl.GetSlice("list")
+	assert.Nil(t, err)
+	assert.Equal(t, list, []string{"a", "b"})
+	counter, err := cl.GetString("counter")
+	assert.Nil(t, err)
+	assert.Equal(t, counter, "6")
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_PartialUpdatesWhileSnapshot(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeGroupCommit)
+	s := startServer(addr, durability)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				_, err := cl.Incr(fmt.Sprintf("counter%d", w), 1, time.Hour)
+				assert.Nil(t, err)
+				_, err = cl.RPush(fmt.Sprintf("list%d", w), time.Hour, fmt.Sprint(i))
+				assert.Nil(t, err)
+			}
+		}(w)
+	}
+	for i := 0; i < 5; i++ {
+		_, err := cl.Snapshot()
+		assert.Nil(t, err)
+	}
+	wg.Wait()
+
+	crashed := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	copyServerFiles(t, addr, crashed)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	// records written during snapshot aren't applied twice
+	s = startServer(crashed, durability)
+	cl, err = client.Dial(crashed)
+	assert.Nil(t, err)
+	for w := 0; w < 4; w++ {
+		val, err := cl.GetString(fmt.Sprintf("counter%d", w))
+		assert.Nil(t, err)
+		assert.Equal(t, val, "200")
+		list, err := cl.GetSlice(fmt.Sprintf("list%d", w))
+		assert.Nil(t, err)
+		assert.Equal(t, len(list), 200)
+	}
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_ConcurrentPartialUpdatesReplay(t *testing.T) {
+	// updates must run in parallel to be applied and written to wal in different order
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeFsync)
+	s := startServer(addr, durability)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	// large elements take long to encode, so record of push can be written after record of pop made after it
+	element := strings.Repeat("e", 64<<10)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				_, err := cl.LPush("list", time.Hour, fmt.Sprintf("%d-%d-%s", w, i, element))
+				assert.Nil(t, err)
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				cl.RPop("list")
+			}
+		}()
+	}
+	wg.Wait()
+	list, err := cl.GetSlice("list")
+	assert.Nil(t, err)
+
+	crashed := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	copyServerFiles(t, addr, crashed)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	// deltas are replayed in the order they were applied
+	s = startServer(crashed, durability)
+	cl, err = client.Dial(crashed)
+	assert.Nil(t, err)
+	replayed, err := cl.GetSlice("list")
+	assert.Nil(t, err)
+	trim := func(list []string) []string {
+		for i := range list {
+			list[i] = strings.TrimSuffix(list[i], element)
+		}
+		return list
+	}
+	assert.Equal(t, trim(replayed), trim(list))
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// walSegments returns segment files of wal of test server
+func walSegments(t *testing.T, addr string) []string {
+	files, err := filepath.Glob(filepath.Join(walDir, addr+".wal.*"))
+	assert.Nil(t, err)
+	return files
+}
+
+// walSize returns total size of wal segments of test server
+func walSize(t *testing.T, addr string) int64 {
+	size := int64(0)
+	for _, file := range walSegments(t, addr) {
+		info, err := os.Stat(file)
+		assert.Nil(t, err)
+		size += info.Size()
+	}
+	return size
+}
+
+func TestServer_DurabilityModes(t *testing.T) {
+	for _, mode := range []wal.Mode{wal.ModeInterval, wal.ModeFsync, wal.ModeGroupCommit} {
+		t.Run(mode.String(), func(t *testing.T) {
+			addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+			opts := []server.Option{server.WithDurability(mode), server.WithWALOptions(wal.Options{Interval: time.Hour})}
+			s := startServer(addr, opts...)
+			cl, err := client.Dial(addr)
+			assert.Nil(t, err)
+
+			empty := walSize(t, addr)
+			err = cl.SetString("key", "value", time.Hour)
+			assert.Nil(t, err)
+			// in interval mode record waits for flush
+			assert.Equal(t, walSize(t, addr) > empty, mode.Synchronous())
+
+			err = cl.Durable().SetString("other", "value", time.Hour)
+			assert.Nil(t, err)
+			assert.True(t, walSize(t, addr) > empty)
+			s.Shutdown(context.Background())
+			cl.Close()
+
+			s = startServer(addr, opts...)
+			cl, err = client.Dial(addr)
+			assert.Nil(t, err)
+			for _, key := range []string{"key", "other"} {
+				val, err := cl.GetString(key)
+				assert.Nil(t, err)
+				assert.Equal(t, val, "value")
+			}
+			s.Shutdown(context.Background())
+			cl.Close()
+		})
+	}
+}
+
+func TestServer_DurabilityNone(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithDurability(wal.ModeNone))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.SetString("key", "value", time.Hour)
+	assert.Nil(t, err)
+	err = cl.Durable().SetString("key", "value", time.Hour)
+	assert.NotNil(t, err)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	s = startServer(addr, server.WithDurability(wal.ModeNone))
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	_, err = cl.GetString("key")
+	assert.NotNil(t, err)
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_WALRecovery(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	noSnapshots := server.WithSnapshotFile("")
+	s := startServer(addr, noSnapshots)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	err = cl.SetString("key", "value", time.Hour)
+	assert.Nil(t, err)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	// torn tail is truncated
+	file := walSegments(t, addr)[0]
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0644)
+	assert.Nil(t, err)
+	f.Write([]byte{0, 0, 0, 0, 0, 0, 0, 1, 0})
+	f.Close()
+	s = startServer(addr, noSnapshots, server.WithWALOptions(wal.Options{Strict: true}))
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	val, err := cl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	// strict server refuses to start with corrupted log
+	data, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	data[len(data)-1] ^= 0xff
+	data = append(data, data[8:]...)
+	assert.Nil(t, ioutil.WriteFile(file, data, 0644))
+	l, _ := zap.NewProduction()
+	s = server.NewServer(l, server.WithWALFile(filepath.Join(walDir, addr+".wal")), noSnapshots,
+		server.WithWALOptions(wal.Options{Strict: true}))
+	err = s.Run(addr)
+	assert.NotNil(t, err)
+	s.Shutdown(context.Background())
+}
+
+func TestServer_WALCompaction(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	opts := server.WithWALOptions(wal.Options{Interval: time.Hour, SegmentSize: 1 << 10, CompactSize: 8 << 10})
+	s := startServer(addr, opts, server.WithDurability(wal.ModeGroupCommit))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.DB(1).SetString("other", "value", time.Hour)
+	assert.Nil(t, err)
+	for i := 0; i < 1000; i++ {
+		err = cl.SetString("key", fmt.Sprint(i), time.Hour)
+		assert.Nil(t, err)
+	}
+	// compaction keeps log small
+	assert.True(t, walSize(t, addr) < 16<<10)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	s = startServer(addr, opts)
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	val, err := cl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "999")
+	val, err = cl.DB(1).GetString("other")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/encryption"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func keyring(t *testing.T, keys string) server.Option {
+	k, err := encryption.Parse(keys)
+	assert.Nil(t, err)
+	return server.WithEncryption(k)
+}
+
+func TestServer_Encryption(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeFsync)
+	oldKey := "1:000102030405060708090a0b0c0d0e0f"
+	s := startServer(addr, durability, keyring(t, oldKey))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("snapshotted", "secret-value", time.Hour))
+	_, err = cl.Snapshot()
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("logged", "secret-value", time.Hour))
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	files, err := filepath.Glob(filepath.Join(walDir, addr+".*"))
+	assert.Nil(t, err)
+	assert.True(t, len(files) > 1)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		assert.False(t, bytes.Contains(data, []byte("secret")))
+		assert.False(t, bytes.Contains(data, []byte("logged")))
+	}
+
+	// key is rotated, data sealed with old key is still read
+	rotated := keyring(t, oldKey+"\n2:101112131415161718191a1b1c1d1e1f")
+	s = startServer(addr, durability, rotated)
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	for _, key := range []string{"snapshotted", "logged"} {
+		val, err := cl.GetString(key)
+		assert.Nil(t, err)
+		assert.Equal(t, val, "secret-value")
+	}
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	// server refuses to start with wrong key or without key
+	for _, opt := range []server.Option{keyring(t, "1:"+strings.Repeat("ff", 16)), server.WithEncryption(nil)} {
+		l, _ := zap.NewProduction()
+		s = server.NewServer(l, server.WithWALFile(filepath.Join(walDir, addr+".wal")),
+			server.WithSnapshotFile(filepath.Join(walDir, addr+".snapshot")), durability, opt)
+		err = s.Run(addr)
+		assert.NotNil(t, err)
+		s.Shutdown(context.Background())
+	}
+}
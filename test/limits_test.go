@@ -0,0 +1,89 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/codec"
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wire"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Limits(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithLimits(server.Limits{
+		MaxFrameSize:  1024,
+		MaxKeyLength:  8,
+		MaxValueSize:  256,
+		MaxElements:   3,
+		MaxKeysResult: 2,
+	}))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.SetString("long_key_name", "value", time.Hour)
+	assert.True(t, client.IsLimitExceeded(err))
+	err = cl.SetString("key", strings.Repeat("x", 300), time.Hour)
+	assert.True(t, client.IsLimitExceeded(err))
+	err = cl.SetSlice("key", []string{"1", "2", "3", "4"}, time.Hour)
+	assert.True(t, client.IsLimitExceeded(err))
+
+	n, err := cl.RPush("list", time.Hour, "1", "2")
+	assert.Nil(t, err)
+	assert.Equal(t, n, 2)
+	_, err = cl.RPush("list", time.Hour, "3", "4")
+	assert.True(t, client.IsLimitExceeded(err))
+	_, err = cl.RPush("list", time.Hour, "3")
+	assert.Nil(t, err)
+
+	err = cl.SetString("key", "value", time.Hour)
+	assert.Nil(t, err)
+	err = cl.SetString("other", "value", time.Hour)
+	assert.Nil(t, err)
+	_, err = cl.Keys(".*")
+	assert.True(t, client.IsLimitExceeded(err))
+	keys, err := cl.Keys("^k")
+	assert.Nil(t, err)
+	assert.Equal(t, keys, []string{"key"})
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_BadFrames(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithLimits(server.Limits{MaxFrameSize: 1024}))
+	p := wire.NewSimpleWireProtocol(codec.NewProtoCodec())
+
+	for name, frame := range map[string][]byte{
+		"huge":      {0xff, 0xff, 0xff, 0xff},
+		"too large": {0, 0, 0x10, 0},
+		"broken":    {0, 0, 0, 3, 0xff, 0xff, 0xff},
+	} {
+		conn, err := net.Dial("tcp", addr)
+		assert.Nil(t, err)
+		_, err = conn.Write(frame)
+		assert.Nil(t, err)
+
+		resp := &godis_proto.Response{}
+		err = p.Read(conn, resp)
+		assert.Nil(t, err, name)
+		assert.Equal(t, resp.GetError().GetCode(), godis_proto.ErrorCode_BadRequest, name)
+		// connection is closed after bad frame
+		var b [1]byte
+		_, err = conn.Read(b[:])
+		assert.Equal(t, err, io.EOF, name)
+		conn.Close()
+	}
+
+	s.Shutdown(context.Background())
+}
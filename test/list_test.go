@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_PushPop(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	n, err := cl.RPush("queue", time.Hour, "b", "c")
+	assert.Nil(t, err)
+	assert.Equal(t, n, 2)
+
+	n, err = cl.LPush("queue", time.Hour, "a", "z")
+	assert.Nil(t, err)
+	assert.Equal(t, n, 4)
+
+	val, err := cl.GetSlice("queue")
+	assert.Nil(t, err)
+	assert.Equal(t, val, []string{"z", "a", "b", "c"})
+
+	el, err := cl.LPop("queue")
+	assert.Nil(t, err)
+	assert.Equal(t, el, "z")
+
+	el, err = cl.RPop("queue")
+	assert.Nil(t, err)
+	assert.Equal(t, el, "c")
+
+	key, el, err := cl.BLPop(context.Background(), time.Second, "empty", "queue")
+	assert.Nil(t, err)
+	assert.Equal(t, key, "queue")
+	assert.Equal(t, el, "a")
+
+	_, err = cl.LPop("queue")
+	assert.Nil(t, err)
+	// empty slice is deleted
+	_, err = cl.GetSlice("queue")
+	assert.NotNil(t, err)
+	_, err = cl.LPop("queue")
+	assert.NotNil(t, err)
+
+	err = cl.SetString("string", "value", time.Hour)
+	assert.Nil(t, err)
+	_, err = cl.RPush("string", time.Hour, "a")
+	assert.NotNil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_BlockingPop(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	start := time.Now()
+	_, _, err = cl.BRPop(context.Background(), 20*time.Millisecond, "jobs")
+	assert.Equal(t, err, client.ErrTimeout)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+
+	// waiters must be served in order they came
+	results := make(chan string, 3)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, el, err := cl.BLPop(context.Background(), 0, "jobs", "other")
+			assert.Nil(t, err)
+			results <- fmt.Sprintf("%d:%s", i, el)
+		}(i)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	_, err = cl.RPush("jobs", time.Hour, "1", "2", "3")
+	assert.Nil(t, err)
+	wg.Wait()
+	close(results)
+	var got []string
+	for r := range results {
+		got = append(got, r)
+	}
+	assert.ElementsMatch(t, got, []string{"0:1", "1:2", "2:3"})
+
+	// cancelled waiter must not take pushed element
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, _, err := cl.BLPop(ctx, 0, "cancelled")
+		errs <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	assert.Equal(t, <-errs, context.Canceled)
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = cl.RPush("cancelled", time.Hour, "x")
+	assert.Nil(t, err)
+	el, err := cl.LPop("cancelled")
+	assert.Nil(t, err)
+	assert.Equal(t, el, "x")
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Lock(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	lock, err := cl.AcquireLock("lock", "alice", 100*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, lock.Owner, "alice")
+	assert.Equal(t, lock.Token, uint64(1))
+
+	lock, err = cl.AcquireLock("lock", "bob", time.Second)
+	assert.Equal(t, err, client.ErrLockHeld)
+	assert.Equal(t, lock.Owner, "alice")
+
+	// acquire by owner extends lease and keeps token
+	lock, err = cl.AcquireLock("lock", "alice", 100*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, lock.Token, uint64(1))
+
+	_, err = cl.RenewLock("lock", "bob", time.Second)
+	assert.Equal(t, err, client.ErrLockNotHeld)
+	err = cl.ReleaseLock("lock", "bob")
+	assert.Equal(t, err, client.ErrLockNotHeld)
+
+	lock, err = cl.RenewLock("lock", "alice", 100*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, lock.Token, uint64(1))
+
+	// lease expires
+	time.Sleep(150 * time.Millisecond)
+	_, err = cl.RenewLock("lock", "alice", time.Second)
+	assert.Equal(t, err, client.ErrLockNotHeld)
+
+	lock, err = cl.AcquireLock("lock", "bob", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, lock.Token, uint64(2))
+	err = cl.ReleaseLock("lock", "bob")
+	assert.Nil(t, err)
+
+	// token keeps increasing after release
+	lock, err = cl.AcquireLock("lock", "alice", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, lock.Token, uint64(3))
+
+	_, err = cl.AcquireLock("lock", "", time.Second)
+	assert.NotNil(t, err)
+	err = cl.SetString("string", "value", time.Hour)
+	assert.Nil(t, err)
+	_, err = cl.AcquireLock("string", "alice", time.Second)
+	assert.NotNil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestClient_Lease(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	lease, err := cl.AcquireLease(context.Background(), "leader", "alice", 60*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, lease.Token, uint64(1))
+
+	// lease is renewed in background
+	time.Sleep(200 * time.Millisecond)
+	assert.Nil(t, lease.Context().Err())
+	_, err = cl.AcquireLock("leader", "bob", time.Second)
+	assert.Equal(t, err, client.ErrLockHeld)
+
+	err = lease.Release()
+	assert.Nil(t, err)
+	assert.NotNil(t, lease.Context().Err())
+
+	lease, err = cl.AcquireLease(context.Background(), "leader", "bob", 60*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, lease.Token, uint64(2))
+
+	// lock is stolen after lease was lost, e.g. because of long pause
+	err = cl.ReleaseLock("leader", "bob")
+	assert.Nil(t, err)
+	_, err = cl.AcquireLock("leader", "carol", time.Second)
+	assert.Nil(t, err)
+	select {
+	case <-lease.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("lock loss wasn't reported")
+	}
+	err = lease.Release()
+	assert.Equal(t, err, client.ErrLockNotHeld)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lease, err = cl.AcquireLease(ctx, "other", "alice", time.Second)
+	assert.Nil(t, err)
+	cancel()
+	<-lease.Context().Done()
+	// lock is released when parent context is done
+	assert.Eventually(t, func() bool {
+		_, err := cl.AcquireLock("other", "bob", time.Second)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// lease which can't be renewed isn't taken
+	for _, ttl := range []time.Duration{0, 2, -time.Second} {
+		_, err = cl.AcquireLease(context.Background(), "short", "alice", ttl)
+		assert.Equal(t, err, client.ErrLeaseTTL)
+	}
+	_, err = cl.AcquireLock("short", "bob", time.Second)
+	assert.Nil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
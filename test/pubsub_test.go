@@ -0,0 +1,162 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/server"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, sub *client.Subscription) *client.Message {
+	select {
+	case msg := <-sub.Channel():
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("message wasn't received")
+		return nil
+	}
+}
+
+// receivePayload skips messages with another payload, they could be published by waitReceivers
+func receivePayload(t *testing.T, sub *client.Subscription, payload string) *client.Message {
+	for {
+		msg := receive(t, sub)
+		if string(msg.Payload) == payload {
+			return msg
+		}
+	}
+}
+
+// waitReceivers publishes to channel until message is received by expected amount of subscribers,
+// because subscribe requests are acknowledged asynchronously
+func waitReceivers(t *testing.T, cl *client.Client, channel string, payload []byte, expected int) {
+	for i := 0; i < 100; i++ {
+		n, err := cl.Publish(channel, payload)
+		assert.Nil(t, err)
+		if n == expected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("message wasn't received by %d subscribers", expected)
+}
+
+func TestServer_PubSub(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	sub, err := cl.Subscribe("invalidate")
+	assert.Nil(t, err)
+	psub, err := cl.PSubscribe("^inv.*")
+	assert.Nil(t, err)
+
+	waitReceivers(t, cl, "invalidate", []byte("user:1"), 2)
+
+	msg := receivePayload(t, sub, "user:1")
+	assert.Equal(t, msg.Channel, "invalidate")
+	assert.Equal(t, msg.Pattern, "")
+	assert.Equal(t, msg.Payload, []byte("user:1"))
+
+	msg = receivePayload(t, psub, "user:1")
+	assert.Equal(t, msg.Channel, "invalidate")
+	assert.Equal(t, msg.Pattern, "^inv.*")
+	assert.Equal(t, msg.Payload, []byte("user:1"))
+
+	n, err := cl.Publish("nothing", []byte("payload"))
+	assert.Nil(t, err)
+	assert.Equal(t, n, 0)
+
+	err = sub.Subscribe("other")
+	assert.Nil(t, err)
+	waitReceivers(t, cl, "other", []byte("other"), 1)
+	msg = receivePayload(t, sub, "other")
+	assert.Equal(t, msg.Channel, "other")
+
+	err = psub.PUnsubscribe("^inv.*")
+	assert.Nil(t, err)
+	waitReceivers(t, cl, "invalidate", []byte("user:2"), 1)
+	msg = receivePayload(t, sub, "user:2")
+	assert.Equal(t, msg.Payload, []byte("user:2"))
+
+	_, err = cl.PSubscribe("(")
+	assert.NotNil(t, err)
+
+	assert.Nil(t, sub.Close())
+	assert.Nil(t, psub.Close())
+	for range sub.Channel() {
+		// drain messages received before close
+	}
+
+	waitReceivers(t, cl, "invalidate", []byte("user:3"), 0)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_KeyspaceNotifications(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithExpireInterval(time.Millisecond))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	all, err := cl.SubscribeKeyspace("^session:")
+	assert.Nil(t, err)
+	expired, err := cl.SubscribeKeyspace("^session:", godis_proto.EventType_Expired)
+	assert.Nil(t, err)
+
+	// wait until both subscriptions are active
+	for i := 0; i < 100; i++ {
+		err = cl.SetString("session:probe", "", time.Hour)
+		assert.Nil(t, err)
+		select {
+		case <-all.Channel():
+		case <-time.After(10 * time.Millisecond):
+			continue
+		}
+		break
+	}
+	time.Sleep(10 * time.Millisecond)
+	for len(all.Channel()) > 0 {
+		<-all.Channel()
+	}
+
+	err = cl.SetString("other", "", time.Hour)
+	assert.Nil(t, err)
+	err = cl.SetString("session:1", "user", time.Hour)
+	assert.Nil(t, err)
+	msg := receive(t, all)
+	assert.Equal(t, msg.Key, "session:1")
+	assert.Equal(t, msg.Event, godis_proto.EventType_Written)
+
+	err = cl.Remove("session:1")
+	assert.Nil(t, err)
+	msg = receive(t, all)
+	assert.Equal(t, msg.Key, "session:1")
+	assert.Equal(t, msg.Event, godis_proto.EventType_Removed)
+
+	// key is never accessed, so it must be expired by server
+	err = cl.SetString("session:2", "user", 5*time.Millisecond)
+	assert.Nil(t, err)
+	msg = receive(t, all)
+	assert.Equal(t, msg.Event, godis_proto.EventType_Written)
+	msg = receive(t, all)
+	assert.Equal(t, msg.Key, "session:2")
+	assert.Equal(t, msg.Event, godis_proto.EventType_Expired)
+
+	msg = receive(t, expired)
+	assert.Equal(t, msg.Key, "session:2")
+	assert.Equal(t, msg.Event, godis_proto.EventType_Expired)
+
+	assert.Nil(t, all.Close())
+	assert.Nil(t, expired.Close())
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Queue(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	jobs, err := cl.QDequeue("jobs", 1, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, len(jobs), 0)
+
+	first, err := cl.QEnqueue("jobs", []byte("first"), 0, 0)
+	assert.Nil(t, err)
+	_, err = cl.QEnqueue("jobs", []byte("delayed"), 200*time.Millisecond, 0)
+	assert.Nil(t, err)
+	_, err = cl.QEnqueue("jobs", nil, 0, 0)
+	assert.NotNil(t, err)
+
+	stats, err := cl.QStats("jobs")
+	assert.Nil(t, err)
+	assert.Equal(t, stats, client.QueueStats{Ready: 1, Delayed: 1})
+
+	jobs, err = cl.QDequeue("jobs", 10, 100*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, len(jobs), 1)
+	assert.Equal(t, jobs[0].ID, first)
+	assert.Equal(t, string(jobs[0].Payload), "first")
+	assert.Equal(t, jobs[0].Attempts, 1)
+
+	// job is hidden until visibility timeout
+	jobs, err = cl.QDequeue("jobs", 10, 100*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, len(jobs), 0)
+	stats, err = cl.QStats("jobs")
+	assert.Nil(t, err)
+	assert.Equal(t, stats, client.QueueStats{Delayed: 1, InFlight: 1})
+
+	time.Sleep(250 * time.Millisecond)
+	// not acknowledged job is delivered again
+	jobs, err = cl.QDequeue("jobs", 10, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, len(jobs), 2)
+	assert.Equal(t, string(jobs[0].Payload), "delayed")
+	assert.Equal(t, string(jobs[1].Payload), "first")
+	assert.Equal(t, jobs[1].Attempts, 2)
+
+	n, err := cl.QAck("jobs", jobs[0].ID, jobs[1].ID, "unknown")
+	assert.Nil(t, err)
+	assert.Equal(t, n, 2)
+	stats, err = cl.QStats("jobs")
+	assert.Nil(t, err)
+	assert.Equal(t, stats, client.QueueStats{})
+
+	err = cl.SetString("string", "value", time.Hour)
+	assert.Nil(t, err)
+	_, err = cl.QEnqueue("string", []byte("job"), 0, 0)
+	assert.NotNil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_QueueIdlePolling(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithDurability(wal.ModeFsync))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	_, err = cl.QEnqueue("jobs", []byte("delayed"), time.Hour, 0)
+	assert.Nil(t, err)
+	// polls which don't deliver or finish jobs don't change queue, so nothing is written to wal
+	size := walSize(t, addr)
+	for i := 0; i < 10; i++ {
+		jobs, err := cl.QDequeue("jobs", 1, time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, len(jobs), 0)
+		n, err := cl.QAck("jobs", "missing")
+		assert.Nil(t, err)
+		assert.Equal(t, n, 0)
+		n, err = cl.QNack("jobs", 0, "missing")
+		assert.Nil(t, err)
+		assert.Equal(t, n, 0)
+	}
+	assert.Equal(t, walSize(t, addr), size)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_QueueDeadLetters(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	id, err := cl.QEnqueue("failing", []byte("job"), 0, 2)
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		jobs, err := cl.QDequeue("failing", 1, time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, len(jobs), 1)
+		n, err := cl.QNack("failing", 0, id)
+		assert.Nil(t, err)
+		assert.Equal(t, n, 1)
+	}
+
+	stats, err := cl.QStats("failing")
+	assert.Nil(t, err)
+	assert.Equal(t, stats, client.QueueStats{Dead: 1})
+	dead, err := cl.QDead("failing", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, len(dead), 1)
+	assert.Equal(t, dead[0].ID, id)
+	assert.Equal(t, dead[0].Attempts, 2)
+
+	// dead job is requeued by nack
+	n, err := cl.QNack("failing", 0, id)
+	assert.Nil(t, err)
+	assert.Equal(t, n, 1)
+	jobs, err := cl.QDequeue("failing", 1, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, len(jobs), 1)
+	assert.Equal(t, jobs[0].Attempts, 1)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestClient_RunWorker(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err = cl.QEnqueue("work", []byte(fmt.Sprint(i)), 0, 0)
+		assert.Nil(t, err)
+	}
+
+	var (
+		mu        sync.Mutex
+		processed []string
+		failed    bool
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		opts := client.WorkerOptions{VisibilityTimeout: time.Minute, Batch: 2, PollInterval: 10 * time.Millisecond}
+		done <- cl.RunWorker(ctx, "work", opts, func(ctx context.Context, job client.Job) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if string(job.Payload) == "2" && !failed {
+				failed = true
+				return errors.New("failed")
+			}
+			processed = append(processed, string(job.Payload))
+			return nil
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(processed) == 5
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	assert.Equal(t, <-done, context.Canceled)
+
+	stats, err := cl.QStats("work")
+	assert.Nil(t, err)
+	assert.Equal(t, stats, client.QueueStats{})
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
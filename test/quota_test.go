@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_DatabaseQuota(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithDatabaseQuota(1, server.Quota{MaxKeys: 2, MaxBytes: 1024, MaxValueSize: 256}))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	db := cl.DB(1)
+
+	err = db.SetString("a", "1", time.Hour)
+	assert.Nil(t, err)
+	err = db.SetString("b", "2", time.Hour)
+	assert.Nil(t, err)
+	err = db.SetString("c", "3", time.Hour)
+	assert.True(t, client.IsQuotaExceeded(err))
+	// existing key can be overwritten and database without quota isn't limited
+	err = db.SetString("a", "4", time.Hour)
+	assert.Nil(t, err)
+	err = cl.SetString("c", "3", time.Hour)
+	assert.Nil(t, err)
+
+	err = db.Remove("b")
+	assert.Nil(t, err)
+	err = db.SetString("big", strings.Repeat("x", 300), time.Hour)
+	assert.True(t, client.IsQuotaExceeded(err))
+	err = db.SetString("b", strings.Repeat("x", 200), time.Hour)
+	assert.Nil(t, err)
+	_, err = db.RPush("a", time.Hour, strings.Repeat("x", 200))
+	assert.NotNil(t, err)
+	assert.False(t, client.IsQuotaExceeded(err))
+
+	stats, err := cl.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, stats.Databases[1].Keys, int64(2))
+	assert.True(t, stats.Databases[1].Bytes > 200)
+	assert.Equal(t, stats.Databases[1].MaxKeys, int64(2))
+	assert.Equal(t, stats.Databases[1].Rejected, int64(2))
+	assert.Equal(t, stats.Databases[0].Keys, int64(1))
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_DatabaseBytesQuota(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithDatabaseQuota(0, server.Quota{MaxBytes: 1024}))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	for i := 0; i < 10; i++ {
+		err = cl.SetString(fmt.Sprint(i), strings.Repeat("x", 200), time.Hour)
+		if err != nil {
+			break
+		}
+	}
+	assert.True(t, client.IsQuotaExceeded(err))
+	stats, err := cl.Stats()
+	assert.Nil(t, err)
+	assert.True(t, stats.Databases[0].Bytes <= 1024)
+
+	// deletes are allowed
+	err = cl.Remove("0")
+	assert.Nil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_ScriptQuota(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithDatabaseQuota(0, server.Quota{MaxKeys: 3, MaxBytes: 1024}))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.SetString("a", "1", time.Hour)
+	assert.Nil(t, err)
+	// script creates keys which aren't declared in KEYS
+	_, err = cl.Eval(`for i = 1, 5 do godis.set("key" .. i, "value") end`, 0, []string{"a"})
+	assert.True(t, client.IsQuotaExceeded(err))
+	_, err = cl.Eval(`godis.set("big", string.rep("x", 2000))`, 0, nil)
+	assert.True(t, client.IsQuotaExceeded(err))
+	stats, err := cl.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, stats.Databases[0].Keys, int64(1))
+	assert.Equal(t, stats.Databases[0].Rejected, int64(2))
+
+	// script which fits quota or replaces keys is allowed
+	_, err = cl.Eval(`godis.set("b", "2"); godis.set("c", "3")`, 0, nil)
+	assert.Nil(t, err)
+	_, err = cl.Eval(`godis.del("a"); godis.set("d", "4")`, 0, nil)
+	assert.Nil(t, err)
+	val, err := cl.GetString("d")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "4")
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_ClientQuota(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithClientQuota(server.Quota{OpsPerSecond: 10, Burst: 3}))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		err = cl.SetString("key", "value", time.Hour)
+		assert.Nil(t, err)
+	}
+	_, err = cl.GetString("key")
+	assert.True(t, client.IsQuotaExceeded(err))
+
+	time.Sleep(150 * time.Millisecond)
+	stats, err := cl.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, len(stats.Clients), 1)
+	assert.Equal(t, stats.Clients[0].Address, "127.0.0.1")
+	assert.Equal(t, stats.Clients[0].Rejected, int64(1))
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_TokenBucket(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	window := 200 * time.Millisecond
+	res, err := cl.TokenBucket("bucket", 4, window, 3)
+	assert.Nil(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, res.Remaining, 1)
+
+	res, err = cl.TokenBucket("bucket", 4, window, 2)
+	assert.Nil(t, err)
+	assert.False(t, res.Allowed)
+	assert.True(t, res.RetryAfter > 0 && res.RetryAfter <= window/4)
+
+	time.Sleep(res.RetryAfter)
+	res, err = cl.TokenBucket("bucket", 4, window, 2)
+	assert.Nil(t, err)
+	assert.True(t, res.Allowed)
+
+	_, err = cl.TokenBucket("bucket", 4, window, 5)
+	assert.NotNil(t, err)
+	_, err = cl.SlidingWindowLog("bucket", 4, window, 1)
+	assert.NotNil(t, err)
+
+	// idle limiter expires
+	time.Sleep(window)
+	keys, err := cl.Keys("bucket")
+	assert.Nil(t, err)
+	assert.Equal(t, len(keys), 0)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_SlidingWindowLog(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	window := 200 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		res, err := cl.SlidingWindowLog("log", 3, window, 1)
+		assert.Nil(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, res.Remaining, 2-i)
+	}
+
+	res, err := cl.SlidingWindowLog("log", 3, window, 1)
+	assert.Nil(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, res.Remaining, 0)
+	assert.True(t, res.RetryAfter > 0 && res.RetryAfter <= window)
+
+	time.Sleep(res.RetryAfter)
+	res, err = cl.SlidingWindowLog("log", 3, window, 1)
+	assert.Nil(t, err)
+	assert.True(t, res.Allowed)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// startRecovered starts server with data directory written by recovery
+func startRecovered(dir string) (*server.Server, string) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	return startServer(addr, server.WithWALFile(filepath.Join(dir, "godis.wal")),
+		server.WithSnapshotFile(filepath.Join(dir, "godis.snapshot"))), addr
+}
+
+func TestServer_RecoverToTime(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithSnapshotFile(""), server.WithDurability(wal.ModeGroupCommit))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("a", "value", time.Hour))
+	assert.Nil(t, cl.DB(1).SetString("b", "value", time.Hour))
+	time.Sleep(10 * time.Millisecond)
+	target := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	// bad deploy deletes keys
+	assert.Nil(t, cl.Remove("a"))
+	assert.Nil(t, cl.SetString("c", "value", time.Hour))
+
+	dir, err := ioutil.TempDir(walDir, "recovered")
+	assert.Nil(t, err)
+	info, err := server.Recover(zap.NewNop(), server.RecoveryOptions{
+		WALFile: filepath.Join(walDir, addr+".wal"),
+		Target:  wal.Target{Time: target},
+		Dir:     dir,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, info.Keys, int64(2))
+	assert.Equal(t, info.LSN, uint64(2))
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	s, addr = startRecovered(dir)
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	val, err := cl.GetString("a")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	val, err = cl.DB(1).GetString("b")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	_, err = cl.GetString("c")
+	assert.NotNil(t, err)
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_RecoverToLSN(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithDurability(wal.ModeGroupCommit))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("snapshot", "value", time.Hour))
+	snapshot, err := cl.Snapshot()
+	assert.Nil(t, err)
+	assert.Equal(t, snapshot.LSN, uint64(1))
+	assert.Nil(t, cl.SetString("wal", "value", time.Hour))
+	assert.Nil(t, cl.SetString("after", "value", time.Hour))
+
+	options := server.RecoveryOptions{
+		SnapshotFile: filepath.Join(walDir, addr+".snapshot"),
+		WALFile:      filepath.Join(walDir, addr+".wal"),
+		Target:       wal.Target{LSN: 2},
+	}
+	options.Dir, err = ioutil.TempDir(walDir, "recovered")
+	assert.Nil(t, err)
+	info, err := server.Recover(zap.NewNop(), options)
+	assert.Nil(t, err)
+	assert.Equal(t, info.Keys, int64(2))
+
+	// snapshot is newer than target
+	options.Target.LSN = 0
+	options.Target.Time = time.Now().Add(-time.Hour)
+	options.Dir, err = ioutil.TempDir(walDir, "recovered")
+	assert.Nil(t, err)
+	_, err = server.Recover(zap.NewNop(), options)
+	assert.NotNil(t, err)
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,195 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/godis_proto"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+// startReplica starts replica of primary and connects to it
+func startReplica(t *testing.T, primary string, retryInterval time.Duration) (*server.Server, *client.Client) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithReplicaOf(server.ReplicaOptions{Primary: primary, RetryInterval: retryInterval}))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	return s, cl
+}
+
+// waitReplica waits until replica applies all records written by primary
+func waitReplica(t *testing.T, primary, replica *client.Client) client.ReplicationStats {
+	stats, err := primary.Stats()
+	assert.Nil(t, err)
+	lsn := stats.Replication.LSN
+	var repl client.ReplicationStats
+	assert.Eventually(t, func() bool {
+		stats, err := replica.Stats()
+		assert.Nil(t, err)
+		repl = stats.Replication
+		return repl.Connected && repl.LSN >= lsn
+	}, 5*time.Second, 10*time.Millisecond)
+	return repl
+}
+
+func TestServer_Replication(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	primary := startServer(addr, server.WithDurability(wal.ModeFsync))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("key", "value", time.Hour))
+	assert.Nil(t, cl.DB(2).SetString("other", "value", time.Hour))
+	_, err = cl.Incr("counter", 5, time.Hour)
+	assert.Nil(t, err)
+
+	// replica gets data written before it's connected by full resync
+	replica, rcl := startReplica(t, addr, 50*time.Millisecond)
+	repl := waitReplica(t, cl, rcl)
+	assert.Equal(t, repl.Primary, addr)
+	assert.Equal(t, repl.FullSyncs, int64(1))
+	val, err := rcl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	val, err = rcl.DB(2).GetString("other")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	stats, err := cl.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, stats.Replication.Replicas, int64(1))
+	assert.Equal(t, stats.Replication.Primary, "")
+
+	// records written later are streamed
+	_, err = cl.Incr("counter", 2, time.Hour)
+	assert.Nil(t, err)
+	_, err = cl.HSet("map", time.Hour, map[string]string{"a": "1", "b": "2"})
+	assert.Nil(t, err)
+	_, err = cl.HDel("map", "a")
+	assert.Nil(t, err)
+	assert.Nil(t, cl.Remove("key"))
+	assert.Nil(t, cl.DB(2).FlushDB())
+	repl = waitReplica(t, cl, rcl)
+	assert.Equal(t, repl.Lag, time.Duration(0))
+	val, err = rcl.GetString("counter")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "7")
+	m, err := rcl.GetMap("map")
+	assert.Nil(t, err)
+	assert.Equal(t, m, map[string]string{"b": "2"})
+	_, err = rcl.GetString("key")
+	assert.NotNil(t, err)
+	size, err := rcl.DB(2).DBSize()
+	assert.Nil(t, err)
+	assert.Equal(t, size, 0)
+
+	// replica rejects writes
+	err = rcl.SetString("key", "value", time.Hour)
+	assert.True(t, client.IsReadOnly(err))
+	_, err = rcl.Incr("counter", 1, time.Hour)
+	assert.True(t, client.IsReadOnly(err))
+
+	replica.Shutdown(context.Background())
+	rcl.Close()
+	assert.Eventually(t, func() bool {
+		stats, err := cl.Stats()
+		assert.Nil(t, err)
+		return stats.Replication.Replicas == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	primary.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_ReplicationResume(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeFsync)
+	primary := startServer(addr, durability)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("before", "value", time.Hour))
+	replica, rcl := startReplica(t, addr, time.Second)
+	waitReplica(t, cl, rcl)
+	primary.Shutdown(context.Background())
+	cl.Close()
+
+	// replica continues from its last LSN after reconnect
+	primary = startServer(addr, durability)
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("resumed", "value", time.Hour))
+	repl := waitReplica(t, cl, rcl)
+	assert.Equal(t, repl.FullSyncs, int64(1))
+	val, err := rcl.GetString("resumed")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	primary.Shutdown(context.Background())
+	cl.Close()
+
+	// records after replica position are deleted by snapshot before replica reconnects, so it makes full resync
+	primary = startServer(addr, durability)
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	assert.Nil(t, cl.SetString("snapshotted", "value", time.Hour))
+	assert.Nil(t, cl.Remove("before"))
+	_, err = cl.Snapshot()
+	assert.Nil(t, err)
+	repl = waitReplica(t, cl, rcl)
+	assert.Equal(t, repl.FullSyncs, int64(2))
+	val, err = rcl.GetString("snapshotted")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	_, err = rcl.GetString("before")
+	assert.NotNil(t, err)
+
+	replica.Shutdown(context.Background())
+	rcl.Close()
+	primary.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_ReplicationSlowReplica(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	primary := startServer(addr, server.WithDurability(wal.ModeFsync))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+	// dump is larger than socket buffers, so primary can't send it to replica which doesn't read it
+	value := strings.Repeat("v", 64<<10)
+	for i := 0; i < 256; i++ {
+		assert.Nil(t, cl.SetString(fmt.Sprintf("key%d", i), value, time.Hour))
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	assert.Nil(t, conn.(*net.TCPConn).SetReadBuffer(4096))
+	assert.Nil(t, rawProtocol.Write(conn, &godis_proto.Request{Operation: godis_proto.Operation_Replicate}))
+	time.Sleep(200 * time.Millisecond)
+
+	// writes, snapshots and compaction aren't blocked by full resync of slow replica
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 256; i++ {
+			if err := cl.SetString(fmt.Sprintf("key%d", i), "value", time.Hour); err != nil {
+				done <- err
+				return
+			}
+		}
+		_, err := cl.Snapshot()
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		assert.Nil(t, err)
+	case <-time.After(2 * time.Second):
+		t.Error("primary is blocked by replica")
+	}
+	conn.Close()
+
+	primary.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Eval(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.SetString("balance", "10", time.Hour)
+	assert.Nil(t, err)
+
+	// check and set
+	transfer := `
+local balance = tonumber(godis.get(KEYS[1]) or "0")
+local amount = tonumber(ARGV[1])
+if balance < amount then
+	error("insufficient funds")
+end
+godis.set(KEYS[1], balance - amount)
+godis.set(KEYS[2], {ARGV[1], "to", KEYS[2]}, 60000)
+return balance - amount
+`
+	res, err := cl.Eval(transfer, 0, []string{"balance", "log"}, "7")
+	assert.Nil(t, err)
+	assert.Equal(t, res, "3")
+
+	val, err := cl.GetString("balance")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "3")
+	arr, err := cl.GetSlice("log")
+	assert.Nil(t, err)
+	assert.Equal(t, arr, []string{"7", "to", "log"})
+
+	// writes of failed script aren't applied
+	_, err = cl.Eval(`godis.set("other", "value"); godis.del(KEYS[1]); error("failed")`, 0, []string{"balance"})
+	assert.NotNil(t, err)
+	_, err = cl.Eval(transfer, 0, []string{"balance", "log"}, "7")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "insufficient funds")
+	val, err = cl.GetString("balance")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "3")
+	_, err = cl.GetString("other")
+	assert.NotNil(t, err)
+
+	res, err = cl.Eval(`return godis.get(KEYS[1])`, 0, []string{"missing"})
+	assert.Nil(t, err)
+	assert.Nil(t, res)
+	res, err = cl.Eval(`return {a = "1", b = "2"}`, 0, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, res, map[string]string{"a": "1", "b": "2"})
+
+	res, err = cl.Eval(`return godis.del(KEYS[1])`, 0, []string{"log"})
+	assert.Nil(t, err)
+	assert.Equal(t, res, "1")
+	_, err = cl.GetSlice("log")
+	assert.NotNil(t, err)
+
+	_, err = cl.Eval(`return dofile("/etc/passwd")`, 0, nil)
+	assert.NotNil(t, err)
+	_, err = cl.Eval(`print("server output")`, 0, nil)
+	assert.NotNil(t, err)
+	_, err = cl.Eval(`return require("os")`, 0, nil)
+	assert.NotNil(t, err)
+	_, err = cl.Eval(`syntax error`, 0, nil)
+	assert.NotNil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_EvalSHA(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithScriptTimeout(50*time.Millisecond))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	sha, err := cl.ScriptLoad(`godis.set(KEYS[1], ARGV[1]); return ARGV[1]`)
+	assert.Nil(t, err)
+	assert.Equal(t, len(sha), 40)
+
+	res, err := cl.EvalSHA(sha, 0, []string{"key"}, "value")
+	assert.Nil(t, err)
+	assert.Equal(t, res, "value")
+	val, err := cl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+
+	_, err = cl.EvalSHA("0000000000000000000000000000000000000000", 0, nil)
+	assert.NotNil(t, err)
+
+	// execution time limit
+	start := time.Now()
+	_, err = cl.Eval(`godis.set(KEYS[1], "changed"); while true do end`, 0, []string{"key"})
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+	_, err = cl.Eval(`while true do end`, 100*time.Millisecond, nil)
+	assert.NotNil(t, err)
+	val, err = cl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
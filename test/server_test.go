@@ -1,23 +1,55 @@
 package test
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
 
 	"github.com/minaevmike/godis/client"
 	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/storage"
 	"github.com/phayes/freeport"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
 
-func startServer(addr string) *server.Server {
+// walDir keeps wal and snapshot files of test servers, every server has its own files
+var walDir string
+
+func TestMain(m *testing.M) {
+	var err error
+	walDir, err = ioutil.TempDir("", "godis")
+	if err != nil {
+		fmt.Println("can't create wal dir:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(walDir)
+	os.Exit(code)
+}
+
+func startServer(addr string, opts ...server.Option) *server.Server {
 	l, _ := zap.NewProduction()
-	s := server.NewServer(l)
+	opts = append([]server.Option{
+		server.WithWALFile(filepath.Join(walDir, addr+".wal")),
+		server.WithSnapshotFile(filepath.Join(walDir, addr+".snapshot")),
+	}, opts...)
+	s := server.NewServer(l, opts...)
 	go s.Run(addr)
-	time.Sleep(time.Millisecond)
+	for i := 0; i < 100; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
 	return s
 }
 
@@ -42,7 +74,7 @@ func TestServer_SetGetString(t *testing.T) {
 	assert.Nil(t, valMap)
 	assert.NotNil(t, err)
 
-	s.Shutdown()
+	s.Shutdown(context.Background())
 	cl.Close()
 }
 
@@ -75,7 +107,7 @@ func TestServer_SetGetSlice(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, val2, "")
 
-	s.Shutdown()
+	s.Shutdown(context.Background())
 	cl.Close()
 }
 
@@ -112,7 +144,7 @@ func TestServer_SetGetMap(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, val2, "")
 
-	s.Shutdown()
+	s.Shutdown(context.Background())
 	cl.Close()
 }
 
@@ -136,7 +168,7 @@ func TestServer_SetGetRemove(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, valStr, "")
 
-	s.Shutdown()
+	s.Shutdown(context.Background())
 	cl.Close()
 }
 
@@ -173,7 +205,7 @@ func TestServer_Keys(t *testing.T) {
 	val, err = cl.Keys("nothing")
 	assert.Nil(t, err)
 	assert.Equal(t, len(val), 0)
-	s.Shutdown()
+	s.Shutdown(context.Background())
 	cl.Close()
 }
 
@@ -208,6 +240,63 @@ func TestServer_Ttl(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, val, "")
 
-	s.Shutdown()
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_Range(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithStorage(storage.NewOrderedStorage()))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	for _, key := range []string{"events:20261017:c", "events:20261017:a", "events:20261018:a", "events:20261016:a", "other"} {
+		err = cl.SetString(key, key, time.Hour)
+		assert.Nil(t, err)
+	}
+
+	keys := func(items []client.KeyValue) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.Key)
+		}
+		return out
+	}
+
+	items, token, err := cl.Range(client.RangeOptions{Start: "events:20261017", End: "events:20261018", Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, keys(items), []string{"events:20261017:a"})
+	assert.Equal(t, items[0].Value.GetStringVal(), "events:20261017:a")
+	assert.NotEqual(t, token, "")
+
+	items, token, err = cl.Range(client.RangeOptions{Start: "events:20261017", End: "events:20261018", Limit: 1, PageToken: token})
+	assert.Nil(t, err)
+	assert.Equal(t, keys(items), []string{"events:20261017:c"})
+	assert.Equal(t, token, "")
+
+	items, token, err = cl.Range(client.RangeOptions{Start: "events:", End: "events;", Reverse: true, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, keys(items), []string{"events:20261018:a", "events:20261017:c"})
+
+	items, token, err = cl.Range(client.RangeOptions{Start: "events:", End: "events;", Reverse: true, PageToken: token})
+	assert.Nil(t, err)
+	assert.Equal(t, keys(items), []string{"events:20261017:a", "events:20261016:a"})
+	assert.Equal(t, token, "")
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_RangeNotSupported(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	items, _, err := cl.Range(client.RangeOptions{})
+	assert.NotNil(t, err)
+	assert.Nil(t, items)
+
+	s.Shutdown(context.Background())
 	cl.Close()
 }
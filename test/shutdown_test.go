@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.SetString("key", "value", time.Hour)
+	assert.Nil(t, err)
+	sub, err := cl.Subscribe("news")
+	assert.Nil(t, err)
+
+	evaluated := make(chan error)
+	go func() {
+		_, err := cl.Eval(`while true do end`, 200*time.Millisecond, nil)
+		evaluated <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	err = s.Shutdown(context.Background())
+	assert.Nil(t, err)
+	// in-flight request is finished before shutdown returns
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+	err = <-evaluated
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "time limit exceeded")
+
+	_, err = net.Dial("tcp", addr)
+	assert.NotNil(t, err)
+	assert.NotNil(t, s.Shutdown(context.Background()))
+	sub.Close()
+	cl.Close()
+
+	// buffered wal records are written on shutdown
+	s = startServer(addr)
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	val, err := cl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_ShutdownTimeout(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	evaluated := make(chan error)
+	go func() {
+		_, err := cl.Eval(`while true do end`, time.Second, nil)
+		evaluated <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = s.Shutdown(ctx)
+	assert.Equal(t, err, context.DeadlineExceeded)
+	// request is interrupted when its connection is closed
+	assert.NotNil(t, <-evaluated)
+
+	cl.Close()
+}
+
+func TestServer_ShutdownBlockedRead(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	popped := make(chan error)
+	go func() {
+		_, _, err := cl.BLPop(context.Background(), 0, "empty")
+		popped <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// request blocked without timeout is finished when server is stopped
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	err = s.Shutdown(ctx)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, <-popped, client.ErrTimeout)
+
+	cl.Close()
+}
+
+func TestServer_ShutdownTimeoutWaitsHandlers(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	// snapshot would wait for script, so only wal contains its write
+	noSnapshots := server.WithSnapshotFile("")
+	s := startServer(addr, noSnapshots)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	go cl.Eval(`for i = 1, 3000000 do end; godis.set("key", "value")`, 0, nil)
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = s.Shutdown(ctx)
+	assert.Equal(t, err, context.DeadlineExceeded)
+	cl.Close()
+
+	// script is finished and its write is saved before wal is closed
+	s = startServer(addr, noSnapshots)
+	cl, err = client.Dial(addr)
+	assert.Nil(t, err)
+	val, err := cl.GetString("key")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+// copyServerFiles copies wal and snapshot files of test server to files of another address,
+// it's used as image of server crashed at this moment
+func copyServerFiles(t *testing.T, from, to string) {
+	files, err := filepath.Glob(filepath.Join(walDir, from+".*"))
+	assert.Nil(t, err)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		target := filepath.Join(walDir, to+strings.TrimPrefix(filepath.Base(file), from))
+		assert.Nil(t, ioutil.WriteFile(target, data, 0644))
+	}
+}
+
+func TestServer_Snapshot(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeGroupCommit)
+	s := startServer(addr, durability)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.SetString("key", "before", time.Hour)
+	assert.Nil(t, err)
+	err = cl.DB(2).SetString("other", "value", time.Hour)
+	assert.Nil(t, err)
+	info, err := cl.Snapshot()
+	assert.Nil(t, err)
+	assert.Equal(t, info.Keys, int64(2))
+	// records included into snapshot are truncated
+	assert.Equal(t, len(walSegments(t, addr)), 1)
+	stats, err := cl.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, *stats.LastSnapshot, info)
+
+	err = cl.SetString("key", "after", time.Hour)
+	assert.Nil(t, err)
+	err = cl.SetString("new", "value", time.Hour)
+	assert.Nil(t, err)
+
+	crashed := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	copyServerFiles(t, addr, crashed)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	// restart loads snapshot and replays wal written after it
+	s = startServer(crashed, durability)
+	cl, err = client.Dial(crashed)
+	assert.Nil(t, err)
+	for key, expected := range map[string]string{"key": "after", "new": "value"} {
+		val, err := cl.GetString(key)
+		assert.Nil(t, err)
+		assert.Equal(t, val, expected)
+	}
+	val, err := cl.DB(2).GetString("other")
+	assert.Nil(t, err)
+	assert.Equal(t, val, "value")
+	stats, err = cl.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, stats.LastSnapshot.WALPosition, info.WALPosition)
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_SnapshotWhileWriting(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeGroupCommit)
+	s := startServer(addr, durability)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				assert.Nil(t, cl.SetString(fmt.Sprintf("key%d", w), fmt.Sprint(i), time.Hour))
+			}
+		}(w)
+	}
+	for i := 0; i < 5; i++ {
+		_, err := cl.Snapshot()
+		assert.Nil(t, err)
+	}
+	wg.Wait()
+
+	crashed := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	copyServerFiles(t, addr, crashed)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	s = startServer(crashed, durability)
+	cl, err = client.Dial(crashed)
+	assert.Nil(t, err)
+	for w := 0; w < 4; w++ {
+		val, err := cl.GetString(fmt.Sprintf("key%d", w))
+		assert.Nil(t, err)
+		assert.Equal(t, val, "199")
+	}
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_ScheduledSnapshot(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithSnapshotInterval(20*time.Millisecond))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	stats, err := cl.Stats()
+	assert.Nil(t, err)
+	assert.NotNil(t, stats.LastSnapshot)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_SnapshotsDisabled(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr, server.WithDurability(wal.ModeNone))
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	_, err = cl.Snapshot()
+	assert.NotNil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,260 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/minaevmike/godis/client"
+	"github.com/minaevmike/godis/server"
+	"github.com/minaevmike/godis/wal"
+	"github.com/phayes/freeport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Stream(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	id, err := cl.XAdd("stream", "1-1", map[string]string{"a": "1"}, 0, time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, id, "1-1")
+	_, err = cl.XAdd("stream", "1-1", map[string]string{"a": "2"}, 0, time.Hour)
+	assert.NotNil(t, err)
+	for i := 2; i <= 5; i++ {
+		_, err = cl.XAdd("stream", client.StreamIDAuto, map[string]string{"a": fmt.Sprint(i)}, 0, time.Hour)
+		assert.Nil(t, err)
+	}
+
+	n, err := cl.XLen("stream")
+	assert.Nil(t, err)
+	assert.Equal(t, n, 5)
+
+	entries, err := cl.XRange("stream", client.StreamIDMin, client.StreamIDMax, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), 2)
+	assert.Equal(t, entries[0].ID, "1-1")
+	assert.Equal(t, entries[1].Fields["a"], "2")
+
+	entries, err = cl.XRevRange("stream", entries[1].ID, client.StreamIDMax, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), 4)
+	assert.Equal(t, entries[0].Fields["a"], "5")
+	assert.Equal(t, entries[3].Fields["a"], "2")
+
+	n, err = cl.XTrim("stream", 3)
+	assert.Nil(t, err)
+	assert.Equal(t, n, 2)
+	entries, err = cl.XRange("stream", client.StreamIDMin, client.StreamIDMax, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), 3)
+	assert.Equal(t, entries[0].Fields["a"], "3")
+
+	_, err = cl.XAdd("stream", client.StreamIDAuto, map[string]string{"a": "6"}, 3, time.Hour)
+	assert.Nil(t, err)
+	n, err = cl.XLen("stream")
+	assert.Nil(t, err)
+	assert.Equal(t, n, 3)
+
+	read, err := cl.XRead(context.Background(), client.XReadArgs{Streams: map[string]string{"stream": entries[1].ID, "empty": "0"}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(read), 1)
+	assert.Equal(t, len(read["stream"]), 2)
+	assert.Equal(t, read["stream"][1].Fields["a"], "6")
+
+	err = cl.SetString("string", "value", time.Hour)
+	assert.Nil(t, err)
+	_, err = cl.XAdd("string", client.StreamIDAuto, map[string]string{"a": "1"}, 0, time.Hour)
+	assert.NotNil(t, err)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_StreamBlockingRead(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	_, err = cl.XAdd("feed", client.StreamIDAuto, map[string]string{"a": "old"}, 0, time.Hour)
+	assert.Nil(t, err)
+
+	args := client.XReadArgs{Streams: map[string]string{"feed": client.StreamIDLast}, Block: true, Timeout: 50 * time.Millisecond}
+	_, err = cl.XRead(context.Background(), args)
+	assert.Equal(t, err, client.ErrTimeout)
+
+	results := make(chan map[string][]client.StreamEntry, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			args := client.XReadArgs{Streams: map[string]string{"feed": client.StreamIDLast}, Block: true}
+			read, err := cl.XRead(context.Background(), args)
+			assert.Nil(t, err)
+			results <- read
+		}()
+	}
+	// let readers block
+	time.Sleep(100 * time.Millisecond)
+	_, err = cl.XAdd("feed", client.StreamIDAuto, map[string]string{"a": "new"}, 0, time.Hour)
+	assert.Nil(t, err)
+
+	// every reader receives new entry
+	for i := 0; i < 2; i++ {
+		select {
+		case read := <-results:
+			assert.Equal(t, len(read["feed"]), 1)
+			assert.Equal(t, read["feed"][0].Fields["a"], "new")
+		case <-time.After(time.Second):
+			t.Fatal("reader wasn't served")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	_, err = cl.XRead(ctx, client.XReadArgs{Streams: map[string]string{"feed": client.StreamIDLast}, Block: true})
+	cancel()
+	assert.Equal(t, err, context.DeadlineExceeded)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_StreamConsumerGroup(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	s := startServer(addr)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	err = cl.XGroupCreate("missing", "group", "0")
+	assert.NotNil(t, err)
+	for i := 1; i <= 3; i++ {
+		_, err = cl.XAdd("jobs", client.StreamIDAuto, map[string]string{"n": fmt.Sprint(i)}, 0, time.Hour)
+		assert.Nil(t, err)
+	}
+	err = cl.XGroupCreate("jobs", "group", "0")
+	assert.Nil(t, err)
+	err = cl.XGroupCreate("jobs", "group", "0")
+	assert.NotNil(t, err)
+
+	newEntries := map[string]string{"jobs": client.StreamIDNew}
+	read, err := cl.XReadGroup(context.Background(), "group", "alice", client.XReadArgs{Streams: newEntries, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, len(read["jobs"]), 2)
+	assert.Equal(t, read["jobs"][0].Fields["n"], "1")
+
+	read, err = cl.XReadGroup(context.Background(), "group", "bob", client.XReadArgs{Streams: newEntries})
+	assert.Nil(t, err)
+	assert.Equal(t, len(read["jobs"]), 1)
+	assert.Equal(t, read["jobs"][0].Fields["n"], "3")
+	bobID := read["jobs"][0].ID
+
+	// nothing left for group
+	read, err = cl.XReadGroup(context.Background(), "group", "bob", client.XReadArgs{Streams: newEntries})
+	assert.Nil(t, err)
+	assert.Equal(t, len(read), 0)
+
+	pending, err := cl.XPending("jobs", "group", "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(pending), 3)
+	pending, err = cl.XPending("jobs", "group", "alice")
+	assert.Nil(t, err)
+	assert.Equal(t, len(pending), 2)
+	assert.Equal(t, pending[0].DeliveryCount, 1)
+
+	// history of pending entries
+	read, err = cl.XReadGroup(context.Background(), "group", "alice", client.XReadArgs{Streams: map[string]string{"jobs": "0"}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(read["jobs"]), 2)
+
+	n, err := cl.XAck("jobs", "group", pending[0].ID, bobID)
+	assert.Nil(t, err)
+	assert.Equal(t, n, 2)
+	n, err = cl.XAck("jobs", "group", bobID)
+	assert.Nil(t, err)
+	assert.Equal(t, n, 0)
+
+	pending, err = cl.XPending("jobs", "group", "")
+	assert.Nil(t, err)
+	assert.Equal(t, len(pending), 1)
+	assert.Equal(t, pending[0].Consumer, "alice")
+
+	done := make(chan map[string][]client.StreamEntry)
+	go func() {
+		read, err := cl.XReadGroup(context.Background(), "group", "bob", client.XReadArgs{Streams: newEntries, Block: true})
+		assert.Nil(t, err)
+		done <- read
+	}()
+	time.Sleep(100 * time.Millisecond)
+	_, err = cl.XAdd("jobs", client.StreamIDAuto, map[string]string{"n": "4"}, 0, time.Hour)
+	assert.Nil(t, err)
+	select {
+	case read := <-done:
+		assert.Equal(t, read["jobs"][0].Fields["n"], "4")
+	case <-time.After(time.Second):
+		t.Fatal("consumer wasn't served")
+	}
+
+	pending, err = cl.XPending("jobs", "group", "bob")
+	assert.Nil(t, err)
+	assert.Equal(t, len(pending), 1)
+
+	s.Shutdown(context.Background())
+	cl.Close()
+}
+
+func TestServer_StreamReplay(t *testing.T) {
+	addr := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	durability := server.WithDurability(wal.ModeFsync)
+	s := startServer(addr, durability)
+	cl, err := client.Dial(addr)
+	assert.Nil(t, err)
+
+	for i := 1; i <= 100; i++ {
+		_, err = cl.XAdd("jobs", client.StreamIDAuto, map[string]string{"n": fmt.Sprint(i)}, 0, time.Hour)
+		assert.Nil(t, err)
+	}
+	// record contains only appended entry
+	size := walSize(t, addr)
+	_, err = cl.XAdd("jobs", client.StreamIDAuto, map[string]string{"n": "101"}, 0, time.Hour)
+	assert.Nil(t, err)
+	assert.True(t, walSize(t, addr)-size < 200)
+
+	_, err = cl.XAdd("jobs", client.StreamIDAuto, map[string]string{"n": "102"}, 100, time.Hour)
+	assert.Nil(t, err)
+	n, err := cl.XTrim("jobs", 50)
+	assert.Nil(t, err)
+	assert.Equal(t, n, 50)
+	err = cl.XGroupCreate("jobs", "group", "0")
+	assert.Nil(t, err)
+	read, err := cl.XReadGroup(context.Background(), "group", "alice", client.XReadArgs{Streams: map[string]string{"jobs": client.StreamIDNew}, Limit: 3})
+	assert.Nil(t, err)
+	assert.Equal(t, len(read["jobs"]), 3)
+	n, err = cl.XAck("jobs", "group", read["jobs"][0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, n, 1)
+
+	crashed := fmt.Sprintf("localhost:%d", freeport.GetPort())
+	copyServerFiles(t, addr, crashed)
+	s.Shutdown(context.Background())
+	cl.Close()
+
+	s = startServer(crashed, durability)
+	cl, err = client.Dial(crashed)
+	assert.Nil(t, err)
+	entries, err := cl.XRange("jobs", client.StreamIDMin, client.StreamIDMax, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), 50)
+	assert.Equal(t, entries[0].Fields["n"], "53")
+	assert.Equal(t, entries[49].Fields["n"], "102")
+	pending, err := cl.XPending("jobs", "group", "alice")
+	assert.Nil(t, err)
+	assert.Equal(t, len(pending), 2)
+	assert.Equal(t, pending[0].ID, read["jobs"][1].ID)
+	read, err = cl.XReadGroup(context.Background(), "group", "alice", client.XReadArgs{Streams: map[string]string{"jobs": client.StreamIDNew}, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, read["jobs"][0].Fields["n"], "56")
+	s.Shutdown(context.Background())
+	cl.Close()
+}
@@ -0,0 +1,80 @@
+package wal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// encryptedFlag is set in command of encrypted records, key and value are sealed together into value then
+const encryptedFlag Command = 0x20
+
+// ErrKeyRequired is returned when encrypted record is read without cipher
+var ErrKeyRequired = errors.New("record is encrypted, encryption key is required")
+
+// Cipher encrypts records, it's implemented by encryption.Keyring
+type Cipher interface {
+	// Seal encrypts plaintext, additional data is authenticated, but not encrypted
+	Seal(plaintext, additional []byte) ([]byte, error)
+	// Open decrypts sealed data, error is returned if key is wrong or data is damaged
+	Open(sealed, additional []byte) ([]byte, error)
+}
+
+// Encrypt returns copy of record with key and value sealed into value. Command, database and LSN stay
+// readable, they are authenticated with sealed data
+func (r *Record) Encrypt(c Cipher) (*Record, error) {
+	if c == nil || r.Encrypted {
+		return r, nil
+	}
+	plaintext := make([]byte, 8, 8+len(r.Key)+len(r.Value))
+	binary.BigEndian.PutUint64(plaintext, uint64(len(r.Key)))
+	plaintext = append(append(plaintext, r.Key...), r.Value...)
+	sealed, err := c.Seal(plaintext, r.additional())
+	if err != nil {
+		return nil, err
+	}
+	e := *r
+	e.Key, e.Value, e.Encrypted = nil, sealed, true
+	return &e, nil
+}
+
+// Decrypt returns copy of encrypted record with key and value opened, record which isn't encrypted
+// is returned as is
+func (r *Record) Decrypt(c Cipher) (*Record, error) {
+	if !r.Encrypted {
+		return r, nil
+	}
+	if c == nil {
+		return nil, ErrKeyRequired
+	}
+	plaintext, err := c.Open(r.Value, r.additional())
+	if err != nil {
+		return nil, fmt.Errorf("can't decrypt record: %v", err)
+	}
+	if len(plaintext) < 8 || binary.BigEndian.Uint64(plaintext) > uint64(len(plaintext)-8) {
+		return nil, errors.New("decrypted record is malformed")
+	}
+	keyLen := binary.BigEndian.Uint64(plaintext)
+	d := *r
+	d.Key, d.Value, d.Encrypted = plaintext[8:8+keyLen], plaintext[8+keyLen:], false
+	return &d, nil
+}
+
+// additional returns fields of record which are authenticated with sealed data
+func (r *Record) additional() []byte {
+	b := make([]byte, 13)
+	b[0] = byte(r.Cmd)
+	binary.BigEndian.PutUint32(b[1:], r.DB)
+	binary.BigEndian.PutUint64(b[5:], r.LSN)
+	return b
+}
+
+// encode writes record encrypted with c, nil c means plaintext
+func encode(w io.Writer, r *Record, c Cipher) (int64, error) {
+	r, err := r.Encrypt(c)
+	if err != nil {
+		return 0, err
+	}
+	return r.WriteTo(w)
+}
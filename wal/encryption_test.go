@@ -0,0 +1,92 @@
+package wal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minaevmike/godis/encryption"
+	"go.uber.org/zap"
+)
+
+func testKeyring(t *testing.T, keys string) Cipher {
+	k, err := encryption.Parse(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestEncryption_Replay(t *testing.T) {
+	file := tempLog(t)
+	oldKey := "1:000102030405060708090a0b0c0d0e0f"
+	w, err := NewFsyncWAL(file, Options{Cipher: testKeyring(t, oldKey)}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("secret-key"), []byte("secret-value"))
+	w.Close()
+
+	// key is rotated, records sealed with old key are still read
+	rotated := testKeyring(t, oldKey+"\n2:101112131415161718191a1b1c1d1e1f")
+	w, err = NewFsyncWAL(file, Options{Cipher: rotated}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("new-key"), []byte("new-value"))
+	var keys []string
+	err = replayWAL(w, func(r *Record) {
+		keys = append(keys, string(r.Key))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	assertKeys(t, keys, "secret-key", "new-key")
+
+	files, err := filepath.Glob(file + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("secret")) || bytes.Contains(data, []byte("new-")) {
+			t.Fatalf("plaintext is visible in %s", f)
+		}
+	}
+}
+
+func TestEncryption_WrongKey(t *testing.T) {
+	file := tempLog(t)
+	w, err := NewFsyncWAL(file, Options{Cipher: testKeyring(t, "1:000102030405060708090a0b0c0d0e0f")}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("a"), []byte("value"))
+	w.Close()
+
+	for _, c := range []Cipher{nil, testKeyring(t, "1:"+strings.Repeat("ff", 16))} {
+		w, err := NewFsyncWAL(file, Options{Cipher: c}, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = replayWAL(w, func(r *Record) {
+			t.Fatalf("record %q is replayed with wrong key", r.Key)
+		})
+		w.Close()
+		if err == nil {
+			t.Fatal("log is replayed with wrong key")
+		}
+		if c == nil && err != ErrKeyRequired {
+			t.Fatalf("expected %v, got %v", ErrKeyRequired, err)
+		}
+		if c != nil && !strings.Contains(err.Error(), encryption.ErrDecrypt.Error()) {
+			t.Fatalf("expected decrypt error, got %v", err)
+		}
+	}
+}
@@ -1,59 +1,115 @@
 package wal
 
 import (
-	"io"
-	"os"
+	"bytes"
 	"sync"
 
 	"go.uber.org/zap"
 )
 
+// fsyncWal writes and syncs every record before write returns
 type fsyncWal struct {
-	f      *os.File
+	log    *segmentedLog
 	mu     sync.Locker
 	logger *zap.Logger
+	// err is set by the first failed write, WAL doesn't accept records after it
+	err error
 }
 
 func (fw *fsyncWal) Write(cmd Command, key []byte, data []byte) error {
+	return fw.WriteBatch([]*Record{{Value: data, Cmd: cmd, Key: key}})
+}
+
+func (fw *fsyncWal) WriteBatch(records []*Record) error {
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
-	r := &Record{Value: data, Cmd: cmd, Key: key}
-	_, err := r.WriteTo(fw.f)
+	if fw.err != nil {
+		return fw.err
+	}
+	fw.log.lsn.assign(records)
+	b := &bytes.Buffer{}
+	for _, r := range records {
+		if _, err := encode(b, r, fw.log.opts.Cipher); err != nil {
+			return err
+		}
+	}
+	_, err := b.WriteTo(fw.log)
+	if err == nil {
+		err = fw.log.Sync()
+	}
+	fw.err = err
 	return err
 }
 
-func NewFsyncWAL(file string, logger *zap.Logger, cb func(record *Record)) WAL {
-	var walFile *os.File
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		walFile, err = os.Create(file)
-	} else {
-		walFile, err = os.OpenFile(file, os.O_RDWR, 0644)
-		if err != nil {
-			logger.Error("can't create file", zap.Error(err))
-			return nil
-		}
-		for {
-			c := &Record{}
-			_, err := c.ReadFrom(walFile)
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				logger.Error("can't read data", zap.Error(err))
-				return nil
-			}
-			if cb != nil {
-				cb(c)
-			}
-			logger.Debug("read wal record", zap.String("Key", string(c.Key)))
-		}
+// Sync returns error of failed write, written records are already synced
+func (fw *fsyncWal) Sync() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.err
+}
+
+// LastLSN returns LSN of the last written record
+func (fw *fsyncWal) LastLSN() uint64 {
+	return fw.log.lsn.last()
+}
+
+// Replay returns iterator over records of log
+func (fw *fsyncWal) Replay() (Iterator, error) {
+	return fw.log.Replay()
+}
+
+// Subscribe returns iterator over records starting from LSN, see WAL
+func (fw *fsyncWal) Subscribe(from uint64) (Iterator, error) {
+	return fw.log.Subscribe(from)
+}
+
+// Compact rewrites log with current dataset, see Compactor
+func (fw *fsyncWal) Compact() error {
+	return fw.log.Compact()
+}
+
+// Checkpoint rotates log, see Checkpointer
+func (fw *fsyncWal) Checkpoint() (uint64, error) {
+	return fw.log.Checkpoint()
+}
+
+// Truncate deletes segments before seq, see Checkpointer
+func (fw *fsyncWal) Truncate(seq uint64) error {
+	return fw.log.Truncate(seq)
+}
+
+// Hold keeps segments from being deleted, see Checkpointer
+func (fw *fsyncWal) Hold() func() {
+	return fw.log.Hold()
+}
+
+// ReadSegments reads records of segments, see Checkpointer
+func (fw *fsyncWal) ReadSegments(from, to uint64, cb func(record *Record)) error {
+	return fw.log.ReadSegments(from, to, cb)
+}
+
+func (fw *fsyncWal) Close() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.err == ErrClosed {
+		return ErrClosed
+	}
+	fw.err = ErrClosed
+	return fw.log.Close()
+}
+
+// NewFsyncWAL opens log file, every write is synced before it returns
+func NewFsyncWAL(file string, opts Options, logger *zap.Logger) (WAL, error) {
+	log, err := openSegmentedLog(file, opts, logger)
+	if err != nil {
+		return nil, err
 	}
 
 	w := &fsyncWal{
 		logger: logger,
-		f:      walFile,
+		log:    log,
 		mu:     &sync.Mutex{},
 	}
 
-	return w
+	return w, nil
 }
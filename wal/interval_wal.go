@@ -2,8 +2,9 @@ package wal
 
 import (
 	"encoding/binary"
+	"errors"
+	"hash/crc32"
 	"io"
-	"os"
 	"sync"
 	"time"
 
@@ -13,15 +14,26 @@ import (
 )
 
 type Record struct {
-	Cmd   Command
+	Cmd Command
+	// DB - index of database record belongs to
+	DB    uint32
 	Key   []byte
 	Value []byte
+	// LSN - log sequence number assigned by WAL on write, records of dumps have zero LSN
+	LSN uint64
+	// Time - unix time in nanoseconds when record was written to WAL
+	Time int64
+	// Encrypted - key and value are sealed into value, see Encrypt
+	Encrypted bool
 }
 
 // this is simple binary serialization format
-// KLKLKLKLVLVLVLVLCCCCK....KV....V
-// |..Key len 8 byte.||..Value len 8 byte..||..command 1 byte ...||..Key..||..Value..|
-//
+// KLKLKLKLVLVLVLVLCCCCK....KV....VSSSS
+// |..Key len 8 byte.||..Value len 8 byte..||..command 1 byte ...||..Key..||..Value..||..CRC 4 byte..|
+// For non zero database dbFlag is set in command and 4 byte database index follows command.
+// For records with LSN metaFlag is set in command and 8 byte LSN and 8 byte time follow command and database index.
+// For encrypted records encryptedFlag is set in command and key is empty.
+// CRC is castagnoli checksum of all previous bytes of record, legacy logs have no CRC
 func (r *Record) WriteTo(w io.Writer) (int64, error) {
 	b := &bytes.Buffer{}
 	// Key len
@@ -36,7 +48,23 @@ func (r *Record) WriteTo(w io.Writer) (int64, error) {
 	}
 
 	//Cmd
-	err = binary.Write(b, binary.BigEndian, r.Cmd)
+	cmd := r.Cmd
+	if r.DB != 0 {
+		cmd |= dbFlag
+	}
+	if r.LSN != 0 {
+		cmd |= metaFlag
+	}
+	if r.Encrypted {
+		cmd |= encryptedFlag
+	}
+	err = binary.Write(b, binary.BigEndian, cmd)
+	if err == nil && r.DB != 0 {
+		err = binary.Write(b, binary.BigEndian, r.DB)
+	}
+	if err == nil && r.LSN != 0 {
+		err = binary.Write(b, binary.BigEndian, [2]uint64{r.LSN, uint64(r.Time)})
+	}
 	if err != nil {
 		return int64(b.Len()), err
 	}
@@ -52,147 +80,443 @@ func (r *Record) WriteTo(w io.Writer) (int64, error) {
 	if err != nil {
 		return int64(b.Len()), err
 	}
+
+	// CRC
+	err = binary.Write(b, binary.BigEndian, crc32.Checksum(b.Bytes(), crcTable))
+	if err != nil {
+		return int64(b.Len()), err
+	}
 	return io.Copy(w, b)
 }
 
+// ReadFrom reads record and checks its CRC, io.EOF is returned only if there are no more records
 func (r *Record) ReadFrom(rr io.Reader) (int64, error) {
-	total := int64(0)
-
-	keyLen := int64(0)
+	return r.read(rr, -1, true)
+}
 
-	err := binary.Read(rr, binary.BigEndian, &keyLen)
-	if err != nil {
-		return total, err
+// read reads record which must fit into limit bytes, negative limit means no limit.
+// errTorn is returned if record is cut off, errChecksum if it's damaged, errLength if it doesn't fit into limit
+func (r *Record) read(rr io.Reader, limit int64, checksum bool) (int64, error) {
+	h := crc32.New(crcTable)
+	if checksum {
+		rr = io.TeeReader(rr, h)
+	}
+	var header [17]byte
+	n, err := io.ReadFull(rr, header[:])
+	total := int64(n)
+	if err == io.ErrUnexpectedEOF {
+		return total, errTorn
 	}
-
-	total += 8
-
-	valueLen := int64(0)
-
-	err = binary.Read(rr, binary.BigEndian, &valueLen)
 	if err != nil {
 		return total, err
 	}
 
-	total += 8
-
-	err = binary.Read(rr, binary.BigEndian, &r.Cmd)
-	if err != nil {
-		return total, err
+	keyLen := int64(binary.BigEndian.Uint64(header[0:8]))
+	valueLen := int64(binary.BigEndian.Uint64(header[8:16]))
+	r.Cmd = Command(header[16])
+	r.Encrypted = r.Cmd&encryptedFlag != 0
+	r.Cmd &^= encryptedFlag
+	r.DB = 0
+	if r.Cmd&dbFlag != 0 {
+		r.Cmd &^= dbFlag
+		err = binary.Read(rr, binary.BigEndian, &r.DB)
+		if err != nil {
+			return total, errTorn
+		}
+		total += 4
+	}
+	r.LSN, r.Time = 0, 0
+	if r.Cmd&metaFlag != 0 {
+		r.Cmd &^= metaFlag
+		var meta [2]uint64
+		if err = binary.Read(rr, binary.BigEndian, &meta); err != nil {
+			return total, errTorn
+		}
+		r.LSN, r.Time = meta[0], int64(meta[1])
+		total += 16
 	}
 
-	total += 1
+	if keyLen < 0 || valueLen < 0 {
+		return total, errChecksum
+	}
+	if limit >= 0 && (keyLen > limit-total || valueLen > limit-total-keyLen) {
+		return total, errLength
+	}
 
 	r.Key = make([]byte, keyLen)
-
-	n, err := io.ReadFull(rr, r.Key)
+	n, err = io.ReadFull(rr, r.Key)
+	total += int64(n)
 	if err != nil {
-		return total, err
+		return total, errTorn
 	}
 
-	total += int64(n)
-
 	r.Value = make([]byte, valueLen)
 	n, err = io.ReadFull(rr, r.Value)
+	total += int64(n)
 	if err != nil {
-		return total, err
+		return total, errTorn
 	}
 
-	total += int64(n)
+	if !checksum {
+		return total, nil
+	}
+	sum := h.Sum32()
+	var stored uint32
+	if err = binary.Read(rr, binary.BigEndian, &stored); err != nil {
+		return total, errTorn
+	}
+	total += 4
+	if stored != sum {
+		return total, errChecksum
+	}
 	return total, nil
 }
 
-type syncedWALRecords struct {
-	mu      sync.Mutex
-	records []*Record
+// Options configure WAL, flush options are used only by interval and group commit WALs
+type Options struct {
+	// Interval - max time record waits in buffer before it's written and synced
+	Interval time.Duration
+	// BatchSize - buffer is flushed as soon as it has this amount of records, zero means only interval flushes
+	BatchSize int
+	// MaxBuffered - writers are blocked while buffer has this amount of records, zero means no limit
+	MaxBuffered int
+	// OnError - called when flush fails, by default error is logged
+	OnError func(err error)
+	// Strict - refuse to open log which is corrupted before its tail, by default log is truncated
+	// at the first corrupted record. Torn tail left by crash is truncated in both modes
+	Strict bool
+	// SegmentSize - log is rotated to new segment when active segment reaches this size, zero means one segment
+	SegmentSize int64
+	// CompactSize - background compaction is started when log is larger than this size and twice larger
+	// than after the last compaction, zero disables background compaction
+	CompactSize int64
+	// ReplayFrom - sequence number of the first segment which isn't included into snapshot,
+	// older segments are deleted without replay
+	ReplayFrom uint64
+	// StartLSN - LSN of the last record included into snapshot, new records get greater LSNs
+	StartLSN uint64
+	// Cipher encrypts written records, nil means plaintext. Records written before encryption was enabled
+	// are read as is
+	Cipher Cipher
+	// Dump writes all live data as records, it's used by compaction and must return consistent dataset:
+	// effects of all records written before it starts
+	Dump func(write func(record *Record) error) error
+	// BeginDump is called by compaction before log is rotated for dump, records written after it
+	// until end is called are replayed after dump, so they must not change values relative to them
+	BeginDump func() (end func(), err error)
 }
 
-func (wr *syncedWALRecords) Add(record *Record) {
-	wr.mu.Lock()
-	wr.records = append(wr.records, record)
-	wr.mu.Unlock()
-}
-
-func (wr *syncedWALRecords) Swap() []*Record {
-	wr.mu.Lock()
-	old := wr.records
-	wr.records = make([]*Record, 0)
-	wr.mu.Unlock()
-	return old
+// DefaultOptions returns options used by server by default
+func DefaultOptions() Options {
+	return Options{
+		Interval:    time.Second,
+		BatchSize:   1024,
+		MaxBuffered: 64 * 1024,
+		SegmentSize: 64 << 20,
+		CompactSize: 64 << 20,
+	}
 }
 
-func NewIntervalWAL(file string, timeout time.Duration, logger *zap.Logger, cb func(record *Record)) WAL {
-	var walFile *os.File
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		walFile, err = os.Create(file)
-	} else {
-		walFile, err = os.OpenFile(file, os.O_RDWR, 0644)
-		if err != nil {
-			logger.Error("can't create file", zap.Error(err))
-			return nil
-		}
-		for {
-			c := &Record{}
-			_, err := c.ReadFrom(walFile)
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				logger.Error("can't read data", zap.Error(err))
-				return nil
-			}
-			if cb != nil {
-				cb(c)
-			}
-			logger.Debug("read wal record", zap.String("Key", string(c.Key)))
-		}
+// NewIntervalWAL opens log file and starts group commit writer.
+// Records are buffered and written with one write and sync per flush
+func NewIntervalWAL(file string, opts Options, logger *zap.Logger) (WAL, error) {
+	log, err := openSegmentedLog(file, opts, logger)
+	if err != nil {
+		return nil, err
 	}
 
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultOptions().Interval
+	}
 	w := &intervalWAL{
-		logger:           logger,
-		timeout:          timeout,
-		w:                walFile,
-		syncedWALRecords: &syncedWALRecords{},
+		logger:   logger,
+		opts:     opts,
+		w:        log,
+		lsn:      log.lsn,
+		flushNow: make(chan struct{}, 1),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
 	}
+	w.cond = sync.NewCond(&w.mu)
 
 	go w.monitor()
 
-	return w
+	return w, nil
 }
 
 type walWriter interface {
-	io.Writer
+	io.WriteCloser
 	Sync() error
 }
 
+// intervalWAL is group commit writer: records of all writers are buffered and flushed together
+// on interval, when batch is full or when somebody waits for durability
 type intervalWAL struct {
-	w                walWriter
-	timeout          time.Duration
-	syncedWALRecords *syncedWALRecords
-	logger           *zap.Logger
+	opts   Options
+	logger *zap.Logger
+
+	// mu guards fields below, cond is signalled after buffer is taken by flush and after flush is finished
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  []*Record
+	// written - amount of records ever buffered, durable - amount of records written and synced
+	written uint64
+	durable uint64
+	// err is set by the first failed flush, WAL doesn't accept records after it,
+	// because it's unknown which part of failed flush reached disk
+	err    error
+	closed bool
+	// lsn assigns LSNs of buffered records, so they are ordered like records in buffer
+	lsn *lsnCounter
+
+	// flushMu serializes flushes and guards w
+	flushMu  sync.Mutex
+	w        walWriter
+	flushNow chan struct{}
+	closing  chan struct{}
+	done     chan struct{}
 }
 
 func (iw *intervalWAL) Write(cmd Command, key []byte, data []byte) error {
-	iw.syncedWALRecords.Add(&Record{
+	return iw.WriteBatch([]*Record{{
 		Cmd:   cmd,
 		Key:   key,
 		Value: data,
-	})
+	}})
+}
+
+// WriteBatch buffers records, it blocks while buffer is full
+func (iw *intervalWAL) WriteBatch(records []*Record) error {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	for iw.err == nil && !iw.closed && iw.opts.MaxBuffered > 0 &&
+		len(iw.buf) > 0 && len(iw.buf)+len(records) > iw.opts.MaxBuffered {
+		iw.kick()
+		iw.cond.Wait()
+	}
+	if iw.err != nil {
+		return iw.err
+	}
+	if iw.closed {
+		return ErrClosed
+	}
+	iw.lsn.assign(records)
+	iw.buf = append(iw.buf, records...)
+	iw.written += uint64(len(records))
+	if iw.opts.BatchSize > 0 && len(iw.buf) >= iw.opts.BatchSize {
+		iw.kick()
+	}
 	return nil
 }
 
-func (iw *intervalWAL) monitor() {
-	t := time.NewTicker(iw.timeout)
+// Sync waits until all records written before it are durable, concurrent calls share one flush
+func (iw *intervalWAL) Sync() error {
+	iw.mu.Lock()
+	defer iw.mu.Unlock()
+	target := iw.written
+	for iw.durable < target && iw.err == nil {
+		iw.kick()
+		iw.cond.Wait()
+	}
+	return iw.err
+}
+
+// LastLSN returns LSN of the last written record, it can be not flushed yet
+func (iw *intervalWAL) LastLSN() uint64 {
+	return iw.lsn.last()
+}
 
+// kick asks monitor to flush buffer now
+func (iw *intervalWAL) kick() {
 	select {
-	case <-t.C:
-		data := iw.syncedWALRecords.Swap()
-		for i := range data {
-			_, err := data[i].WriteTo(iw.w)
-			if err != nil {
-				iw.logger.Error("can't write record", zap.Error(err))
-			}
+	case iw.flushNow <- struct{}{}:
+	default:
+	}
+}
+
+func (iw *intervalWAL) monitor() {
+	defer close(iw.done)
+	t := time.NewTicker(iw.opts.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-iw.flushNow:
+		case <-iw.closing:
+			return
+		}
+		iw.flush()
+	}
+}
+
+// flush writes buffered records to file with one write and syncs it
+func (iw *intervalWAL) flush() error {
+	iw.flushMu.Lock()
+	defer iw.flushMu.Unlock()
+
+	iw.mu.Lock()
+	records, target, failed := iw.buf, iw.written, iw.err
+	iw.buf = nil
+	iw.cond.Broadcast()
+	iw.mu.Unlock()
+	if len(records) == 0 || failed != nil {
+		return failed
+	}
+
+	b := &bytes.Buffer{}
+	var err error
+	for _, r := range records {
+		if _, err = encode(b, r, iw.opts.Cipher); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		_, err = b.WriteTo(iw.w)
+	}
+	if err == nil {
+		err = iw.w.Sync()
+	}
+
+	iw.mu.Lock()
+	if err != nil {
+		iw.err = err
+	} else {
+		iw.durable = target
+	}
+	iw.cond.Broadcast()
+	iw.mu.Unlock()
+
+	if err != nil {
+		if iw.opts.OnError != nil {
+			iw.opts.OnError(err)
+		} else {
+			iw.logger.Error("can't flush wal", zap.Error(err))
 		}
-		iw.w.Sync()
 	}
+	return err
+}
+
+// logReader is implemented by log which records can be read
+type logReader interface {
+	Replay() (Iterator, error)
+	Subscribe(from uint64) (Iterator, error)
+}
+
+// Replay returns iterator over flushed records of log
+func (iw *intervalWAL) Replay() (Iterator, error) {
+	r, ok := iw.w.(logReader)
+	if !ok {
+		return nil, errors.New("wal can't be read")
+	}
+	return r.Replay()
+}
+
+// Subscribe returns iterator over records starting from LSN, see WAL. Records are read after they are flushed
+func (iw *intervalWAL) Subscribe(from uint64) (Iterator, error) {
+	r, ok := iw.w.(logReader)
+	if !ok {
+		return nil, errors.New("wal can't be read")
+	}
+	return r.Subscribe(from)
+}
+
+// Compact rewrites log with current dataset, see Compactor
+func (iw *intervalWAL) Compact() error {
+	c, ok := iw.w.(Compactor)
+	if !ok {
+		return errors.New("wal can't be compacted")
+	}
+	return c.Compact()
+}
+
+// Checkpoint rotates log, see Checkpointer
+func (iw *intervalWAL) Checkpoint() (uint64, error) {
+	c, ok := iw.w.(Checkpointer)
+	if !ok {
+		return 0, errors.New("wal doesn't support checkpoints")
+	}
+	return c.Checkpoint()
+}
+
+// Truncate deletes segments before seq, see Checkpointer
+func (iw *intervalWAL) Truncate(seq uint64) error {
+	c, ok := iw.w.(Checkpointer)
+	if !ok {
+		return errors.New("wal doesn't support checkpoints")
+	}
+	return c.Truncate(seq)
+}
+
+// Hold keeps segments from being deleted, see Checkpointer
+func (iw *intervalWAL) Hold() func() {
+	c, ok := iw.w.(Checkpointer)
+	if !ok {
+		return func() {}
+	}
+	return c.Hold()
+}
+
+// ReadSegments reads records of segments, see Checkpointer
+func (iw *intervalWAL) ReadSegments(from, to uint64, cb func(record *Record)) error {
+	c, ok := iw.w.(Checkpointer)
+	if !ok {
+		return errors.New("wal doesn't support checkpoints")
+	}
+	return c.ReadSegments(from, to, cb)
+}
+
+// Close stops monitor, flushes buffered records and closes file
+func (iw *intervalWAL) Close() error {
+	iw.mu.Lock()
+	if iw.closed {
+		iw.mu.Unlock()
+		return ErrClosed
+	}
+	iw.closed = true
+	iw.cond.Broadcast()
+	iw.mu.Unlock()
+
+	close(iw.closing)
+	<-iw.done
+	err := iw.flush()
+
+	iw.flushMu.Lock()
+	defer iw.flushMu.Unlock()
+	if closeErr := iw.w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// NewGroupCommitWAL opens log file like NewIntervalWAL, but Write returns only after its record is synced,
+// batches are synced by Sync. Records of concurrent writers are synced together, so one fsync is shared by many writes
+func NewGroupCommitWAL(file string, opts Options, logger *zap.Logger) (WAL, error) {
+	w, err := NewIntervalWAL(file, opts, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &groupCommitWAL{intervalWAL: w.(*intervalWAL)}, nil
+}
+
+type groupCommitWAL struct {
+	*intervalWAL
+}
+
+// Write buffers record and waits until it's synced
+func (gw *groupCommitWAL) Write(cmd Command, key []byte, data []byte) error {
+	if err := gw.WriteBatch([]*Record{{
+		Cmd:   cmd,
+		Key:   key,
+		Value: data,
+	}}); err != nil {
+		return err
+	}
+	return gw.Sync()
+}
+
+// WriteBatch only buffers records, caller must Sync them. Batches are written under storage locks,
+// so waiting for fsync there would block other writers
+func (gw *groupCommitWAL) WriteBatch(records []*Record) error {
+	return gw.intervalWAL.WriteBatch(records)
 }
@@ -0,0 +1,265 @@
+package wal
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newTestWAL(t *testing.T, opts Options) (*intervalWAL, string) {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	file := filepath.Join(dir, "test.wal")
+	w, err := NewIntervalWAL(file, opts, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.(*intervalWAL), file
+}
+
+// countRecords returns amount of records in log file
+func countRecords(t *testing.T, file string) int {
+	f, err := os.Open(segmentFile(file, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(headerSize, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for {
+		r := &Record{}
+		if _, err := r.ReadFrom(f); err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			return count
+		}
+		count++
+	}
+}
+
+func waitRecords(t *testing.T, file string, expected int) {
+	for i := 0; i < 100; i++ {
+		if countRecords(t, file) == expected {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d records, got %d", expected, countRecords(t, file))
+}
+
+func TestIntervalWAL_FlushesOnEveryInterval(t *testing.T) {
+	w, file := newTestWAL(t, Options{Interval: 10 * time.Millisecond})
+	defer w.Close()
+	for i := 1; i <= 3; i++ {
+		if err := w.Write(Write, []byte("key"), []byte("value")); err != nil {
+			t.Fatal(err)
+		}
+		waitRecords(t, file, i)
+	}
+}
+
+func TestIntervalWAL_FlushesFullBatch(t *testing.T) {
+	w, file := newTestWAL(t, Options{Interval: time.Hour, BatchSize: 2})
+	defer w.Close()
+	w.Write(Write, []byte("a"), nil)
+	time.Sleep(20 * time.Millisecond)
+	if n := countRecords(t, file); n != 0 {
+		t.Fatalf("batch isn't full, but %d records were flushed", n)
+	}
+	w.Write(Write, []byte("b"), nil)
+	waitRecords(t, file, 2)
+}
+
+func TestIntervalWAL_SyncAndClose(t *testing.T) {
+	w, file := newTestWAL(t, Options{Interval: time.Hour})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Write(Write, []byte("key"), nil)
+			if err := w.Sync(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := countRecords(t, file); n != 10 {
+		t.Fatalf("expected 10 durable records, got %d", n)
+	}
+
+	w.Write(Delete, []byte("key"), nil)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRecords(t, file); n != 11 {
+		t.Fatalf("buffered record wasn't flushed on close, got %d records", n)
+	}
+	if err := w.Write(Write, []byte("key"), nil); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+// blockingWriter blocks writes until release is closed, it fails writes when err is set
+type blockingWriter struct {
+	release chan struct{}
+	writes  chan struct{}
+	err     error
+}
+
+func (bw *blockingWriter) Write(p []byte) (int, error) {
+	bw.writes <- struct{}{}
+	<-bw.release
+	if bw.err != nil {
+		return 0, bw.err
+	}
+	return len(p), nil
+}
+
+func (bw *blockingWriter) Sync() error  { return nil }
+func (bw *blockingWriter) Close() error { return nil }
+
+func newBlockingWAL(opts Options, bw *blockingWriter) *intervalWAL {
+	w := &intervalWAL{
+		logger:   zap.NewNop(),
+		opts:     opts,
+		w:        bw,
+		lsn:      &lsnCounter{},
+		flushNow: make(chan struct{}, 1),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	go w.monitor()
+	return w
+}
+
+func TestIntervalWAL_Backpressure(t *testing.T) {
+	bw := &blockingWriter{release: make(chan struct{}), writes: make(chan struct{}, 10)}
+	w := newBlockingWAL(Options{Interval: time.Hour, MaxBuffered: 2}, bw)
+
+	w.Write(Write, []byte("a"), nil)
+	w.Write(Write, []byte("b"), nil)
+	// third record waits for flush which is blocked by writer
+	written := make(chan struct{})
+	go func() {
+		w.Write(Write, []byte("c"), nil)
+		w.Write(Write, []byte("d"), nil)
+		w.Write(Write, []byte("e"), nil)
+		close(written)
+	}()
+	<-bw.writes
+	select {
+	case <-written:
+		t.Fatal("writer wasn't blocked by full buffer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(bw.release)
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("writer wasn't released after flush")
+	}
+	w.Close()
+}
+
+func TestIntervalWAL_FlushError(t *testing.T) {
+	bw := &blockingWriter{release: make(chan struct{}), writes: make(chan struct{}, 10), err: errors.New("disk failed")}
+	close(bw.release)
+	reported := make(chan error, 1)
+	w := newBlockingWAL(Options{Interval: time.Hour, OnError: func(err error) { reported <- err }}, bw)
+
+	w.Write(Write, []byte("a"), nil)
+	if err := w.Sync(); err != bw.err {
+		t.Fatalf("expected flush error, got %v", err)
+	}
+	if err := <-reported; err != bw.err {
+		t.Fatalf("expected reported flush error, got %v", err)
+	}
+	if err := w.Write(Write, []byte("b"), nil); err != bw.err {
+		t.Fatalf("failed wal accepted record, got %v", err)
+	}
+	w.Close()
+}
+
+func TestGroupCommitWAL_WriteIsDurable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "test.wal")
+	w, err := NewGroupCommitWAL(file, Options{Interval: time.Hour}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.Write(Write, []byte("key"), nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := countRecords(t, file); n != 10 {
+		t.Fatalf("expected 10 durable records, got %d", n)
+	}
+}
+
+func TestGroupCommitWAL_WriteBatchIsSyncedBySync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "test.wal")
+	w, err := NewGroupCommitWAL(file, Options{Interval: time.Hour}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	if err := w.(Batcher).WriteBatch([]*Record{{Cmd: Write, Key: []byte("a")}, {Cmd: Write, Key: []byte("b")}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRecords(t, file); n != 0 {
+		t.Fatalf("batch was synced before Sync, got %d records", n)
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRecords(t, file); n != 2 {
+		t.Fatalf("expected 2 durable records, got %d", n)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, mode := range []Mode{ModeNone, ModeInterval, ModeFsync, ModeGroupCommit} {
+		parsed, err := ParseMode(mode.String())
+		if err != nil || parsed != mode {
+			t.Fatalf("%s parsed as %s: %v", mode, parsed, err)
+		}
+	}
+	if _, err := ParseMode("sometimes"); err == nil {
+		t.Fatal("unknown mode was parsed")
+	}
+}
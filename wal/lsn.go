@@ -0,0 +1,38 @@
+package wal
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// lsnCounter assigns log sequence numbers, it's shared by segmented log and WAL buffering records for it.
+// Callers serialize assign with appending records, so LSNs grow in order of records in log
+type lsnCounter struct {
+	lsn uint64
+}
+
+// assign sets LSN and, if it isn't set, time of records
+func (c *lsnCounter) assign(records []*Record) {
+	now := time.Now().UnixNano()
+	for _, r := range records {
+		r.LSN = atomic.AddUint64(&c.lsn, 1)
+		if r.Time == 0 {
+			r.Time = now
+		}
+	}
+}
+
+// last returns the last assigned LSN
+func (c *lsnCounter) last() uint64 {
+	return atomic.LoadUint64(&c.lsn)
+}
+
+// advance makes LSNs assigned later greater than lsn
+func (c *lsnCounter) advance(lsn uint64) {
+	for {
+		last := c.last()
+		if lsn <= last || atomic.CompareAndSwapUint64(&c.lsn, last, lsn) {
+			return
+		}
+	}
+}
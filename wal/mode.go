@@ -0,0 +1,63 @@
+package wal
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Mode defines when written records become durable
+type Mode int
+
+const (
+	// ModeNone disables log, nothing is persisted and log file isn't replayed
+	ModeNone Mode = iota
+	// ModeInterval buffers records and syncs them on interval or when batch is full, write returns before sync
+	ModeInterval
+	// ModeFsync writes and syncs every record before write returns
+	ModeFsync
+	// ModeGroupCommit buffers records of concurrent writers and syncs them together, write returns after sync
+	ModeGroupCommit
+)
+
+var modeNames = map[Mode]string{
+	ModeNone:        "none",
+	ModeInterval:    "interval",
+	ModeFsync:       "fsync",
+	ModeGroupCommit: "group-commit",
+}
+
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Synchronous returns true if write returns only after record is durable
+func (m Mode) Synchronous() bool {
+	return m == ModeFsync || m == ModeGroupCommit
+}
+
+// ParseMode returns mode by its name: none, interval, fsync or group-commit
+func ParseMode(name string) (Mode, error) {
+	for m, n := range modeNames {
+		if n == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown durability mode %q", name)
+}
+
+// New opens log file in given mode, its records are read by Replay
+func New(file string, mode Mode, opts Options, logger *zap.Logger) (WAL, error) {
+	switch mode {
+	case ModeInterval:
+		return NewIntervalWAL(file, opts, logger)
+	case ModeFsync:
+		return NewFsyncWAL(file, opts, logger)
+	case ModeGroupCommit:
+		return NewGroupCommitWAL(file, opts, logger)
+	}
+	return NoopWAL{}, nil
+}
@@ -0,0 +1,150 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Target - point of recovery, zero fields don't limit it
+type Target struct {
+	// LSN - LSN of the last replayed record
+	LSN uint64
+	// Time - records written after it aren't replayed
+	Time time.Time
+}
+
+func (t Target) String() string {
+	if t.Time.IsZero() {
+		return fmt.Sprintf("lsn %d", t.LSN)
+	}
+	if t.LSN == 0 {
+		return t.Time.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("lsn %d or %s", t.LSN, t.Time.Format(time.RFC3339Nano))
+}
+
+// after returns true if record is written after target
+func (t Target) after(record *Record) bool {
+	return (t.LSN > 0 && record.LSN > t.LSN) || (!t.Time.IsZero() && record.Time > t.Time.UnixNano())
+}
+
+// before returns true if target is before point
+func (t Target) before(p Point) bool {
+	return (t.LSN > 0 && t.LSN < p.LSN) || (!t.Time.IsZero() && t.Time.Before(p.Time))
+}
+
+// Point - LSN and time of record
+type Point struct {
+	LSN  uint64
+	Time time.Time
+}
+
+// ReplayTo replays records of log written before target, starting from segment with sequence number from,
+// and returns the last replayed record. Log isn't changed, so it can be read while server writes it.
+// Dumps (snapshot replay continues and compacted segments) are fuzzy: they contain effects of records written
+// up to their end, floor is end of snapshot. Target before end of dump can't be reached and error is returned.
+// Encrypted records are decrypted with c
+func ReplayTo(file string, from uint64, floor Point, target Target, c Cipher, cb func(record *Record)) (Point, error) {
+	var point Point
+	if target.before(floor) {
+		return point, unreachable(target, floor)
+	}
+	files, err := logFiles(file, from)
+	if err != nil {
+		return point, err
+	}
+
+	var reached bool
+	for i, name := range files {
+		var targetErr error
+		apply := func(record *Record) {
+			if record, targetErr = record.Decrypt(c); targetErr == nil {
+				cb(record)
+			}
+		}
+		err := readLog(name, i == len(files)-1, func(record *Record, h logHeader) {
+			switch {
+			case reached || targetErr != nil:
+			case record.LSN == 0:
+				// record of compacted segment, header has end of dump
+				if h.lsn > floor.LSN {
+					floor.LSN = h.lsn
+				}
+				if end := time.Unix(0, h.time); h.time != 0 && end.After(floor.Time) {
+					floor.Time = end
+				}
+				if target.before(floor) {
+					targetErr = unreachable(target, floor)
+					return
+				}
+				apply(record)
+			case target.after(record):
+				reached = true
+				if record.LSN <= floor.LSN {
+					targetErr = unreachable(target, floor)
+				}
+			default:
+				apply(record)
+				point = Point{LSN: record.LSN, Time: time.Unix(0, record.Time)}
+			}
+		})
+		if err == nil {
+			err = targetErr
+		}
+		if err != nil || reached {
+			return point, err
+		}
+	}
+	return point, nil
+}
+
+func unreachable(target Target, floor Point) error {
+	return fmt.Errorf("recovery target %s is before the end of dump at lsn %d (%s), recover from older snapshot",
+		target, floor.LSN, floor.Time.Format(time.RFC3339Nano))
+}
+
+// logFiles returns segments of log starting from sequence number from, or log file written by older version
+func logFiles(file string, from uint64) ([]string, error) {
+	seqs, err := listSegments(file, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(seqs) == 0 {
+		if _, err := os.Stat(file); err == nil {
+			return []string{file}, nil
+		}
+	}
+	var files []string
+	for _, seq := range seqs {
+		if seq >= from {
+			files = append(files, segmentFile(file, seq))
+		}
+	}
+	return files, nil
+}
+
+// readLog reads records of log of any format version without changing it, cb gets header of log with
+// every record. Corrupted tail of the last log is left by interrupted or running write, so it's ignored
+func readLog(file string, last bool, cb func(record *Record, h logHeader)) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	h, complete, err := readHeader(f)
+	if err != nil || !complete {
+		return err
+	}
+	_, corruption := replayRecords(f, recordsOffset(h.version), info.Size(), h.version > 0, func(record *Record) {
+		cb(record, h)
+	})
+	if corruption != nil && !(last && corruption.Tail) {
+		return corruption
+	}
+	return nil
+}
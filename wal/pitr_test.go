@@ -0,0 +1,124 @@
+package wal
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func replayTo(t *testing.T, file string, from, floor uint64, target Target) ([]string, Point, error) {
+	var keys []string
+	point, err := ReplayTo(file, from, Point{LSN: floor}, target, nil, func(r *Record) {
+		keys = append(keys, string(r.Key))
+	})
+	return keys, point, err
+}
+
+func TestReplayTo_LSN(t *testing.T) {
+	file := writeLog(t, "a", "b", "c", "d")
+	keys, point, err := replayTo(t, file, 0, 0, Target{LSN: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a", "b")
+	if point.LSN != 2 {
+		t.Fatalf("unexpected point %+v", point)
+	}
+
+	// snapshot includes records up to its LSN
+	if _, _, err := replayTo(t, file, 0, 3, Target{LSN: 2}); err == nil {
+		t.Fatal("target before snapshot was reached")
+	}
+}
+
+func TestReplayTo_Time(t *testing.T) {
+	file := tempLog(t)
+	w, err := NewFsyncWAL(file, Options{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("a"), nil)
+	time.Sleep(10 * time.Millisecond)
+	target := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	w.Write(Write, []byte("b"), nil)
+	w.Close()
+
+	keys, point, err := replayTo(t, file, 0, 0, Target{Time: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a")
+	if point.LSN != 1 || point.Time.After(target) {
+		t.Fatalf("unexpected point %+v", point)
+	}
+}
+
+func TestReplayTo_Compacted(t *testing.T) {
+	file := tempLog(t)
+	opts := Options{}
+	opts.Dump = func(write func(record *Record) error) error {
+		return write(&Record{Cmd: Write, Key: []byte("dump")})
+	}
+	w, err := NewFsyncWAL(file, opts, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("a"), nil)
+	w.Write(Write, []byte("b"), nil)
+	if err := w.(Compactor).Compact(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("c"), nil)
+	w.Close()
+
+	// records before compaction are replaced by dump
+	if _, _, err := replayTo(t, file, 0, 0, Target{LSN: 1}); err == nil {
+		t.Fatal("target before compaction was reached")
+	}
+	keys, _, err := replayTo(t, file, 0, 0, Target{LSN: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "dump")
+}
+
+func TestLSN_Persisted(t *testing.T) {
+	file := tempLog(t)
+	w, err := NewFsyncWAL(file, Options{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("a"), nil)
+	w.Write(Write, []byte("b"), nil)
+	// all records are truncated, new segment keeps LSN in header
+	seq, err := w.(Checkpointer).Checkpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.(Checkpointer).Truncate(seq); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	var lsns []uint64
+	w, err = NewFsyncWAL(file, Options{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	err = replayWAL(w, func(r *Record) {
+		lsns = append(lsns, r.LSN)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lsns) != 0 || w.LastLSN() != 2 {
+		t.Fatalf("unexpected lsn %d after replay of %v", w.LastLSN(), lsns)
+	}
+	w.Write(Write, []byte("c"), nil)
+	if lsn := w.LastLSN(); lsn != 3 {
+		t.Fatalf("unexpected lsn %d of new record", lsn)
+	}
+}
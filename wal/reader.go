@@ -0,0 +1,223 @@
+package wal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// segmentReader reads records of segmented log. It reads only part of segment which size is known to log,
+// so records which are being written aren't read. Segment deleted by snapshot or replaced by compaction
+// stays open until it's read to the end, it isn't changed after it stops being active
+type segmentReader struct {
+	l *segmentedLog
+	// follow is set for subscriber, it waits for new records and can't read dump
+	follow bool
+	// from - records with smaller LSN are skipped by subscriber
+	from uint64
+
+	// mu is held by Next, Close takes it to close file
+	mu  sync.Mutex
+	seq uint64
+	f   *os.File
+	r   *bufio.Reader
+	// compacted - segment was written by compaction when it was opened
+	compacted bool
+	// final is set when segment isn't changed anymore and limit is its size
+	final  bool
+	offset int64
+	limit  int64
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Replay returns iterator over all records of log
+func (l *segmentedLog) Replay() (Iterator, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, ErrClosed
+	}
+	sr := &segmentReader{l: l, done: make(chan struct{})}
+	if err := sr.open(0); err != nil {
+		return nil, err
+	}
+	return sr, nil
+}
+
+// Subscribe returns iterator over records with LSN from and greater, see WAL
+func (l *segmentedLog) Subscribe(from uint64) (Iterator, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, ErrClosed
+	}
+	if from == 0 {
+		from = 1
+	}
+	if last := l.lsn.last(); from > last+1 {
+		return nil, fmt.Errorf("lsn %d is ahead of wal, the last lsn is %d", from, last)
+	}
+	// records of older segments have LSNs not greater than base LSN of the next one
+	i := -1
+	for j, s := range l.segments {
+		if s.lsn < from {
+			i = j
+		}
+	}
+	if i < 0 || l.segments[i].compacted {
+		return nil, ErrUnavailable
+	}
+	sr := &segmentReader{l: l, follow: true, from: from, done: make(chan struct{})}
+	if err := sr.open(i); err != nil {
+		return nil, err
+	}
+	return sr, nil
+}
+
+// open opens segment with index i in segments of log, mu of log must be held
+func (sr *segmentReader) open(i int) error {
+	s := sr.l.segments[i]
+	f, err := os.Open(segmentFile(sr.l.base, s.seq))
+	if err != nil {
+		return err
+	}
+	if sr.f != nil {
+		sr.f.Close()
+	}
+	sr.seq, sr.f, sr.compacted, sr.final = s.seq, f, s.compacted, false
+	sr.offset, sr.limit = headerSize, s.size
+	sr.r = bufio.NewReader(io.NewSectionReader(f, sr.offset, sr.limit-sr.offset))
+	return nil
+}
+
+// Next returns the next record, see Iterator
+func (sr *segmentReader) Next(ctx context.Context) (*Record, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	for {
+		select {
+		case <-sr.done:
+			return nil, ErrClosed
+		default:
+		}
+		if sr.offset >= sr.limit {
+			if err := sr.advance(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		record := &Record{}
+		n, err := record.read(sr.r, sr.limit-sr.offset, true)
+		if err != nil {
+			if err == io.EOF {
+				err = errTorn
+			}
+			return nil, &CorruptionError{File: sr.f.Name(), Offset: sr.offset, Reason: err}
+		}
+		sr.offset += n
+		if !sr.follow {
+			return record.Decrypt(sr.l.opts.Cipher)
+		}
+		if record.LSN == 0 {
+			// dump doesn't contain separate records
+			return nil, ErrUnavailable
+		}
+		if record.LSN >= sr.from {
+			return record.Decrypt(sr.l.opts.Cipher)
+		}
+	}
+}
+
+// advance extends readable part of segment, opens the next segment or waits for new records
+func (sr *segmentReader) advance(ctx context.Context) error {
+	l := sr.l
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	i := -1
+	for j, s := range l.segments {
+		if s.seq == sr.seq {
+			i = j
+		}
+	}
+	if !sr.final {
+		switch {
+		case i < 0 || l.segments[i].compacted != sr.compacted:
+			// segment was deleted or replaced while it was open, it's read to the end of file
+			l.mu.Unlock()
+			info, err := sr.f.Stat()
+			if err != nil {
+				return err
+			}
+			sr.final = true
+			sr.extend(info.Size())
+			return nil
+		case l.segments[i].size > sr.offset:
+			sr.extend(l.segments[i].size)
+			l.mu.Unlock()
+			return nil
+		case i < len(l.segments)-1:
+			sr.final = true
+		case !sr.follow:
+			l.mu.Unlock()
+			return io.EOF
+		default:
+			changed := l.changed
+			l.mu.Unlock()
+			sr.mu.Unlock()
+			defer sr.mu.Lock()
+			select {
+			case <-changed:
+				return nil
+			case <-sr.done:
+				return ErrClosed
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	defer l.mu.Unlock()
+	next := -1
+	for j := len(l.segments) - 1; j >= 0 && l.segments[j].seq > sr.seq; j-- {
+		next = j
+	}
+	if next < 0 {
+		return ErrUnavailable
+	}
+	if sr.follow && (l.segments[next].seq != sr.seq+1 || l.segments[next].compacted) {
+		// records between segments were deleted
+		return ErrUnavailable
+	}
+	return sr.open(next)
+}
+
+// extend makes segment readable up to size
+func (sr *segmentReader) extend(size int64) {
+	if size > sr.limit {
+		sr.limit = size
+	}
+	sr.r = bufio.NewReader(io.NewSectionReader(sr.f, sr.offset, sr.limit-sr.offset))
+}
+
+// Close stops iterator, it can be called concurrently with Next which waits for records
+func (sr *segmentReader) Close() error {
+	sr.closeOnce.Do(func() {
+		close(sr.done)
+	})
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if sr.f == nil {
+		return nil
+	}
+	err := sr.f.Close()
+	sr.f = nil
+	return err
+}
@@ -0,0 +1,132 @@
+package wal
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func openTestLog(t *testing.T, opts Options) WAL {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	w, err := NewFsyncWAL(filepath.Join(dir, "test.wal"), opts, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func nextKey(t *testing.T, it Iterator) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	r, err := it.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(r.Key)
+}
+
+func TestSubscribe_Tail(t *testing.T) {
+	w := openTestLog(t, Options{SegmentSize: 100})
+	for _, key := range []string{"a", "b", "c"} {
+		w.Write(Write, []byte(key), []byte("value"))
+	}
+	it, err := w.Subscribe(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	if key := nextKey(t, it); key != "b" {
+		t.Fatalf("expected b, got %s", key)
+	}
+	if key := nextKey(t, it); key != "c" {
+		t.Fatalf("expected c, got %s", key)
+	}
+
+	// records written later are read from new segments
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		for _, key := range []string{"d", "e", "f", "g"} {
+			w.Write(Write, []byte(key), []byte("value"))
+		}
+	}()
+	for _, expected := range []string{"d", "e", "f", "g"} {
+		if key := nextKey(t, it); key != expected {
+			t.Fatalf("expected %s, got %s", expected, key)
+		}
+	}
+}
+
+func TestSubscribe_Cancel(t *testing.T) {
+	w := openTestLog(t, Options{})
+	it, err := w.Subscribe(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := it.Next(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+
+	// close stops waiting Next
+	errs := make(chan error)
+	go func() {
+		_, err := it.Next(context.Background())
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	it.Close()
+	if err := <-errs; err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestSubscribe_Unavailable(t *testing.T) {
+	w := openTestLog(t, Options{})
+	w.Write(Write, []byte("a"), nil)
+	w.Write(Write, []byte("b"), nil)
+	if _, err := w.Subscribe(10); err == nil {
+		t.Fatal("lsn ahead of log is subscribed")
+	}
+
+	it, err := w.Subscribe(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	seq, err := w.(Checkpointer).Checkpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.(Checkpointer).Truncate(seq); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Subscribe(1); err != ErrUnavailable {
+		t.Fatalf("expected ErrUnavailable, got %v", err)
+	}
+	// open segment is read to the end after it's deleted
+	if key := nextKey(t, it); key != "a" {
+		t.Fatalf("expected a, got %s", key)
+	}
+
+	// records after truncation are available
+	w.Write(Write, []byte("c"), nil)
+	it, err = w.Subscribe(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	if key := nextKey(t, it); key != "c" {
+		t.Fatalf("expected c, got %s", key)
+	}
+}
@@ -0,0 +1,363 @@
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// log file starts with header: 4 byte magic, 4 byte format version, 8 byte base LSN and 8 byte base time,
+// records of segment have greater LSNs and are written later. Files without header are legacy logs with records
+// without CRC, files of version 1 have no base LSN, both are upgraded on open
+const (
+	magic      = "GWAL"
+	version    = uint32(2)
+	headerSize = int64(len(magic) + 20)
+	// v1HeaderSize - size of header of version 1
+	v1HeaderSize = int64(len(magic) + 4)
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+const (
+	// recordScanWindow - how far after record with damaged length the next valid record is searched
+	recordScanWindow = 1 << 20
+	// recordScanLimit - max size of data read while the next valid record is searched
+	recordScanLimit = 16 << 20
+	// recordScanBudget - max total size of candidate records checked while the next valid record is searched
+	recordScanBudget = 64 << 20
+)
+
+var (
+	// errTorn - record is cut off by end of log
+	errTorn = errors.New("record is incomplete")
+	// errChecksum - record is damaged
+	errChecksum = errors.New("record checksum mismatch")
+	// errLength - record is longer than rest of log, it's cut off or its length is damaged
+	errLength = errors.New("record length exceeds log")
+)
+
+// CorruptionError describes corrupted record of log
+type CorruptionError struct {
+	File string
+	// Offset - position of the first corrupted record, log is valid before it
+	Offset int64
+	// Tail is true if nothing but corrupted record or zeroes follow offset, it's left by interrupted write
+	Tail   bool
+	Reason error
+}
+
+func (e *CorruptionError) Error() string {
+	place := "middle"
+	if e.Tail {
+		place = "tail"
+	}
+	return fmt.Sprintf("wal %s is corrupted at offset %d (%s of log): %v", e.File, e.Offset, place, e.Reason)
+}
+
+// openSegment opens segment file, replays its records with cb and returns file positioned after the last
+// valid record, its size and base LSN. Corrupted tail of the last segment is truncated and reported, other
+// corruption is error in strict mode, otherwise segment is truncated at it as well and truncated is true
+func openSegment(file string, last, strict bool, logger *zap.Logger, cb func(record *Record)) (f *os.File, size int64, base uint64, truncated bool, err error) {
+	f, err = os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, 0, 0, false, err
+	}
+	size, base, corruption, err := replayLog(f, cb)
+	if upgrade, ok := err.(errUpgrade); ok {
+		f.Close()
+		f, err = upgradeLog(file, upgrade.version, logger, cb)
+		if err != nil {
+			return nil, 0, 0, false, err
+		}
+	}
+	if err == nil && corruption != nil {
+		if !last {
+			// segment was synced before the next one was created, so it can't have torn tail
+			corruption.Tail = false
+		}
+		if !corruption.Tail && strict {
+			err = corruption
+		} else {
+			truncated = !corruption.Tail
+			err = truncate(f, corruption, logger)
+		}
+	}
+	if err == nil {
+		size, err = f.Seek(0, io.SeekEnd)
+	}
+	if err != nil {
+		f.Close()
+		return nil, 0, 0, false, err
+	}
+	return f, size, base, truncated, nil
+}
+
+// errUpgrade is returned for log of older format
+type errUpgrade struct {
+	version uint32
+}
+
+func (e errUpgrade) Error() string {
+	return fmt.Sprintf("wal of format version %d", e.version)
+}
+
+// logHeader - header of log
+type logHeader struct {
+	version uint32
+	// lsn and time - LSN and time of the last record written before log was started, or of the end of dump
+	// for logs written by compaction
+	lsn  uint64
+	time int64
+}
+
+// newHeader returns header of log started now
+func newHeader(lsn uint64) logHeader {
+	return logHeader{version: version, lsn: lsn, time: time.Now().UnixNano()}
+}
+
+func (h logHeader) bytes() []byte {
+	b := make([]byte, headerSize)
+	copy(b, magic)
+	binary.BigEndian.PutUint32(b[len(magic):], h.version)
+	binary.BigEndian.PutUint64(b[v1HeaderSize:], h.lsn)
+	binary.BigEndian.PutUint64(b[v1HeaderSize+8:], uint64(h.time))
+	return b
+}
+
+// readHeader reads header of log, legacy log has version 0. Complete is false if log is empty or its header is cut off
+func readHeader(f *os.File) (h logHeader, complete bool, err error) {
+	b := make([]byte, headerSize)
+	n, err := f.ReadAt(b, 0)
+	if err != nil && err != io.EOF {
+		return h, false, err
+	}
+	if n < len(magic) {
+		// records of legacy log start with zero bytes of key length
+		return h, false, nil
+	}
+	if string(b[:len(magic)]) != magic {
+		return h, true, nil
+	}
+	if int64(n) < v1HeaderSize {
+		return h, false, nil
+	}
+	h.version = binary.BigEndian.Uint32(b[len(magic):])
+	switch {
+	case h.version == 1:
+		return h, true, nil
+	case h.version != version:
+		return h, false, fmt.Errorf("wal %s has unsupported format version %d", f.Name(), h.version)
+	case int64(n) < headerSize:
+		return h, false, nil
+	}
+	h.lsn = binary.BigEndian.Uint64(b[v1HeaderSize:])
+	h.time = int64(binary.BigEndian.Uint64(b[v1HeaderSize+8:]))
+	return h, true, nil
+}
+
+// recordsOffset returns offset of the first record in log of given format version
+func recordsOffset(v uint32) int64 {
+	switch v {
+	case 0:
+		return 0
+	case 1:
+		return v1HeaderSize
+	}
+	return headerSize
+}
+
+// replayLog checks header and replays records, it returns size of valid part of log, base LSN of log
+// and corruption found after it
+func replayLog(f *os.File, cb func(record *Record)) (int64, uint64, *CorruptionError, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	size := info.Size()
+	h, complete, err := readHeader(f)
+	switch {
+	case err != nil:
+		return 0, 0, nil, err
+	case !complete:
+		// empty log or log which header wasn't written completely
+		return headerSize, 0, nil, writeHeader(f, logHeader{version: version})
+	case h.version != version:
+		return 0, 0, nil, errUpgrade{version: h.version}
+	}
+
+	offset, corruption := replayRecords(f, headerSize, size, true, cb)
+	return offset, h.lsn, corruption, nil
+}
+
+// truncate drops corrupted part of log
+func truncate(f *os.File, corruption *CorruptionError, logger *zap.Logger) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	fields := []zap.Field{zap.String("file", f.Name()), zap.Int64("offset", corruption.Offset),
+		zap.Int64("dropped", info.Size()-corruption.Offset), zap.Error(corruption.Reason)}
+	if corruption.Tail {
+		logger.Warn("wal tail is corrupted, truncating", fields...)
+	} else {
+		logger.Error("wal is corrupted, truncating", fields...)
+	}
+	if err := f.Truncate(corruption.Offset); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// replayRecords replays records of log between offset and size, it returns offset after the last valid record
+// and corruption found after it
+func replayRecords(f *os.File, offset, size int64, checksum bool, cb func(record *Record)) (int64, *CorruptionError) {
+	r := bufio.NewReader(io.NewSectionReader(f, offset, size-offset))
+	for offset < size {
+		record := &Record{}
+		n, err := record.read(r, size-offset, checksum)
+		if err != nil {
+			if err == io.EOF {
+				err = errTorn
+			}
+			tail := err == errTorn || onlyZeroes(f, offset+n, size)
+			if err == errLength {
+				// record is cut off by interrupted write or its length is damaged, valid records follow it in the latter case
+				tail = !checksum || !validRecordAfter(f, offset+1, size)
+			}
+			return offset, &CorruptionError{
+				File:   f.Name(),
+				Offset: offset,
+				Tail:   tail,
+				Reason: err,
+			}
+		}
+		if cb != nil {
+			cb(record)
+		}
+		offset += n
+	}
+	return offset, nil
+}
+
+// validRecordAfter returns true if any record with valid checksum starts between offset and size. Scan is bounded,
+// so damaged log isn't read quadratically: only records which start in recordScanWindow after offset and end
+// in recordScanLimit are found and search stops when candidates take more than recordScanBudget to check
+func validRecordAfter(f *os.File, offset, size int64) bool {
+	if offset >= size {
+		return false
+	}
+	data := make([]byte, min64(size-offset, recordScanLimit))
+	if _, err := f.ReadAt(data, offset); err != nil {
+		return false
+	}
+	checked := int64(0)
+	for i := 0; i < len(data) && i < recordScanWindow; i++ {
+		record := &Record{}
+		n, err := record.read(bytes.NewReader(data[i:]), int64(len(data)-i), true)
+		if err == nil {
+			return true
+		}
+		if checked += n; checked > recordScanBudget {
+			return false
+		}
+	}
+	return false
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// onlyZeroes returns true if log has only zero bytes between offset and size,
+// file systems can leave them after crash in space allocated for interrupted write
+func onlyZeroes(f *os.File, offset, size int64) bool {
+	r := bufio.NewReader(io.NewSectionReader(f, offset, size-offset))
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err == io.EOF
+		}
+		if b != 0 {
+			return false
+		}
+	}
+}
+
+// writeHeader replaces content of file with header and leaves file positioned after it
+func writeHeader(f *os.File, h logHeader) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt(h.bytes(), 0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(headerSize, io.SeekStart); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// upgradeLog replays log of older format version and rewrites its records in current format
+func upgradeLog(file string, v uint32, logger *zap.Logger, cb func(record *Record)) (*os.File, error) {
+	old, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer old.Close()
+	info, err := old.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.OpenFile(file+".upgrade", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeHeader(tmp, logHeader{version: version}); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	w := bufio.NewWriter(tmp)
+	var writeErr error
+	// legacy records have no checksum
+	_, corruption := replayRecords(old, recordsOffset(v), info.Size(), v > 0, func(record *Record) {
+		if writeErr == nil {
+			_, writeErr = record.WriteTo(w)
+		}
+		if cb != nil {
+			cb(record)
+		}
+	})
+	if corruption != nil {
+		logger.Warn("wal of older format is corrupted, dropping records after corruption", zap.String("file", file),
+			zap.Int64("offset", corruption.Offset), zap.Error(corruption.Reason))
+	}
+	if writeErr == nil {
+		writeErr = w.Flush()
+	}
+	if writeErr == nil {
+		writeErr = tmp.Sync()
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(tmp.Name(), file)
+	}
+	if writeErr != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, writeErr
+	}
+	logger.Info("wal is upgraded", zap.String("file", file), zap.Uint32("from", v), zap.Uint32("version", version))
+	return tmp, nil
+}
@@ -0,0 +1,304 @@
+package wal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// writeLog writes records with given keys to new log and returns its path
+func writeLog(t *testing.T, keys ...string) string {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	file := filepath.Join(dir, "test.wal")
+	w, err := NewFsyncWAL(file, Options{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if err := w.Write(Write, []byte(key), []byte("value")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// replayKeys opens log and returns keys of replayed records
+func replayKeys(file string, strict bool) ([]string, error) {
+	var keys []string
+	w, err := NewFsyncWAL(file, Options{Strict: strict}, zap.NewNop())
+	if err != nil {
+		return nil, err
+	}
+	err = replayWAL(w, func(r *Record) {
+		keys = append(keys, string(r.Key))
+	})
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return keys, w.Close()
+}
+
+// replayWAL reads all records of log with cb
+func replayWAL(w WAL, cb func(r *Record)) error {
+	it, err := w.Replay()
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for {
+		r, err := it.Next(context.Background())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		cb(r)
+	}
+}
+
+func fileSize(t *testing.T, file string) int64 {
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func assertKeys(t *testing.T, keys []string, expected ...string) {
+	if len(keys) != len(expected) {
+		t.Fatalf("expected keys %v, got %v", expected, keys)
+	}
+	for i := range keys {
+		if keys[i] != expected[i] {
+			t.Fatalf("expected keys %v, got %v", expected, keys)
+		}
+	}
+}
+
+func TestOpenLog_TornTail(t *testing.T) {
+	file := writeLog(t, "a", "b", "c")
+	segment := segmentFile(file, 1)
+	size := fileSize(t, segment)
+	if err := os.Truncate(segment, size-3); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a", "b")
+	recordSize := (size - headerSize) / 3
+	if s := fileSize(t, segment); s != headerSize+2*recordSize {
+		t.Fatalf("torn record wasn't truncated, size %d", s)
+	}
+
+	// log is writable after truncation
+	w, err := NewFsyncWAL(file, Options{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("d"), nil)
+	w.Close()
+	keys, err = replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a", "b", "d")
+}
+
+func TestOpenLog_ZeroTail(t *testing.T) {
+	file := writeLog(t, "a")
+	f, err := os.OpenFile(segmentFile(file, 1), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(make([]byte, 100))
+	f.Close()
+
+	keys, err := replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a")
+}
+
+func TestOpenLog_Corruption(t *testing.T) {
+	file := writeLog(t, "a", "b", "c")
+	segment := segmentFile(file, 1)
+	data, err := ioutil.ReadFile(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordSize := (int64(len(data)) - headerSize) / 3
+	// damage key of the second record
+	at := headerSize + recordSize + 17
+	data[at] ^= 0xff
+	if err := ioutil.WriteFile(segment, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = replayKeys(file, true)
+	ce, ok := err.(*CorruptionError)
+	if !ok {
+		t.Fatalf("expected corruption error, got %v", err)
+	}
+	if ce.Offset != headerSize+recordSize || ce.Tail || ce.Reason != errChecksum {
+		t.Fatalf("unexpected corruption %+v", ce)
+	}
+	if s := fileSize(t, segment); s != int64(len(data)) {
+		t.Fatal("strict mode changed corrupted log")
+	}
+
+	keys, err := replayKeys(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a")
+	if s := fileSize(t, segment); s != headerSize+recordSize {
+		t.Fatalf("log wasn't truncated at corruption, size %d", s)
+	}
+}
+
+func TestOpenLog_LengthCorruption(t *testing.T) {
+	file := writeLog(t, "a", "b", "c")
+	segment := segmentFile(file, 1)
+	data, err := ioutil.ReadFile(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordSize := (int64(len(data)) - headerSize) / 3
+	// damage value length of the second record, so record runs past end of log
+	at := headerSize + recordSize + 14
+	data[at] ^= 0xff
+	if err := ioutil.WriteFile(segment, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = replayKeys(file, true)
+	ce, ok := err.(*CorruptionError)
+	if !ok {
+		t.Fatalf("expected corruption error, got %v", err)
+	}
+	if ce.Offset != headerSize+recordSize || ce.Tail || ce.Reason != errLength {
+		t.Fatalf("unexpected corruption %+v", ce)
+	}
+	if s := fileSize(t, segment); s != int64(len(data)) {
+		t.Fatal("strict mode changed corrupted log")
+	}
+}
+
+func TestOpenLog_TornValue(t *testing.T) {
+	file := writeLog(t, "a", "b", "c")
+	segment := segmentFile(file, 1)
+	// value of the last record is cut off, so its length runs past end of log
+	if err := os.Truncate(segment, fileSize(t, segment)-6); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a", "b")
+}
+
+func TestOpenLog_TornLargeValue(t *testing.T) {
+	file := writeLog(t, "a", "b")
+	w, err := NewFsyncWAL(file, Options{}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every 8th byte of value starts plausible record, so checking every offset of segment is quadratic
+	value := bytes.Repeat([]byte{0, 0, 0, 0, 0, 1, 0, 0}, 8<<20)
+	if err := w.Write(Write, []byte("c"), value); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	segment := segmentFile(file, 1)
+	if err := os.Truncate(segment, fileSize(t, segment)-6); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	keys, err := replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a", "b")
+	if d := time.Since(start); d > 10*time.Second {
+		t.Fatalf("torn tail is checked too long: %v", d)
+	}
+}
+
+func TestOpenLog_UpgradeLegacy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "test.wal")
+
+	// legacy record has no crc
+	b := &bytes.Buffer{}
+	for _, key := range []string{"a", "b"} {
+		r := &Record{Cmd: Write, DB: 1, Key: []byte(key), Value: []byte("value")}
+		r.WriteTo(b)
+		b.Truncate(b.Len() - 4)
+	}
+	if err := ioutil.WriteFile(file, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a", "b")
+	// log written as one file becomes the first segment
+	data, err := ioutil.ReadFile(segmentFile(file, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, logHeader{version: version}.bytes()) {
+		t.Fatal("legacy log wasn't upgraded")
+	}
+	keys, err = replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "a", "b")
+}
+
+func TestOpenLog_UnsupportedVersion(t *testing.T) {
+	file := writeLog(t, "a")
+	data, err := ioutil.ReadFile(segmentFile(file, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[v1HeaderSize-1] = 3
+	if err := ioutil.WriteFile(segmentFile(file, 1), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replayKeys(file, false); err == nil {
+		t.Fatal("log of unsupported version was opened")
+	}
+}
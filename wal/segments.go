@@ -0,0 +1,501 @@
+package wal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// compactSuffix - suffix of segment which is being written by compaction
+const compactSuffix = ".compact"
+
+var errCompacting = errors.New("wal compaction is already running")
+
+// Compactor is implemented by WALs which can rewrite log with current dataset
+type Compactor interface {
+	// Compact writes dataset returned by Options.Dump to new segment and deletes segments it supersedes
+	Compact() error
+}
+
+// Checkpointer is implemented by WALs which can drop records included into snapshot
+// and read records written after checkpoint
+type Checkpointer interface {
+	// Checkpoint starts new segment and returns its sequence number, records written before it are in older segments
+	Checkpoint() (uint64, error)
+	// Truncate deletes segments before seq
+	Truncate(seq uint64) error
+	// Hold keeps segments from being deleted or replaced by compaction until release is called
+	Hold() (release func())
+	// ReadSegments reads records of closed segments with sequence numbers in [from, to), they must be held
+	ReadSegments(from, to uint64, cb func(record *Record)) error
+}
+
+// segmentFile returns path of segment with given sequence number, segments are named <log>.<seq>
+func segmentFile(base string, seq uint64) string {
+	return fmt.Sprintf("%s.%016d", base, seq)
+}
+
+type segment struct {
+	seq  uint64
+	size int64
+	// lsn - base LSN from header of segment
+	lsn uint64
+	// compacted is set for segment written by compaction, it has dump instead of records
+	compacted bool
+}
+
+// segmentedLog splits log into segments of limited size, records are appended to the last (active) segment.
+// Compaction rotates log, writes dataset to segment which replaces all segments before rotation
+// and deletes them, while writes go to the new active segment
+type segmentedLog struct {
+	base   string
+	opts   Options
+	logger *zap.Logger
+
+	// mu guards fields below and segment files
+	mu     sync.Mutex
+	active *os.File
+	// segments - all segments including active one, ordered by seq
+	segments []segment
+	// total - size of all segments, compacted - their size after the last compaction
+	total      int64
+	compacted  int64
+	compacting bool
+	closed     bool
+	// holds - amount of readers which need current segments, segments aren't deleted while they are held
+	holds int
+	// lsn assigns LSNs of records written to log, it's shared with WAL buffering records
+	lsn *lsnCounter
+	// changed is closed and replaced when segments change, readers wait for it
+	changed chan struct{}
+	// compactions waits for running compaction
+	compactions sync.WaitGroup
+}
+
+// openSegmentedLog checks segments of log and opens the last one for writes, records are read by Replay.
+// Log written as one file by older versions becomes the first segment
+func openSegmentedLog(base string, opts Options, logger *zap.Logger) (*segmentedLog, error) {
+	seqs, err := listSegments(base, true)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(base); err == nil && info.Mode().IsRegular() {
+		if len(seqs) > 0 {
+			return nil, fmt.Errorf("wal %s exists together with its segments", base)
+		}
+		if err := os.Rename(base, segmentFile(base, 1)); err != nil {
+			return nil, err
+		}
+		seqs = []uint64{1}
+	}
+	for len(seqs) > 0 && seqs[0] < opts.ReplayFrom {
+		// segment is included into snapshot, but wasn't deleted after it
+		if err := os.Remove(segmentFile(base, seqs[0])); err != nil {
+			return nil, err
+		}
+		seqs = seqs[1:]
+	}
+	if len(seqs) == 0 {
+		seqs = []uint64{1}
+		if opts.ReplayFrom > 1 {
+			seqs[0] = opts.ReplayFrom
+		}
+	}
+
+	l := &segmentedLog{base: base, opts: opts, logger: logger, lsn: &lsnCounter{}, changed: make(chan struct{})}
+	l.lsn.advance(opts.StartLSN)
+	// records of dumps and logs of older versions have no LSN
+	dump := false
+	check := func(record *Record) {
+		l.lsn.advance(record.LSN)
+		dump = dump || record.LSN == 0
+	}
+	for i, seq := range seqs {
+		last := i == len(seqs)-1
+		dump = false
+		f, size, lsn, truncated, err := openSegment(segmentFile(base, seq), last, opts.Strict, logger, check)
+		if err != nil {
+			return nil, err
+		}
+		l.lsn.advance(lsn)
+		l.segments = append(l.segments, segment{seq: seq, size: size, lsn: lsn, compacted: dump})
+		l.total += size
+		if truncated && !last {
+			// records after corruption are dropped like in the rest of corrupted segment
+			for _, later := range seqs[i+1:] {
+				logger.Error("dropping wal segment after corruption", zap.String("file", segmentFile(base, later)))
+				if err := os.Remove(segmentFile(base, later)); err != nil {
+					f.Close()
+					return nil, err
+				}
+			}
+			last = true
+		}
+		if last {
+			l.active = f
+			break
+		}
+		f.Close()
+	}
+	return l, nil
+}
+
+// listSegments returns sorted sequence numbers of segments of log, unfinished compactions are removed if cleanup is set
+func listSegments(base string, cleanup bool) ([]uint64, error) {
+	files, err := filepath.Glob(base + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var seqs []uint64
+	for _, file := range files {
+		suffix := strings.TrimPrefix(file, base+".")
+		if strings.HasSuffix(suffix, compactSuffix) {
+			if !cleanup {
+				continue
+			}
+			if err := os.Remove(file); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		seq, err := strconv.ParseUint(suffix, 10, 64)
+		if err != nil || len(suffix) != 16 {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+// Write appends data to active segment, log is rotated after segment reaches max size,
+// so data of one write is never split between segments
+func (l *segmentedLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, ErrClosed
+	}
+	n, err := l.active.Write(p)
+	l.segments[len(l.segments)-1].size += int64(n)
+	l.total += int64(n)
+	l.notify()
+	if err != nil {
+		return n, err
+	}
+	if l.opts.SegmentSize > 0 && l.segments[len(l.segments)-1].size >= l.opts.SegmentSize {
+		err = l.rotate()
+	}
+	l.maybeCompact()
+	return n, err
+}
+
+func (l *segmentedLog) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return ErrClosed
+	}
+	return l.active.Sync()
+}
+
+// Close waits for running compaction, syncs and closes active segment
+func (l *segmentedLog) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	l.closed = true
+	l.notify()
+	l.mu.Unlock()
+
+	l.compactions.Wait()
+	if err := l.active.Sync(); err != nil {
+		l.active.Close()
+		return err
+	}
+	return l.active.Close()
+}
+
+// rotate syncs active segment and starts the next one, mu must be held. Base LSN of new segment is the last
+// assigned LSN, records buffered by WAL can have smaller LSNs, but they are greater than LSNs of older segments
+func (l *segmentedLog) rotate() error {
+	if err := l.active.Sync(); err != nil {
+		return err
+	}
+	seq := l.segments[len(l.segments)-1].seq + 1
+	f, err := os.OpenFile(segmentFile(l.base, seq), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	h := newHeader(l.lsn.last())
+	if err = writeHeader(f, h); err == nil {
+		err = syncDir(l.base)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	l.active.Close()
+	l.active = f
+	l.segments = append(l.segments, segment{seq: seq, size: headerSize, lsn: h.lsn})
+	l.total += headerSize
+	l.notify()
+	return nil
+}
+
+// notify wakes up readers waiting for changes of segments, mu must be held
+func (l *segmentedLog) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Checkpoint rotates log and returns sequence number of new active segment
+func (l *segmentedLog) Checkpoint() (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, ErrClosed
+	}
+	if err := l.rotate(); err != nil {
+		return 0, err
+	}
+	return l.segments[len(l.segments)-1].seq, nil
+}
+
+// Truncate deletes segments before seq, active segment is never deleted. Nothing is deleted while segments are held
+func (l *segmentedLog) Truncate(seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holds > 0 {
+		return nil
+	}
+	for len(l.segments) > 1 && l.segments[0].seq < seq {
+		if err := os.Remove(segmentFile(l.base, l.segments[0].seq)); err != nil {
+			return err
+		}
+		l.total -= l.segments[0].size
+		l.segments = l.segments[1:]
+	}
+	l.compacted = l.total
+	l.notify()
+	return nil
+}
+
+// Hold keeps segments from being deleted until release is called
+func (l *segmentedLog) Hold() func() {
+	l.mu.Lock()
+	l.holds++
+	l.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.holds--
+			l.mu.Unlock()
+		})
+	}
+}
+
+// ReadSegments reads records of closed segments with sequence numbers in [from, to)
+func (l *segmentedLog) ReadSegments(from, to uint64, cb func(record *Record)) error {
+	l.mu.Lock()
+	var segments []segment
+	for _, s := range l.segments {
+		if s.seq >= from && s.seq < to {
+			segments = append(segments, s)
+		}
+	}
+	if n := len(l.segments); n > 0 && to > l.segments[n-1].seq {
+		l.mu.Unlock()
+		return errors.New("active wal segment can't be read")
+	}
+	l.mu.Unlock()
+
+	var decryptErr error
+	decrypt := func(record *Record) {
+		if decryptErr != nil {
+			return
+		}
+		if record, decryptErr = record.Decrypt(l.opts.Cipher); decryptErr == nil {
+			cb(record)
+		}
+	}
+	for _, s := range segments {
+		f, err := os.Open(segmentFile(l.base, s.seq))
+		if err != nil {
+			return err
+		}
+		_, corruption := replayRecords(f, headerSize, s.size, true, decrypt)
+		f.Close()
+		if corruption != nil {
+			return corruption
+		}
+		if decryptErr != nil {
+			return decryptErr
+		}
+	}
+	return nil
+}
+
+// maybeCompact starts background compaction when log is larger than CompactSize
+// and twice larger than after the last compaction, mu must be held
+func (l *segmentedLog) maybeCompact() {
+	if l.opts.Dump == nil || l.opts.CompactSize <= 0 || l.compacting || l.closed || l.holds > 0 {
+		return
+	}
+	if l.total < l.opts.CompactSize || l.total < 2*l.compacted {
+		return
+	}
+	l.compacting = true
+	l.compactions.Add(1)
+	go func() {
+		defer l.compactions.Done()
+		if err := l.compact(); err != nil {
+			l.logger.Error("can't compact wal", zap.Error(err))
+		}
+	}()
+}
+
+// Compact rewrites log with dataset returned by Options.Dump
+func (l *segmentedLog) Compact() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	if l.compacting {
+		l.mu.Unlock()
+		return errCompacting
+	}
+	if l.opts.Dump == nil {
+		l.mu.Unlock()
+		return errors.New("wal dump isn't set")
+	}
+	l.compacting = true
+	l.compactions.Add(1)
+	l.mu.Unlock()
+	defer l.compactions.Done()
+	return l.compact()
+}
+
+// compact rotates log, so segments before rotation aren't changed anymore, and replaces them with dump.
+// Dump is made after rotation, so it contains effects of all records of replaced segments. Records written
+// to active segment concurrently with dump can be in it too, they are replayed after dump and lead to the same state
+func (l *segmentedLog) compact() error {
+	defer func() {
+		l.mu.Lock()
+		l.compacting = false
+		l.mu.Unlock()
+	}()
+	if l.opts.BeginDump != nil {
+		end, err := l.opts.BeginDump()
+		if err != nil {
+			return err
+		}
+		defer end()
+	}
+
+	l.mu.Lock()
+	if err := l.rotate(); err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	replaced := l.segments[len(l.segments)-2].seq
+	l.mu.Unlock()
+
+	file := segmentFile(l.base, replaced)
+	size, lsn, err := l.dump(file + compactSuffix)
+	if err != nil {
+		os.Remove(file + compactSuffix)
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.segments) == 0 || l.segments[0].seq > replaced || l.holds > 0 {
+		// replaced segments were truncated after snapshot during dump or they are held by reader
+		os.Remove(file + compactSuffix)
+		return nil
+	}
+	if err := os.Rename(file+compactSuffix, file); err != nil {
+		os.Remove(file + compactSuffix)
+		return err
+	}
+	if err := syncDir(l.base); err != nil {
+		return err
+	}
+	before := l.total
+	var segments []segment
+	for _, s := range l.segments {
+		switch {
+		case s.seq < replaced:
+			if err := os.Remove(segmentFile(l.base, s.seq)); err != nil {
+				return err
+			}
+			l.total -= s.size
+		case s.seq == replaced:
+			l.total += size - s.size
+			segments = append(segments, segment{seq: s.seq, size: size, lsn: lsn, compacted: true})
+		default:
+			segments = append(segments, s)
+		}
+	}
+	l.segments = segments
+	l.compacted = l.total
+	l.notify()
+	l.logger.Info("wal compacted", zap.String("file", l.base), zap.Int64("before", before), zap.Int64("after", l.total))
+	return nil
+}
+
+// dump writes dataset to new segment file and returns its size and base LSN
+func (l *segmentedLog) dump(file string) (int64, uint64, error) {
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	if err := writeHeader(f, logHeader{version: version}); err != nil {
+		return 0, 0, err
+	}
+	size := headerSize
+	var h logHeader
+	w := bufio.NewWriter(f)
+	err = l.opts.Dump(func(record *Record) error {
+		n, err := encode(w, record, l.opts.Cipher)
+		size += n
+		return err
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		// dump contains effects of records assigned before its end, so they are below base LSN
+		h = newHeader(l.lsn.last())
+		_, err = f.WriteAt(h.bytes(), 0)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	return size, h.lsn, err
+}
+
+// syncDir syncs directory of file, so created and renamed files survive crash
+func syncDir(file string) error {
+	dir, err := os.Open(filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -0,0 +1,128 @@
+package wal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func tempLog(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "test.wal")
+}
+
+func segmentsCount(t *testing.T, file string) int {
+	seqs, err := listSegments(file, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(seqs)
+}
+
+func TestSegmentedLog_Rotation(t *testing.T) {
+	file := tempLog(t)
+	w, err := NewFsyncWAL(file, Options{SegmentSize: 100}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected []string
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key%d", i)
+		expected = append(expected, key)
+		if err := w.Write(Write, []byte(key), []byte("value")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+	if n := segmentsCount(t, file); n < 5 {
+		t.Fatalf("log wasn't rotated, %d segments", n)
+	}
+
+	keys, err := replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, expected...)
+}
+
+func TestSegmentedLog_Compact(t *testing.T) {
+	file := tempLog(t)
+	var w WAL
+	opts := Options{SegmentSize: 200}
+	opts.Dump = func(write func(record *Record) error) error {
+		// write made concurrently with dump goes to the next segment
+		if err := w.Write(Write, []byte("concurrent"), nil); err != nil {
+			return err
+		}
+		return write(&Record{Cmd: Write, Key: []byte("dump")})
+	}
+	w, err := NewFsyncWAL(file, opts, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		w.Write(Write, []byte("key"), []byte("value"))
+	}
+	if err := w.(Compactor).Compact(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write(Write, []byte("after"), nil)
+	w.Close()
+
+	if n := segmentsCount(t, file); n != 2 {
+		t.Fatalf("replaced segments weren't deleted, %d segments", n)
+	}
+	keys, err := replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKeys(t, keys, "dump", "concurrent", "after")
+}
+
+func TestSegmentedLog_BackgroundCompaction(t *testing.T) {
+	file := tempLog(t)
+	opts := Options{SegmentSize: 100, CompactSize: 500}
+	compacted := make(chan struct{}, 1)
+	opts.Dump = func(write func(record *Record) error) error {
+		defer func() {
+			select {
+			case compacted <- struct{}{}:
+			default:
+			}
+		}()
+		return write(&Record{Cmd: Write, Key: []byte("dump")})
+	}
+	w, err := NewFsyncWAL(file, opts, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		w.Write(Write, []byte("key"), []byte("value"))
+	}
+	select {
+	case <-compacted:
+	case <-time.After(time.Second):
+		t.Fatal("log wasn't compacted")
+	}
+	w.Close()
+
+	keys, err := replayKeys(file, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) == 0 || keys[0] != "dump" {
+		t.Fatalf("log doesn't start with dump: %v", keys)
+	}
+	if len(keys) >= 20 {
+		t.Fatalf("compacted log has %d records", len(keys))
+	}
+}
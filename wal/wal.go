@@ -1,14 +1,89 @@
 package wal
 
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrClosed is returned when closed WAL is used
+	ErrClosed = errors.New("wal is closed")
+	// ErrUnavailable is returned when records requested by subscriber are deleted from log by snapshot
+	// or compaction, state has to be copied from dump then
+	ErrUnavailable = errors.New("wal records aren't available")
+	// ErrDisabled is returned when records of disabled WAL are requested
+	ErrDisabled = errors.New("wal is disabled")
+)
+
 type Command uint8
 
 const (
 	Write Command = iota
 	Delete
+	// Flush deletes all keys of database
+	Flush
+	// commands below change part of value, their records contain only the change
+	// Expire sets ttl of key
+	Expire
+	// MapSet sets fields of map
+	MapSet
+	// MapDelete deletes fields of map
+	MapDelete
+	// PushLeft and PushRight add elements to the head or the tail of list
+	PushLeft
+	PushRight
+	// PopLeft and PopRight remove the first or the last element of list
+	PopLeft
+	PopRight
+	// Incr adds number to integer value
+	Incr
+	// StreamAdd appends entry to stream
+	StreamAdd
+	// StreamTrim removes the oldest entries of stream, so it contains at most given amount of entries
+	StreamTrim
+	// StreamGroupCreate adds consumer group to stream
+	StreamGroupCreate
+	// StreamDeliver adds entries delivered to consumer to pending list of group
+	StreamDeliver
+	// StreamAck removes acknowledged entries from pending list of group
+	StreamAck
 )
 
+// dbFlag is set in command of records of non zero database, database index follows command then.
+// Records of database 0 keep original format
+const dbFlag Command = 0x80
+
+// metaFlag is set in command of records with LSN, LSN and time follow command and database index then
+const metaFlag Command = 0x40
+
 type WAL interface {
 	Write(cmd Command, key []byte, data []byte) error
+	// Replay returns iterator over records of log, it restores state before the first write
+	Replay() (Iterator, error)
+	// Subscribe returns iterator over records with LSN from and greater, it waits for records written later.
+	// ErrUnavailable is returned if some of these records are deleted from log
+	Subscribe(from uint64) (Iterator, error)
+	// Sync returns after all records written before it are synced to disk
+	Sync() error
+	// LastLSN returns LSN of the last written record
+	LastLSN() uint64
+	// Close writes buffered records, syncs and closes log, WAL can't be used after it
+	Close() error
+}
+
+// Iterator reads records of log in order they are written
+type Iterator interface {
+	// Next returns the next record, io.EOF is returned after the last record of replay.
+	// Iterator of subscriber waits for new records until ctx is done
+	Next(ctx context.Context) (*Record, error)
+	Close() error
+}
+
+// Batcher is implemented by WALs which can write several records as one unit,
+// so records of batch are never separated by records written concurrently
+type Batcher interface {
+	WriteBatch(records []*Record) error
 }
 
 type NoopWAL struct {
@@ -17,3 +92,37 @@ type NoopWAL struct {
 func (NoopWAL) Write(cmd Command, key []byte, data []byte) error {
 	return nil
 }
+
+func (NoopWAL) WriteBatch(records []*Record) error {
+	return nil
+}
+
+func (NoopWAL) Replay() (Iterator, error) {
+	return emptyIterator{}, nil
+}
+
+func (NoopWAL) Subscribe(from uint64) (Iterator, error) {
+	return nil, ErrDisabled
+}
+
+func (NoopWAL) Sync() error {
+	return nil
+}
+
+func (NoopWAL) Close() error {
+	return nil
+}
+
+func (NoopWAL) LastLSN() uint64 {
+	return 0
+}
+
+type emptyIterator struct{}
+
+func (emptyIterator) Next(ctx context.Context) (*Record, error) {
+	return nil, io.EOF
+}
+
+func (emptyIterator) Close() error {
+	return nil
+}
@@ -2,7 +2,9 @@ package wire
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"io"
 	"net"
 
@@ -20,12 +22,38 @@ type Protocol interface {
 	Write(conn net.Conn, src interface{}) error
 }
 
+const (
+	// DefaultMaxFrameSize - max size of message read by protocol created by NewSimpleWireProtocol
+	DefaultMaxFrameSize = 64 << 20
+	// readChunkSize - frame buffer grows by this size at most, so memory is allocated only for received data
+	readChunkSize = 64 << 10
+)
+
+// ErrFrameTooLarge is returned by Read when message size exceeds max frame size, message isn't read,
+// so connection can't be used anymore
+var ErrFrameTooLarge = errors.New("frame is too large")
+
+// DecodeError is returned by Read when message was read but can't be decoded
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "can't decode frame: " + e.Err.Error()
+}
+
 func NewSimpleWireProtocol(codec codec.Codec) Protocol {
-	return &simpleProtocol{codec: codec}
+	return NewSimpleWireProtocolWithMaxFrameSize(codec, DefaultMaxFrameSize)
+}
+
+// NewSimpleWireProtocolWithMaxFrameSize creates protocol which refuses to read messages larger than maxFrameSize bytes
+func NewSimpleWireProtocolWithMaxFrameSize(codec codec.Codec, maxFrameSize uint32) Protocol {
+	return &simpleProtocol{codec: codec, maxFrameSize: maxFrameSize}
 }
 
 type simpleProtocol struct {
-	codec codec.Codec
+	codec        codec.Codec
+	maxFrameSize uint32
 }
 
 func (s *simpleProtocol) Read(conn net.Conn, dst interface{}) error {
@@ -34,15 +62,31 @@ func (s *simpleProtocol) Read(conn net.Conn, dst interface{}) error {
 	if err != nil {
 		return err
 	}
+	if length > s.maxFrameSize {
+		return ErrFrameTooLarge
+	}
 
-	message := make([]byte, length)
-	_, err = io.ReadFull(conn, message)
+	// length isn't trusted, buffer grows only when data is received
+	capacity := length
+	if capacity > readChunkSize {
+		capacity = readChunkSize
+	}
+	message := bytes.NewBuffer(make([]byte, 0, capacity))
+	_, err = io.CopyN(message, conn, int64(length))
 	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
 		return err
 	}
 
-	return s.codec.Unmarshal(message, dst)
+	err = s.codec.Unmarshal(message.Bytes(), dst)
+	if err != nil {
+		return &DecodeError{Err: err}
+	}
+	return nil
 }
+
 func (s *simpleProtocol) Write(conn net.Conn, src interface{}) error {
 	message, err := s.codec.Marshal(src)
 	if err != nil {